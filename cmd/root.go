@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/creasty/defaults"
 	"github.com/ethpandaops/checkpointz/pkg/checkpointz"
@@ -18,7 +20,10 @@ var rootCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		cfg := initCommon()
 		p := checkpointz.NewServer(log, cfg)
-		if err := p.Start(context.Background()); err != nil {
+
+		go reloadOnSIGHUP(cmd.Context(), p)
+
+		if err := p.Start(cmd.Context()); err != nil {
 			log.WithError(err).Fatal("failed to serve")
 		}
 	},
@@ -31,8 +36,8 @@ var (
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() {
-	err := rootCmd.Execute()
+func Execute(ctx context.Context) {
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
 		os.Exit(1)
 	}
@@ -42,6 +47,35 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "config.yaml", "config file (default is config.yaml)")
 }
 
+// reloadOnSIGHUP reloads the upstreams from the config file each time the process receives SIGHUP.
+func reloadOnSIGHUP(ctx context.Context, p *checkpointz.Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+
+		log.WithField("cfgFile", cfgFile).Info("Caught SIGHUP, reloading upstreams")
+
+		config, err := loadConfigFromFile(cfgFile)
+		if err != nil {
+			log.WithError(err).Error("Failed to load config, keeping the current upstreams")
+
+			continue
+		}
+
+		if err := p.ReloadUpstreams(ctx, config); err != nil {
+			log.WithError(err).Error("Failed to reload upstreams, keeping the current upstreams")
+		}
+	}
+}
+
 func loadConfigFromFile(file string) (*checkpointz.Config, error) {
 	if file == "" {
 		file = "config.yaml"
@@ -1,22 +1,32 @@
 package main
 
 import (
+	"context"
 	"log"
+	"math/rand"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/ethpandaops/checkpointz/cmd"
 )
 
 func main() {
-	cancel := make(chan os.Signal, 1)
-	signal.Notify(cancel, syscall.SIGTERM, syscall.SIGINT)
+	// Seed so that instances don't all pick the same upstreams and jitter.
+	rand.Seed(time.Now().UnixNano())
 
-	go cmd.Execute()
+	ctx, cancel := context.WithCancel(context.Background())
 
-	sig := <-cancel
-	log.Printf("Caught signal: %v", sig)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
 
-	os.Exit(0)
+	go func() {
+		sig := <-signals
+		log.Printf("Caught signal: %v", sig)
+
+		cancel()
+	}()
+
+	cmd.Execute(ctx)
 }
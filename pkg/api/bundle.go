@@ -0,0 +1,105 @@
+package api
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/service/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/service/eth"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	bundleArchiveContentType = "application/x-tar"
+	bundleArchivePath        = "/checkpointz/v1/download/bundle"
+)
+
+// handleCheckpointzDownloadBundle streams a tar archive holding the SSZ encoded block and state for a root.
+// It isn't a wrappedHandler as the archive isn't a negotiable content type and is too large to buffer.
+func (h *Handler) handleCheckpointzDownloadBundle(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	start := time.Now()
+	statusCode := http.StatusOK
+
+	h.metrics.ObserveRequest(r.Method, bundleArchivePath)
+
+	defer func() {
+		h.metrics.ObserveResponse(r.Method, bundleArchivePath, fmt.Sprintf("%v", statusCode), bundleArchiveContentType, time.Since(start))
+	}()
+
+	writeError := func(code int, err error) {
+		statusCode = code
+
+		if writeErr := WriteErrorResponse(w, err.Error(), code); writeErr != nil {
+			h.log.WithError(writeErr).Error("Failed to write error response")
+		}
+	}
+
+	id, err := eth.ParseBlockID(r.URL.Query().Get("root"))
+	if err != nil || id.Type() != eth.BlockIDRoot {
+		writeError(http.StatusBadRequest, errors.New("root must be a 0x prefixed block root"))
+
+		return
+	}
+
+	root, err := id.AsRoot()
+	if err != nil {
+		writeError(http.StatusBadRequest, err)
+
+		return
+	}
+
+	bundle, err := h.checkpointz.V1Bundle(r.Context(), checkpointz.NewBundleRequest(root))
+	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) || errors.Is(err, store.ErrStateNotFound) {
+			writeError(http.StatusNotFound, errors.New("bundle not found"))
+
+			return
+		}
+
+		writeError(http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", bundleArchiveContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"checkpointz-bundle-%d-%#x.tar\"", bundle.Slot, bundle.Root))
+
+	if err := writeBundleArchive(w, bundle, time.Now()); err != nil {
+		h.log.WithError(err).Error("Failed to write bundle archive")
+	}
+}
+
+// writeBundleArchive writes the bundle's block and state to w as block.ssz and state.ssz in a tar archive.
+func writeBundleArchive(w io.Writer, bundle *checkpointz.BundleResponse, modTime time.Time) error {
+	archive := tar.NewWriter(w)
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"block.ssz", bundle.Block},
+		{"state.ssz", bundle.State},
+	}
+
+	for _, file := range files {
+		if err := archive.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0o644,
+			Size:    int64(len(file.data)),
+			ModTime: modTime,
+		}); err != nil {
+			return err
+		}
+
+		if _, err := archive.Write(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
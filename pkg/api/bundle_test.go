@@ -0,0 +1,62 @@
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/service/checkpointz"
+)
+
+func TestWriteBundleArchive(t *testing.T) {
+	bundle := &checkpointz.BundleResponse{
+		Block: []byte("block"),
+		State: []byte("state"),
+	}
+
+	buf := &bytes.Buffer{}
+
+	if err := writeBundleArchive(buf, bundle, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		"block.ssz": "block",
+		"state.ssz": "state",
+	}
+
+	archive := tar.NewReader(buf)
+
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, err := io.ReadAll(archive)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want, ok := expected[header.Name]
+		if !ok {
+			t.Fatalf("unexpected file %s", header.Name)
+		}
+
+		if string(data) != want {
+			t.Errorf("%s: expected %q, got %q", header.Name, want, data)
+		}
+
+		delete(expected, header.Name)
+	}
+
+	if len(expected) != 0 {
+		t.Errorf("missing files: %v", expected)
+	}
+}
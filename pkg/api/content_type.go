@@ -1,6 +1,10 @@
 package api
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type ContentType int
 
@@ -26,25 +30,59 @@ func (c ContentType) String() string {
 	return ""
 }
 
+// DeriveContentType derives the preferred content type from an Accept header.
+// Media ranges are weighted by their q-value, with ties going to the first listed.
 func DeriveContentType(accept string) ContentType {
-	switch accept {
-	case "application/json":
-		return ContentTypeJSON
-	case "*/*":
+	// Default to JSON if they don't care what they get.
+	if strings.TrimSpace(accept) == "" {
 		return ContentTypeJSON
+	}
+
+	best := ContentTypeUnknown
+	bestQuality := 0.0
+
+	for _, mediaRange := range strings.Split(accept, ",") {
+		contentType, quality := parseMediaRange(mediaRange)
+		if contentType == ContentTypeUnknown || quality <= bestQuality {
+			continue
+		}
+
+		best = contentType
+		bestQuality = quality
+	}
+
+	return best
+}
+
+func parseMediaRange(mediaRange string) (ContentType, float64) {
+	parts := strings.Split(mediaRange, ";")
+
+	quality := 1.0
+
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != "q" {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil {
+			return ContentTypeUnknown, 0
+		}
+
+		quality = q
+	}
+
+	switch strings.ToLower(strings.TrimSpace(parts[0])) {
+	case "application/json", "*/*", "application/*":
+		return ContentTypeJSON, quality
 	case "application/yaml":
-		return ContentTypeYAML
+		return ContentTypeYAML, quality
 	case "application/octet-stream":
-		return ContentTypeSSZ
-	// TODO(sam.caldermason): HACK to support Nimbus - what should we do here?
-	case "application/octet-stream,application/json;q=0.9":
-		return ContentTypeSSZ
-	// Default to JSON if they don't care what they get.
-	case "":
-		return ContentTypeJSON
+		return ContentTypeSSZ, quality
 	}
 
-	return ContentTypeUnknown
+	return ContentTypeUnknown, 0
 }
 
 func ValidateContentType(contentType ContentType, accepting []ContentType) error {
@@ -0,0 +1,29 @@
+package api
+
+import "testing"
+
+func TestDeriveContentType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   ContentType
+	}{
+		{"", ContentTypeJSON},
+		{"*/*", ContentTypeJSON},
+		{"application/json", ContentTypeJSON},
+		{"application/yaml", ContentTypeYAML},
+		{"application/octet-stream", ContentTypeSSZ},
+		{"application/octet-stream,application/json;q=0.9", ContentTypeSSZ},
+		{"application/json;q=0.9, application/octet-stream", ContentTypeSSZ},
+		{"application/octet-stream;q=0.5,application/json", ContentTypeJSON},
+		{"application/json,application/octet-stream", ContentTypeJSON},
+		{"application/octet-stream;q=0", ContentTypeUnknown},
+		{"text/html", ContentTypeUnknown},
+		{"text/html,application/json;q=0.1", ContentTypeJSON},
+	}
+
+	for _, test := range tests {
+		if got := DeriveContentType(test.accept); got != test.want {
+			t.Errorf("DeriveContentType(%q) = %v, want %v", test.accept, got, test.want)
+		}
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
 	"github.com/ethpandaops/checkpointz/pkg/service/checkpointz"
 	"github.com/ethpandaops/checkpointz/pkg/service/eth"
 	"github.com/julienschmidt/httprouter"
@@ -29,6 +32,13 @@ type Handler struct {
 	brandName     string
 	brandImageURL string
 
+	debugCacheEndpoint  bool
+	adminEndpoints      bool
+	validatorsEndpoints bool
+
+	// servingLimiter bounds how many blocks and states are served at once. Nil if unlimited.
+	servingLimiter *servingLimiter
+
 	metrics Metrics
 }
 
@@ -42,14 +52,29 @@ func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider, config *be
 		brandName:     config.Frontend.BrandName,
 		brandImageURL: config.Frontend.BrandImageURL,
 
+		debugCacheEndpoint:  config.DebugCacheEndpoint,
+		adminEndpoints:      config.AdminEndpoints,
+		validatorsEndpoints: config.ValidatorsEndpoints,
+
+		servingLimiter: newServingLimiter(config.MaxConcurrentServes, config.ServeQueueTimeout, config.ServeRetryAfter),
+
 		metrics: NewMetrics("http"),
 	}
 }
 
+// Register registers the public endpoints.
 func (h *Handler) Register(ctx context.Context, router *httprouter.Router) error {
+	h.registerErrorHandlers(router)
+
 	router.GET("/eth/v1/beacon/genesis", h.wrappedHandler(h.handleEthV1BeaconGenesis))
+	router.GET("/eth/v1/beacon/blocks/:block_id", h.servingLimiter.limit(h.wrappedHandler(h.handleEthV1BeaconBlocks)))
 	router.GET("/eth/v1/beacon/blocks/:block_id/root", h.wrappedHandler(h.handleEthV1BeaconBlocksRoot))
+	router.GET("/eth/v1/beacon/blinded_blocks/:block_id", h.servingLimiter.limit(h.wrappedHandler(h.handleEthV1BeaconBlindedBlocks)))
+	router.GET("/eth/v1/beacon/headers/:block_id", h.wrappedHandler(h.handleEthV1BeaconHeaders))
 	router.GET("/eth/v1/beacon/states/:state_id/finality_checkpoints", h.wrappedHandler(h.handleEthV1BeaconStatesFinalityCheckpoints))
+	router.GET("/eth/v1/beacon/states/:state_id/randao", h.wrappedHandler(h.handleEthV1BeaconStatesRandao))
+	router.GET("/eth/v1/beacon/states/:state_id/validators", h.wrappedHandler(h.handleEthV1BeaconStatesValidators))
+	router.GET("/eth/v1/beacon/states/:state_id/validator_balances", h.wrappedHandler(h.handleEthV1BeaconStatesValidatorBalances))
 	router.GET("/eth/v1/beacon/deposit_snapshot", h.wrappedHandler(h.handleEthV1BeaconDepositSnapshot))
 
 	router.GET("/eth/v1/config/spec", h.wrappedHandler(h.handleEthV1ConfigSpec))
@@ -61,18 +86,64 @@ func (h *Handler) Register(ctx context.Context, router *httprouter.Router) error
 	router.GET("/eth/v1/node/peers", h.wrappedHandler(h.handleEthV1NodePeers))
 	router.GET("/eth/v1/node/peer_count", h.wrappedHandler(h.handleEthV1NodePeerCount))
 
-	router.GET("/eth/v2/beacon/blocks/:block_id", h.wrappedHandler(h.handleEthV2BeaconBlocks))
+	router.GET("/eth/v2/beacon/blocks/:block_id", h.servingLimiter.limit(h.wrappedHandler(h.handleEthV2BeaconBlocks)))
 
-	router.GET("/eth/v2/debug/beacon/states/:state_id", h.wrappedHandler(h.handleEthV2DebugBeaconStates))
+	router.GET("/eth/v2/debug/beacon/states/:state_id", h.servingLimiter.limit(h.wrappedHandler(h.handleEthV2DebugBeaconStates)))
 
 	router.GET("/checkpointz/v1/status", h.wrappedHandler(h.handleCheckpointzStatus))
+	router.GET("/checkpointz/v1/checkpoints", h.wrappedHandler(h.handleCheckpointzCheckpoints))
 	router.GET("/checkpointz/v1/beacon/slots", h.wrappedHandler(h.handleCheckpointzBeaconSlots))
 	router.GET("/checkpointz/v1/beacon/slots/:slot", h.wrappedHandler(h.handleCheckpointzBeaconSlot))
 	router.GET("/checkpointz/v1/ready", h.wrappedHandler(h.handleCheckpointzReady))
+	router.GET("/readyz", h.wrappedHandler(h.handleCheckpointzReady))
+	router.GET("/checkpointz/v1/selftest", h.servingLimiter.limit(h.wrappedHandler(h.handleCheckpointzSelfTest)))
+	router.GET(bundleArchivePath, h.servingLimiter.limit(h.handleCheckpointzDownloadBundle))
+
+	return nil
+}
+
+// RegisterAdmin registers the admin and debug endpoints that are enabled. These can be registered on a separate
+// router to the public endpoints so they can be served on a port that isn't publicly reachable.
+func (h *Handler) RegisterAdmin(ctx context.Context, router *httprouter.Router) error {
+	h.registerErrorHandlers(router)
+
+	if h.debugCacheEndpoint {
+		router.GET("/checkpointz/v1/debug/cache", h.wrappedHandler(h.handleCheckpointzDebugCache))
+	}
+
+	if h.adminEndpoints {
+		router.POST("/checkpointz/v1/admin/recheck", h.wrappedHandler(h.handleCheckpointzAdminRecheck))
+		router.POST("/checkpointz/v1/admin/fetch", h.wrappedHandler(h.handleCheckpointzAdminFetch))
+		router.POST("/checkpointz/v1/admin/pin", h.wrappedHandler(h.handleCheckpointzAdminPin))
+		router.DELETE("/checkpointz/v1/admin/pin", h.wrappedHandler(h.handleCheckpointzAdminUnpin))
+	}
 
 	return nil
 }
 
+// registerErrorHandlers makes the router's own errors use the Beacon API error format rather than plain text.
+func (h *Handler) registerErrorHandlers(router *httprouter.Router) {
+	router.NotFound = NotFoundHandler(nil)
+	router.MethodNotAllowed = errorHandler(http.StatusMethodNotAllowed, "method not allowed")
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, recovered interface{}) {
+		h.log.WithField("path", r.URL.Path).WithField("panic", recovered).Error("Recovered from panic while handling request")
+
+		if err := WriteErrorResponse(w, "internal server error", http.StatusInternalServerError); err != nil {
+			h.log.WithError(err).Error("Failed to write error response")
+		}
+	}
+}
+
+// notFoundError returns the error to respond with when the requested block or state isn't found. The genesis
+// bundle being unavailable from the upstreams is reported as such, as retrying won't help.
+func notFoundError(err error, msg string) error {
+	if errors.Is(err, beacon.ErrGenesisBundleUnavailable) {
+		return beacon.ErrGenesisBundleUnavailable
+	}
+
+	return errors.New(msg)
+}
+
 func deriveRegisteredPath(request *http.Request, ps httprouter.Params) string {
 	registeredPath := request.URL.Path
 	for _, param := range ps {
@@ -109,6 +180,11 @@ func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Reque
 
 		response, err = handler(ctx, r, p, contentType)
 		if err != nil {
+			// A handler that fails without saying how is an internal error.
+			if response == nil || response.StatusCode < http.StatusBadRequest {
+				response = NewInternalServerErrorResponse(nil)
+			}
+
 			if writeErr := WriteErrorResponse(w, err.Error(), response.StatusCode); writeErr != nil {
 				h.log.WithError(writeErr).Error("Failed to write error response")
 			}
@@ -118,7 +194,14 @@ func (h *Handler) wrappedHandler(handler func(ctx context.Context, r *http.Reque
 
 		data, err := response.MarshalAs(contentType)
 		if err != nil {
-			if writeErr := WriteErrorResponse(w, err.Error(), http.StatusInternalServerError); writeErr != nil {
+			statusCode := http.StatusInternalServerError
+			if errors.Is(err, ErrUnsupportedContentType) {
+				statusCode = http.StatusUnsupportedMediaType
+			}
+
+			response = &HTTPResponse{StatusCode: statusCode}
+
+			if writeErr := WriteErrorResponse(w, err.Error(), statusCode); writeErr != nil {
 				h.log.WithError(writeErr).Error("Failed to write error response")
 			}
 
@@ -142,6 +225,10 @@ func (h *Handler) handleEthV1BeaconGenesis(ctx context.Context, r *http.Request,
 
 	genesis, err := h.eth.BeaconGenesis(ctx)
 	if err != nil {
+		if errors.Is(err, beacon.ErrGenesisNotAvailable) {
+			return NewServiceUnavailableResponse(nil), err
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
@@ -154,18 +241,99 @@ func (h *Handler) handleEthV1BeaconGenesis(ctx context.Context, r *http.Request,
 	return rsp, nil
 }
 
+func (h *Handler) handleEthV1BeaconBlocks(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	rsp, _, err := h.beaconBlockResponse(ctx, p.ByName("block_id"), contentType)
+	if err != nil {
+		return rsp, err
+	}
+
+	return rsp, nil
+}
+
 func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	rsp, block, err := h.beaconBlockResponse(ctx, p.ByName("block_id"), contentType)
+	if err != nil {
+		return rsp, err
+	}
+
+	rsp.AddExtraData("version", block.Version.String())
+	rsp.AddExtraData("execution_optimistic", "false")
+
+	return rsp, nil
+}
+
+// beaconBlockResponse resolves the block_id and builds the response shared by the v1 and v2 block endpoints.
+// The v2 endpoint adds the version to the envelope.
+func (h *Handler) beaconBlockResponse(ctx context.Context, id string, contentType ContentType) (*HTTPResponse, *spec.VersionedSignedBeaconBlock, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON, ContentTypeSSZ}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), nil, err
+	}
+
+	blockID, err := eth.ParseBlockID(id)
+	if err != nil {
+		return NewBadRequestResponse(nil), nil, err
+	}
+
+	block, err := h.eth.BeaconBlock(ctx, blockID)
+	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) {
+			return NewNotFoundResponse(nil), nil, notFoundError(err, "block not found")
+		}
+
+		return NewInternalServerErrorResponse(nil), nil, err
+	}
+
+	var rsp = &HTTPResponse{}
+
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		rsp = NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeJSON: block.Phase0.MarshalJSON,
+		})
+	case spec.DataVersionAltair:
+		rsp = NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeJSON: block.Altair.MarshalJSON,
+		})
+	case spec.DataVersionBellatrix:
+		rsp = NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeJSON: block.Bellatrix.MarshalJSON,
+		})
+	case spec.DataVersionCapella:
+		rsp = NewSuccessResponse(ContentTypeResolvers{
+			ContentTypeJSON: block.Capella.MarshalJSON,
+		})
+	default:
+		return NewInternalServerErrorResponse(nil), nil, errors.New("unknown block version")
+	}
+
+	// Blocks are commonly requested as SSZ, so the cached encoding is used rather than encoding them each time.
+	rsp.resolvers[ContentTypeSSZ] = func() ([]byte, error) {
+		return h.eth.MarshalBeaconBlockSSZ(ctx, block)
+	}
+
+	rsp.Headers["Eth-Consensus-Version"] = h.eth.ConsensusVersion(ctx, block)
+
+	setBlockCacheControl(rsp, blockID)
+
+	return rsp, block, nil
+}
+
+func (h *Handler) handleEthV1BeaconBlindedBlocks(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
 	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON, ContentTypeSSZ}); err != nil {
 		return NewUnsupportedMediaTypeResponse(nil), err
 	}
 
-	blockID, err := eth.NewBlockIdentifier(p.ByName("block_id"))
+	blockID, err := eth.ParseBlockID(p.ByName("block_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
 	}
 
-	block, err := h.eth.BeaconBlock(ctx, blockID)
+	block, blinded, err := h.eth.BlindedBeaconBlock(ctx, blockID)
 	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) {
+			return NewNotFoundResponse(nil), notFoundError(err, "block not found")
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
@@ -184,32 +352,46 @@ func (h *Handler) handleEthV2BeaconBlocks(ctx context.Context, r *http.Request,
 		})
 	case spec.DataVersionBellatrix:
 		rsp = NewSuccessResponse(ContentTypeResolvers{
-			ContentTypeJSON: block.Bellatrix.MarshalJSON,
-			ContentTypeSSZ:  block.Bellatrix.MarshalSSZ,
+			ContentTypeJSON: blinded.Bellatrix.MarshalJSON,
+			ContentTypeSSZ: func() ([]byte, error) {
+				return h.eth.MarshalBlindedBeaconBlockSSZ(blinded)
+			},
 		})
 	case spec.DataVersionCapella:
 		rsp = NewSuccessResponse(ContentTypeResolvers{
-			ContentTypeJSON: block.Capella.MarshalJSON,
-			ContentTypeSSZ:  block.Capella.MarshalSSZ,
+			ContentTypeJSON: blinded.Capella.MarshalJSON,
+			ContentTypeSSZ: func() ([]byte, error) {
+				return h.eth.MarshalBlindedBeaconBlockSSZ(blinded)
+			},
 		})
 	default:
 		return NewInternalServerErrorResponse(nil), errors.New("unknown block version")
 	}
 
+	rsp.Headers["Eth-Consensus-Version"] = h.eth.ConsensusVersion(ctx, block)
+
 	rsp.AddExtraData("version", block.Version.String())
 	rsp.AddExtraData("execution_optimistic", "false")
 
+	setBlockCacheControl(rsp, blockID)
+
+	return rsp, nil
+}
+
+// setBlockCacheControl sets how long a response for the block_id can be cached for.
+func setBlockCacheControl(rsp *HTTPResponse, blockID eth.BlockIdentifier) {
 	switch blockID.Type() {
 	case eth.BlockIDRoot, eth.BlockIDGenesis, eth.BlockIDSlot:
 		rsp.SetCacheControl("public, s-max-age=6000")
 	case eth.BlockIDFinalized:
 		// TODO(sam.calder-mason): This should be calculated using the Weak-Subjectivity period.
 		rsp.SetCacheControl("public, s-max-age=30")
-	case eth.BlockIDHead:
+	case eth.BlockIDJustified:
 		rsp.SetCacheControl("public, s-max-age=30")
+	case eth.BlockIDHead:
+		// The head moves every slot.
+		rsp.SetCacheControl("public, s-max-age=6")
 	}
-
-	return rsp, nil
 }
 
 func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
@@ -217,18 +399,27 @@ func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Requ
 		return NewUnsupportedMediaTypeResponse(nil), err
 	}
 
-	id, err := eth.NewStateIdentifier(p.ByName("state_id"))
+	id, err := eth.ParseStateID(p.ByName("state_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
 	}
 
 	state, err := h.eth.BeaconState(ctx, id)
 	if err != nil {
+		if errors.Is(err, store.ErrStateNotFound) || errors.Is(err, store.ErrBlockNotFound) {
+			return NewNotFoundResponse(nil), notFoundError(err, "state not found")
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
 	if state == nil {
-		return NewInternalServerErrorResponse(nil), errors.New("state not found")
+		return NewNotFoundResponse(nil), errors.New("state not found")
+	}
+
+	version, err := h.eth.BeaconStateVersion(ctx, id)
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
 	}
 
 	rsp := NewSuccessResponse(ContentTypeResolvers{
@@ -237,6 +428,8 @@ func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Requ
 		},
 	})
 
+	rsp.Headers["Eth-Consensus-Version"] = version
+
 	switch id.Type() {
 	case eth.StateIDRoot, eth.StateIDGenesis, eth.StateIDSlot:
 		// TODO(sam.calder-mason): This should be calculated using the Weak-Subjectivity period.
@@ -244,8 +437,11 @@ func (h *Handler) handleEthV2DebugBeaconStates(ctx context.Context, r *http.Requ
 	case eth.StateIDFinalized:
 		// TODO(sam.calder-mason): This should be calculated using the Weak-Subjectivity period.
 		rsp.SetCacheControl("public, s-max-age=180")
-	case eth.StateIDHead:
+	case eth.StateIDJustified:
 		rsp.SetCacheControl("public, s-max-age=30")
+	case eth.StateIDHead:
+		// The head moves every slot.
+		rsp.SetCacheControl("public, s-max-age=6")
 	}
 
 	return rsp, nil
@@ -258,6 +454,10 @@ func (h *Handler) handleEthV1ConfigSpec(ctx context.Context, r *http.Request, p
 
 	sp, err := h.eth.ConfigSpec(ctx)
 	if err != nil {
+		if errors.Is(err, beacon.ErrSpecNotAvailable) {
+			return NewServiceUnavailableResponse(nil), err
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
@@ -444,13 +644,13 @@ func (h *Handler) handleCheckpointzReady(ctx context.Context, r *http.Request, p
 		return NewUnsupportedMediaTypeResponse(nil), err
 	}
 
-	status, err := h.checkpointz.V1Status(ctx, checkpointz.NewStatusRequest())
+	ready, err := h.checkpointz.V1Ready(ctx, checkpointz.NewReadyRequest())
 	if err != nil {
 		return NewInternalServerErrorResponse(nil), err
 	}
 
-	if status.Finality == nil || status.Finality.Finalized == nil {
-		return NewInternalServerErrorResponse(nil), errors.New("no finalized checkpoint")
+	if !ready {
+		return NewServiceUnavailableResponse(nil), errors.New("serving checkpoint bundle is not available yet")
 	}
 
 	rsp := NewSuccessResponse(ContentTypeResolvers{
@@ -462,6 +662,185 @@ func (h *Handler) handleCheckpointzReady(ctx context.Context, r *http.Request, p
 	return rsp, nil
 }
 
+func (h *Handler) handleCheckpointzSelfTest(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	report, err := h.checkpointz.V1SelfTest(ctx, checkpointz.NewSelfTestRequest())
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(report)
+		},
+	})
+
+	rsp.SetCacheControl("no-store")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzDebugCache(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	contents, err := h.checkpointz.V1DebugCache(ctx, checkpointz.NewDebugCacheRequest())
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(contents)
+		},
+	})
+
+	rsp.SetCacheControl("no-store")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzAdminRecheck(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	head, err := h.checkpointz.V1AdminRecheck(ctx, checkpointz.NewAdminRecheckRequest())
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(head)
+		},
+	})
+
+	rsp.SetCacheControl("no-store")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzAdminFetch(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	id, err := eth.ParseBlockID(r.URL.Query().Get("root"))
+	if err != nil || id.Type() != eth.BlockIDRoot {
+		return NewBadRequestResponse(nil), errors.New("root must be a 0x prefixed block root")
+	}
+
+	root, err := id.AsRoot()
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	queue, err := h.checkpointz.V1AdminFetch(ctx, checkpointz.NewAdminFetchRequest(root))
+	if err != nil {
+		if errors.Is(err, beacon.ErrBundleAlreadyCached) || errors.Is(err, beacon.ErrBundleAlreadyQueued) {
+			return NewConflictResponse(nil), err
+		}
+
+		if errors.Is(err, beacon.ErrNotFinalizedCheckpoint) || errors.Is(err, beacon.ErrBlockExpired) {
+			return NewBadRequestResponse(nil), err
+		}
+
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(queue)
+		},
+	})
+
+	rsp.SetCacheControl("no-store")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzAdminPin(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	id, err := eth.ParseBlockID(r.URL.Query().Get("root"))
+	if err != nil || id.Type() != eth.BlockIDRoot {
+		return NewBadRequestResponse(nil), errors.New("root must be a 0x prefixed block root")
+	}
+
+	root, err := id.AsRoot()
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	pinned, err := h.checkpointz.V1AdminPin(ctx, checkpointz.NewAdminPinRequest(root))
+	if err != nil {
+		if errors.Is(err, beacon.ErrNotFinalizedCheckpoint) {
+			return NewBadRequestResponse(nil), err
+		}
+
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(pinned)
+		},
+	})
+
+	rsp.SetCacheControl("no-store")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzAdminUnpin(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	unpinned, err := h.checkpointz.V1AdminUnpin(ctx, checkpointz.NewAdminUnpinRequest())
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(unpinned)
+		},
+	})
+
+	rsp.SetCacheControl("no-store")
+
+	return rsp, nil
+}
+
+func (h *Handler) handleCheckpointzCheckpoints(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	checkpoints, err := h.checkpointz.V1Checkpoints(ctx, checkpointz.NewCheckpointsRequest())
+	if err != nil {
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(checkpoints)
+		},
+	})
+
+	rsp.SetCacheControl("public, s-max-age=30")
+
+	return rsp, nil
+}
+
 func (h *Handler) handleCheckpointzBeaconSlots(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
 	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
 		return NewUnsupportedMediaTypeResponse(nil), err
@@ -514,13 +893,21 @@ func (h *Handler) handleEthV1BeaconStatesFinalityCheckpoints(ctx context.Context
 		return NewUnsupportedMediaTypeResponse(nil), err
 	}
 
-	id, err := eth.NewStateIdentifier(p.ByName("state_id"))
+	id, err := eth.ParseStateID(p.ByName("state_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
 	}
 
 	finality, err := h.eth.FinalityCheckpoints(ctx, id)
 	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) || errors.Is(err, store.ErrStateNotFound) {
+			return NewNotFoundResponse(nil), notFoundError(err, "state not found")
+		}
+
+		if errors.Is(err, beacon.ErrStateFinalityUnsupported) {
+			return NewNotImplementedResponse(nil), err
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
@@ -531,39 +918,257 @@ func (h *Handler) handleEthV1BeaconStatesFinalityCheckpoints(ctx context.Context
 	})
 
 	switch id.Type() {
-	case eth.StateIDFinalized, eth.StateIDHead:
+	case eth.StateIDFinalized, eth.StateIDHead, eth.StateIDJustified:
+		rsp.SetCacheControl("public, s-max-age=5")
+	case eth.StateIDRoot, eth.StateIDGenesis, eth.StateIDSlot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	}
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1BeaconStatesRandao(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	id, err := eth.ParseStateID(p.ByName("state_id"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	var epoch *phase0.Epoch
+
+	if raw := r.URL.Query().Get("epoch"); raw != "" {
+		e, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return NewBadRequestResponse(nil), fmt.Errorf("invalid epoch: %w", err)
+		}
+
+		parsed := phase0.Epoch(e)
+		epoch = &parsed
+	}
+
+	mix, err := h.eth.RandaoMix(ctx, id, epoch)
+	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) || errors.Is(err, store.ErrStateNotFound) {
+			return NewNotFoundResponse(nil), notFoundError(err, "state not found")
+		}
+
+		if errors.Is(err, beacon.ErrStateRandaoUnsupported) {
+			return NewNotImplementedResponse(nil), err
+		}
+
+		if errors.Is(err, beacon.ErrRandaoEpochOutOfRange) {
+			return NewBadRequestResponse(nil), err
+		}
+
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	wrapped := struct {
+		Randao string `json:"randao"`
+	}{
+		Randao: fmt.Sprintf("%#x", mix),
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(wrapped)
+		},
+	})
+
+	rsp.AddExtraData("execution_optimistic", "false")
+
+	switch id.Type() {
+	case eth.StateIDFinalized, eth.StateIDHead, eth.StateIDJustified:
+		rsp.SetCacheControl("public, s-max-age=5")
+	case eth.StateIDRoot, eth.StateIDGenesis, eth.StateIDSlot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	}
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1BeaconStatesValidators(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	if !h.validatorsEndpoints {
+		return NewNotImplementedResponse(nil), errors.New("validators endpoint is not enabled")
+	}
+
+	id, err := eth.ParseStateID(p.ByName("state_id"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	validators, err := h.eth.Validators(ctx, id, queryValues(r, "id"), queryValues(r, "status"))
+	if err != nil {
+		return validatorsErrorResponse(err)
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(validators)
+		},
+	})
+
+	rsp.AddExtraData("execution_optimistic", "false")
+
+	switch id.Type() {
+	case eth.StateIDFinalized, eth.StateIDHead, eth.StateIDJustified:
+		rsp.SetCacheControl("public, s-max-age=5")
+	case eth.StateIDRoot, eth.StateIDGenesis, eth.StateIDSlot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	}
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1BeaconStatesValidatorBalances(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	if !h.validatorsEndpoints {
+		return NewNotImplementedResponse(nil), errors.New("validator balances endpoint is not enabled")
+	}
+
+	id, err := eth.ParseStateID(p.ByName("state_id"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	balances, err := h.eth.ValidatorBalances(ctx, id, queryValues(r, "id"))
+	if err != nil {
+		return validatorsErrorResponse(err)
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: func() ([]byte, error) {
+			return json.Marshal(balances)
+		},
+	})
+
+	rsp.AddExtraData("execution_optimistic", "false")
+
+	switch id.Type() {
+	case eth.StateIDFinalized, eth.StateIDHead, eth.StateIDJustified:
 		rsp.SetCacheControl("public, s-max-age=5")
+	case eth.StateIDRoot, eth.StateIDGenesis, eth.StateIDSlot:
+		rsp.SetCacheControl("public, s-max-age=6000")
 	}
 
 	return rsp, nil
 }
 
+// validatorsErrorResponse returns the response for an error reading the validators of a state.
+func validatorsErrorResponse(err error) (*HTTPResponse, error) {
+	switch {
+	case errors.Is(err, store.ErrBlockNotFound), errors.Is(err, store.ErrStateNotFound):
+		return NewNotFoundResponse(nil), notFoundError(err, "state not found")
+	case errors.Is(err, beacon.ErrStateValidatorsUnsupported):
+		return NewNotImplementedResponse(nil), err
+	case errors.Is(err, eth.ErrInvalidValidatorID), errors.Is(err, eth.ErrInvalidValidatorStatus):
+		return NewBadRequestResponse(nil), err
+	default:
+		return NewInternalServerErrorResponse(nil), err
+	}
+}
+
+// queryValues returns every value of the query parameter, which can be repeated or comma separated.
+func queryValues(r *http.Request, key string) []string {
+	var values []string
+
+	for _, value := range r.URL.Query()[key] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	return values
+}
+
 func (h *Handler) handleEthV1BeaconBlocksRoot(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
 	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
 		return NewUnsupportedMediaTypeResponse(nil), err
 	}
 
-	id, err := eth.NewBlockIdentifier(p.ByName("block_id"))
+	id, err := eth.ParseBlockID(p.ByName("block_id"))
 	if err != nil {
 		return NewBadRequestResponse(nil), err
 	}
 
 	root, err := h.eth.BlockRoot(ctx, id)
 	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) {
+			return NewNotFoundResponse(nil), notFoundError(err, "block not found")
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
 	wrapped := struct {
 		Root string `json:"root"`
 	}{
-		Root: fmt.Sprintf("%x", root),
+		Root: fmt.Sprintf("%#x", root),
 	}
 
-	return NewSuccessResponse(ContentTypeResolvers{
+	rsp := NewSuccessResponse(ContentTypeResolvers{
 		ContentTypeJSON: func() ([]byte, error) {
 			return json.Marshal(wrapped)
 		},
-	}), nil
+	})
+
+	rsp.AddExtraData("execution_optimistic", "false")
+
+	switch id.Type() {
+	case eth.BlockIDRoot, eth.BlockIDGenesis, eth.BlockIDSlot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	case eth.BlockIDFinalized, eth.BlockIDHead, eth.BlockIDJustified:
+		rsp.SetCacheControl("public, s-max-age=30")
+	}
+
+	return rsp, nil
+}
+
+func (h *Handler) handleEthV1BeaconHeaders(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+	if err := ValidateContentType(contentType, []ContentType{ContentTypeJSON}); err != nil {
+		return NewUnsupportedMediaTypeResponse(nil), err
+	}
+
+	id, err := eth.ParseBlockID(p.ByName("block_id"))
+	if err != nil {
+		return NewBadRequestResponse(nil), err
+	}
+
+	header, err := h.eth.BlockHeader(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrBlockNotFound) {
+			return NewNotFoundResponse(nil), notFoundError(err, "block not found")
+		}
+
+		return NewInternalServerErrorResponse(nil), err
+	}
+
+	rsp := NewSuccessResponse(ContentTypeResolvers{
+		ContentTypeJSON: header.MarshalJSON,
+	})
+
+	rsp.AddExtraData("execution_optimistic", "false")
+
+	switch id.Type() {
+	case eth.BlockIDRoot, eth.BlockIDGenesis, eth.BlockIDSlot:
+		rsp.SetCacheControl("public, s-max-age=6000")
+	case eth.BlockIDFinalized, eth.BlockIDHead, eth.BlockIDJustified:
+		rsp.SetCacheControl("public, s-max-age=30")
+	}
+
+	return rsp, nil
 }
 
 func (h *Handler) handleEthV1BeaconDepositSnapshot(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
@@ -573,6 +1178,10 @@ func (h *Handler) handleEthV1BeaconDepositSnapshot(ctx context.Context, r *http.
 
 	snapshot, err := h.eth.DepositSnapshot(ctx)
 	if err != nil {
+		if errors.Is(err, store.ErrDepositSnapshotNotFound) {
+			return NewNotFoundResponse(nil), errors.New("deposit snapshot not found")
+		}
+
 		return NewInternalServerErrorResponse(nil), err
 	}
 
@@ -0,0 +1,583 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/service/checkpointz"
+	"github.com/ethpandaops/checkpointz/pkg/service/eth"
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAdminEndpointsAreNotRegisteredPublicly(t *testing.T) {
+	h := &Handler{
+		log:                logrus.New(),
+		debugCacheEndpoint: true,
+		adminEndpoints:     true,
+	}
+
+	public := httprouter.New()
+	if err := h.Register(context.Background(), public); err != nil {
+		t.Fatal(err)
+	}
+
+	admin := httprouter.New()
+	if err := h.RegisterAdmin(context.Background(), admin); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: "/checkpointz/v1/debug/cache"},
+		{method: http.MethodPost, path: "/checkpointz/v1/admin/recheck"},
+		{method: http.MethodPost, path: "/checkpointz/v1/admin/fetch"},
+		{method: http.MethodPost, path: "/checkpointz/v1/admin/pin"},
+		{method: http.MethodDelete, path: "/checkpointz/v1/admin/pin"},
+	}
+
+	for _, test := range tests {
+		if handle, _, _ := public.Lookup(test.method, test.path); handle != nil {
+			t.Errorf("expected %s %s to not be registered on the public router", test.method, test.path)
+		}
+
+		if handle, _, _ := admin.Lookup(test.method, test.path); handle == nil {
+			t.Errorf("expected %s %s to be registered on the admin router", test.method, test.path)
+		}
+	}
+
+	if handle, _, _ := admin.Lookup(http.MethodGet, "/eth/v1/beacon/genesis"); handle != nil {
+		t.Error("expected public endpoints to not be registered on the admin router")
+	}
+}
+
+func TestErrorResponsesUseBeaconAPIFormat(t *testing.T) {
+	h := &Handler{
+		log:     logrus.New(),
+		metrics: NewMetrics("test_error_responses"),
+	}
+
+	router := httprouter.New()
+	if err := h.Register(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := map[string]func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error){
+		"/test/not_found": func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+			return NewNotFoundResponse(nil), errors.New("block not found")
+		},
+		"/test/bad_id": func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+			return NewBadRequestResponse(nil), errors.New("invalid block id")
+		},
+		"/test/unavailable": func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+			return NewServiceUnavailableResponse(nil), errors.New("not yet synced")
+		},
+		"/test/no_response": func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+			return nil, errors.New("failed")
+		},
+		"/test/json_only": func(ctx context.Context, r *http.Request, p httprouter.Params, contentType ContentType) (*HTTPResponse, error) {
+			return NewSuccessResponse(ContentTypeResolvers{
+				ContentTypeJSON: func() ([]byte, error) { return []byte(`{}`), nil },
+			}), nil
+		},
+	}
+
+	for path, handler := range handlers {
+		router.GET(path, h.wrappedHandler(handler))
+	}
+
+	router.GET("/test/panic", func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		accept     string
+		statusCode int
+	}{
+		{name: "not found", method: http.MethodGet, path: "/test/not_found", statusCode: http.StatusNotFound},
+		{name: "bad id", method: http.MethodGet, path: "/test/bad_id", statusCode: http.StatusBadRequest},
+		{name: "upstream unavailable", method: http.MethodGet, path: "/test/unavailable", statusCode: http.StatusServiceUnavailable},
+		{name: "handler without a response", method: http.MethodGet, path: "/test/no_response", statusCode: http.StatusInternalServerError},
+		{name: "unsupported content type", method: http.MethodGet, path: "/test/json_only", accept: "application/octet-stream", statusCode: http.StatusUnsupportedMediaType},
+		{name: "unknown path", method: http.MethodGet, path: "/eth/v1/unknown", statusCode: http.StatusNotFound},
+		{name: "validators endpoint disabled", method: http.MethodGet, path: "/eth/v1/beacon/states/head/validators", statusCode: http.StatusNotImplemented},
+		{name: "method not allowed", method: http.MethodPost, path: "/eth/v1/beacon/genesis", statusCode: http.StatusMethodNotAllowed},
+		{name: "panic", method: http.MethodGet, path: "/test/panic", statusCode: http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != test.statusCode {
+				t.Errorf("expected status %d, got %d", test.statusCode, rec.Code)
+			}
+
+			if contentType := rec.Header().Get("Content-Type"); contentType != ContentTypeJSON.String() {
+				t.Errorf("expected content type %s, got %s", ContentTypeJSON.String(), contentType)
+			}
+
+			var body BeaconError
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected a json error body, got %q: %v", rec.Body.String(), err)
+			}
+
+			if body.Code != test.statusCode {
+				t.Errorf("expected code %d in the body, got %d", test.statusCode, body.Code)
+			}
+
+			if body.Message == "" {
+				t.Error("expected a message in the body")
+			}
+		})
+	}
+}
+
+func TestNotFoundHandlerFallsBackForNonAPIPaths(t *testing.T) {
+	fallback := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	handler := NotFoundHandler(fallback)
+
+	tests := []struct {
+		path       string
+		statusCode int
+	}{
+		{path: "/", statusCode: http.StatusTeapot},
+		{path: "/static/main.js", statusCode: http.StatusTeapot},
+		{path: "/eth/v1/unknown", statusCode: http.StatusNotFound},
+		{path: "/checkpointz/v1/unknown", statusCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if rec.Code != test.statusCode {
+			t.Errorf("%s: expected status %d, got %d", test.path, test.statusCode, rec.Code)
+		}
+	}
+}
+
+func TestNotFoundError(t *testing.T) {
+	if err := notFoundError(store.ErrBlockNotFound, "block not found"); err.Error() != "block not found" {
+		t.Errorf("expected the default message, got %q", err)
+	}
+
+	if err := notFoundError(beacon.ErrGenesisBundleUnavailable, "block not found"); !errors.Is(err, beacon.ErrGenesisBundleUnavailable) {
+		t.Errorf("expected genesis being unavailable to be reported, got %q", err)
+	}
+}
+
+// fakePinProvider pins any root other than notFinalized. Only the pinning methods are implemented.
+type fakePinProvider struct {
+	beacon.FinalityProvider
+
+	pinned       *v1.Finality
+	notFinalized phase0.Root
+}
+
+func (f *fakePinProvider) Pin(ctx context.Context, root phase0.Root) error {
+	if root == f.notFinalized {
+		return beacon.ErrNotFinalizedCheckpoint
+	}
+
+	f.pinned = &v1.Finality{Finalized: &phase0.Checkpoint{Epoch: 2, Root: root}}
+
+	return nil
+}
+
+func (f *fakePinProvider) Unpin(ctx context.Context) {
+	f.pinned = nil
+}
+
+func (f *fakePinProvider) Pinned(ctx context.Context) *v1.Finality {
+	return f.pinned
+}
+
+func TestAdminPin(t *testing.T) {
+	provider := &fakePinProvider{notFinalized: phase0.Root{0x02}}
+
+	h := &Handler{
+		log:            logrus.New(),
+		checkpointz:    checkpointz.NewHandler(logrus.New(), provider),
+		adminEndpoints: true,
+		metrics:        NewMetrics("test_admin_pin"),
+	}
+
+	router := httprouter.New()
+	if err := h.RegisterAdmin(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	pinned := fmt.Sprintf("%#x", phase0.Root{0x01})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		statusCode int
+		pinned     bool
+	}{
+		{name: "pin", method: http.MethodPost, path: "/checkpointz/v1/admin/pin?root=" + pinned, statusCode: http.StatusOK, pinned: true},
+		{name: "missing root", method: http.MethodPost, path: "/checkpointz/v1/admin/pin", statusCode: http.StatusBadRequest, pinned: true},
+		{name: "not finalized", method: http.MethodPost, path: fmt.Sprintf("/checkpointz/v1/admin/pin?root=%#x", phase0.Root{0x02}), statusCode: http.StatusBadRequest, pinned: true},
+		{name: "unpin", method: http.MethodDelete, path: "/checkpointz/v1/admin/pin", statusCode: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+
+		if rec.Code != test.statusCode {
+			t.Errorf("%s: expected status %d, got %d: %s", test.name, test.statusCode, rec.Code, rec.Body.String())
+		}
+
+		if (provider.pinned != nil) != test.pinned {
+			t.Errorf("%s: expected pinned to be %v, got %v", test.name, test.pinned, provider.pinned)
+		}
+
+		if test.statusCode == http.StatusOK && !strings.Contains(rec.Body.String(), pinned) {
+			t.Errorf("%s: expected the pinned checkpoint in the response, got %s", test.name, rec.Body.String())
+		}
+	}
+}
+
+// fakeStateProvider serves a single state by its state root. Only the methods used to serve states are implemented.
+type fakeStateProvider struct {
+	beacon.FinalityProvider
+
+	stateRoot phase0.Root
+	state     []byte
+}
+
+func (f *fakeStateProvider) GetBeaconStateByStateRoot(ctx context.Context, root phase0.Root) (*[]byte, error) {
+	if root != f.stateRoot {
+		return nil, store.ErrStateNotFound
+	}
+
+	return &f.state, nil
+}
+
+func (f *fakeStateProvider) GetBlockByStateRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	if root != f.stateRoot {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return beacontest.Phase0Block(64, root), nil
+}
+
+func (f *fakeStateProvider) ForkAtSlot(ctx context.Context, slot phase0.Slot) (string, error) {
+	return "", errors.New("spec not known")
+}
+
+func TestDebugBeaconStates(t *testing.T) {
+	provider := &fakeStateProvider{stateRoot: phase0.Root{0x01}, state: []byte("state")}
+
+	h := &Handler{
+		log:     logrus.New(),
+		eth:     eth.NewHandler(logrus.New(), provider, "test_debug_states"),
+		metrics: NewMetrics("test_debug_states"),
+	}
+
+	router := httprouter.New()
+	if err := h.Register(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		stateRoot  phase0.Root
+		statusCode int
+		version    string
+	}{
+		{name: "cached", stateRoot: phase0.Root{0x01}, statusCode: http.StatusOK, version: "phase0"},
+		{name: "not cached", stateRoot: phase0.Root{0x02}, statusCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/eth/v2/debug/beacon/states/%#x", test.stateRoot), nil)
+			req.Header.Set("Accept", ContentTypeSSZ.String())
+
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != test.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", test.statusCode, rec.Code, rec.Body.String())
+			}
+
+			if version := rec.Header().Get("Eth-Consensus-Version"); version != test.version {
+				t.Errorf("expected consensus version %q, got %q", test.version, version)
+			}
+
+			if test.statusCode == http.StatusOK && rec.Body.String() != string(provider.state) {
+				t.Errorf("expected the state to be served, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+// fakeBlockProvider serves a single block by its slot. Only the methods used to look up blocks by slot are
+// implemented.
+type fakeBlockProvider struct {
+	beacon.FinalityProvider
+
+	block *spec.VersionedSignedBeaconBlock
+}
+
+func (f *fakeBlockProvider) GetBlockBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
+	if blockSlot, err := f.block.Slot(); err != nil || blockSlot != slot {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return f.block, nil
+}
+
+func (f *fakeBlockProvider) ForkAtSlot(ctx context.Context, slot phase0.Slot) (string, error) {
+	return "", errors.New("spec not known")
+}
+
+func TestBeaconBlocksRoot(t *testing.T) {
+	provider := &fakeBlockProvider{block: beacontest.Phase0Block(64, phase0.Root{0x01})}
+
+	root, err := provider.block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &Handler{
+		log:     logrus.New(),
+		eth:     eth.NewHandler(logrus.New(), provider, "test_blocks_root"),
+		metrics: NewMetrics("test_blocks_root"),
+	}
+
+	router := httprouter.New()
+	if err := h.Register(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		root       string
+	}{
+		{name: "cached", path: "/eth/v1/beacon/blocks/64/root", statusCode: http.StatusOK, root: fmt.Sprintf("%#x", root)},
+		{name: "not cached", path: "/eth/v1/beacon/blocks/65/root", statusCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			if rec.Code != test.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", test.statusCode, rec.Code, rec.Body.String())
+			}
+
+			if test.statusCode != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Data struct {
+					Root string `json:"root"`
+				} `json:"data"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected a json body, got %q: %v", rec.Body.String(), err)
+			}
+
+			if body.Data.Root != test.root {
+				t.Errorf("expected root %s, got %s", test.root, body.Data.Root)
+			}
+		})
+	}
+}
+
+// fakeConfigProvider serves the chain config once it is known. Only the config methods are implemented.
+type fakeConfigProvider struct {
+	beacon.FinalityProvider
+
+	spec    *state.Spec
+	genesis *v1.Genesis
+}
+
+func (f *fakeConfigProvider) Spec(ctx context.Context) (*state.Spec, error) {
+	if f.spec == nil {
+		return nil, fmt.Errorf("%w: no upstream is ready", beacon.ErrSpecNotAvailable)
+	}
+
+	return f.spec, nil
+}
+
+func (f *fakeConfigProvider) Genesis(ctx context.Context) (*v1.Genesis, error) {
+	if f.genesis == nil {
+		return nil, fmt.Errorf("%w: no upstream is ready", beacon.ErrGenesisNotAvailable)
+	}
+
+	return f.genesis, nil
+}
+
+func TestConfigSpecIsUnavailableUntilKnown(t *testing.T) {
+	provider := &fakeConfigProvider{}
+
+	h := &Handler{
+		log:     logrus.New(),
+		eth:     eth.NewHandler(logrus.New(), provider, "test_config_spec"),
+		metrics: NewMetrics("test_config_spec"),
+	}
+
+	router := httprouter.New()
+	if err := h.Register(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() int {
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/eth/v1/config/spec", nil))
+
+		return rec.Code
+	}
+
+	if code := get(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before the spec is known, got %d", http.StatusServiceUnavailable, code)
+	}
+
+	provider.spec = &state.Spec{SlotsPerEpoch: 32}
+
+	if code := get(); code != http.StatusOK {
+		t.Errorf("expected status %d once the spec is known, got %d", http.StatusOK, code)
+	}
+}
+
+func TestBeaconGenesisIsUnavailableUntilKnown(t *testing.T) {
+	provider := &fakeConfigProvider{}
+
+	h := &Handler{
+		log:     logrus.New(),
+		eth:     eth.NewHandler(logrus.New(), provider, "test_beacon_genesis"),
+		metrics: NewMetrics("test_beacon_genesis"),
+	}
+
+	router := httprouter.New()
+	if err := h.Register(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() int {
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/eth/v1/beacon/genesis", nil))
+
+		return rec.Code
+	}
+
+	if code := get(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d before genesis is known, got %d", http.StatusServiceUnavailable, code)
+	}
+
+	provider.genesis = &v1.Genesis{GenesisTime: time.Unix(1606824023, 0)}
+
+	if code := get(); code != http.StatusOK {
+		t.Errorf("expected status %d once genesis is known, got %d", http.StatusOK, code)
+	}
+}
+
+func TestBeaconBlocks(t *testing.T) {
+	provider := &fakeBlockProvider{block: beacontest.Phase0Block(64, phase0.Root{0x01})}
+
+	h := &Handler{
+		log:     logrus.New(),
+		eth:     eth.NewHandler(logrus.New(), provider, "test_blocks"),
+		metrics: NewMetrics("test_blocks"),
+	}
+
+	router := httprouter.New()
+	if err := h.Register(context.Background(), router); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		version    string
+	}{
+		{name: "v1", path: "/eth/v1/beacon/blocks/64", statusCode: http.StatusOK},
+		{name: "v2", path: "/eth/v2/beacon/blocks/64", statusCode: http.StatusOK, version: "phase0"},
+		{name: "v1 not cached", path: "/eth/v1/beacon/blocks/65", statusCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			if rec.Code != test.statusCode {
+				t.Fatalf("expected status %d, got %d: %s", test.statusCode, rec.Code, rec.Body.String())
+			}
+
+			if test.statusCode != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Version string          `json:"version"`
+				Data    json.RawMessage `json:"data"`
+			}
+
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("expected a json body, got %q: %v", rec.Body.String(), err)
+			}
+
+			if len(body.Data) == 0 {
+				t.Error("expected the block in the body")
+			}
+
+			// Only the v2 envelope has a version.
+			if body.Version != test.version {
+				t.Errorf("expected version %q in the body, got %q", test.version, body.Version)
+			}
+
+			if version := rec.Header().Get("Eth-Consensus-Version"); version != "phase0" {
+				t.Errorf("expected consensus version %q, got %q", "phase0", version)
+			}
+		})
+	}
+}
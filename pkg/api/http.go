@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 // WriteJSONResponse writes a JSON response to the given writer.
@@ -37,6 +38,8 @@ func WriteContentAwareResponse(w http.ResponseWriter, data []byte, contentType C
 	}
 }
 
+// WriteErrorResponse writes an error shaped as the Beacon API expects, {"code":404,"message":"..."}, with the given
+// status code.
 func WriteErrorResponse(w http.ResponseWriter, msg string, statusCode int) error {
 	w.Header().Set("Content-Type", ContentTypeJSON.String())
 
@@ -57,3 +60,27 @@ func WriteErrorResponse(w http.ResponseWriter, msg string, statusCode int) error
 
 	return nil
 }
+
+// errorHandler responds to every request with a Beacon API error.
+func errorHandler(statusCode int, msg string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//nolint:errcheck // nothing more can be done if writing the error fails.
+		WriteErrorResponse(w, msg, statusCode)
+	})
+}
+
+// NotFoundHandler responds to requests for unknown API paths with a Beacon API error. Requests for any other path
+// are passed to fallback if it is set, so the frontend can be served from the same router.
+func NotFoundHandler(fallback http.Handler) http.Handler {
+	notFound := errorHandler(http.StatusNotFound, "not found")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fallback == nil || strings.HasPrefix(r.URL.Path, "/eth/") || strings.HasPrefix(r.URL.Path, "/checkpointz/") {
+			notFound.ServeHTTP(w, r)
+
+			return
+		}
+
+		fallback.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// servingLimiter bounds how many block and state responses are served at once, as each one holds a copy of the
+// encoded block or state in memory while it is written.
+type servingLimiter struct {
+	slots chan struct{}
+	// wait is how long a request waits for a slot before it is rejected.
+	wait time.Duration
+	// retryAfter is sent to rejected requests as the Retry-After header.
+	retryAfter time.Duration
+}
+
+// newServingLimiter returns a limiter that serves at most maxServes requests at once. Returns nil, which doesn't limit
+// anything, if maxServes is 0.
+func newServingLimiter(maxServes int, wait, retryAfter time.Duration) *servingLimiter {
+	if maxServes <= 0 {
+		return nil
+	}
+
+	return &servingLimiter{
+		slots:      make(chan struct{}, maxServes),
+		wait:       wait,
+		retryAfter: retryAfter,
+	}
+}
+
+// acquire takes a slot, waiting for one to be released for up to the limiter's wait. Returns false if no slot was
+// taken.
+func (l *servingLimiter) acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if l.wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *servingLimiter) release() {
+	<-l.slots
+}
+
+// limit rejects requests with a 503 while the limiter is full, rather than building ever more responses at once.
+func (l *servingLimiter) limit(handle httprouter.Handle) httprouter.Handle {
+	if l == nil {
+		return handle
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if !l.acquire(r.Context()) {
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(l.retryAfter.Seconds()))))
+
+			//nolint:errcheck // nothing more can be done if writing the error fails.
+			WriteErrorResponse(w, "too many concurrent requests, try again later", http.StatusServiceUnavailable)
+
+			return
+		}
+
+		defer l.release()
+
+		handle(w, r, p)
+	}
+}
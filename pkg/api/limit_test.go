@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestServingLimiterRejectsRequestsOverTheLimit(t *testing.T) {
+	const (
+		limit    = 2
+		requests = 5
+	)
+
+	limiter := newServingLimiter(limit, 0, 3*time.Second)
+
+	started := make(chan struct{}, requests)
+	unblock := make(chan struct{})
+
+	handle := limiter.limit(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	})
+
+	codes := make(chan *httptest.ResponseRecorder, requests)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			rec := httptest.NewRecorder()
+			handle(rec, httptest.NewRequest(http.MethodGet, "/eth/v2/debug/beacon/states/finalized", nil), nil)
+			codes <- rec
+		}()
+	}
+
+	// Wait for the requests that got a slot to be in progress, then for the rest to be rejected.
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	for i := 0; i < requests-limit; i++ {
+		rec := <-codes
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected requests over the limit to get %d, got %d", http.StatusServiceUnavailable, rec.Code)
+		}
+
+		if retryAfter := rec.Header().Get("Retry-After"); retryAfter != "3" {
+			t.Errorf("expected a Retry-After of 3, got %q", retryAfter)
+		}
+	}
+
+	close(unblock)
+	wg.Wait()
+	close(codes)
+
+	for rec := range codes {
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected requests within the limit to succeed, got %d", rec.Code)
+		}
+	}
+}
+
+func TestServingLimiterWaitsForASlot(t *testing.T) {
+	limiter := newServingLimiter(1, time.Second, time.Second)
+
+	release := make(chan struct{})
+
+	handle := limiter.limit(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if !limiter.acquire(httptest.NewRequest(http.MethodGet, "/", nil).Context()) {
+		t.Fatal("expected to acquire the only slot")
+	}
+
+	close(release)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		limiter.release()
+	}()
+
+	rec := httptest.NewRecorder()
+	handle(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the request to be served once a slot was released, got %d", rec.Code)
+	}
+}
+
+func TestServingLimiterDisabled(t *testing.T) {
+	if limiter := newServingLimiter(0, 0, 0); limiter != nil {
+		t.Fatal("expected no limiter when the limit is 0")
+	}
+
+	called := false
+
+	var limiter *servingLimiter
+
+	limiter.limit(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		called = true
+	})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), nil)
+
+	if !called {
+		t.Error("expected a nil limiter to not limit requests")
+	}
+}
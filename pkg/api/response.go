@@ -2,10 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+// ErrUnsupportedContentType is returned when a response can't be encoded as the requested content type.
+var ErrUnsupportedContentType = errors.New("unsupported content-type")
+
 type ContentTypeResolver func() ([]byte, error)
 type ContentTypeResolvers map[ContentType]ContentTypeResolver
 
@@ -24,7 +28,7 @@ type jsonResponse struct {
 
 func (r HTTPResponse) MarshalAs(contentType ContentType) ([]byte, error) {
 	if _, exists := r.resolvers[contentType]; !exists {
-		return nil, fmt.Errorf("unsupported content-type: %s", contentType.String())
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType.String())
 	}
 
 	if contentType != ContentTypeJSON {
@@ -69,6 +73,42 @@ func NewBadRequestResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	}
 }
 
+func NewNotFoundResponse(resolvers ContentTypeResolvers) *HTTPResponse {
+	return &HTTPResponse{
+		resolvers:  resolvers,
+		StatusCode: http.StatusNotFound,
+		Headers:    make(map[string]string),
+		ExtraData:  make(map[string]interface{}),
+	}
+}
+
+func NewConflictResponse(resolvers ContentTypeResolvers) *HTTPResponse {
+	return &HTTPResponse{
+		resolvers:  resolvers,
+		StatusCode: http.StatusConflict,
+		Headers:    make(map[string]string),
+		ExtraData:  make(map[string]interface{}),
+	}
+}
+
+func NewServiceUnavailableResponse(resolvers ContentTypeResolvers) *HTTPResponse {
+	return &HTTPResponse{
+		resolvers:  resolvers,
+		StatusCode: http.StatusServiceUnavailable,
+		Headers:    make(map[string]string),
+		ExtraData:  make(map[string]interface{}),
+	}
+}
+
+func NewNotImplementedResponse(resolvers ContentTypeResolvers) *HTTPResponse {
+	return &HTTPResponse{
+		resolvers:  resolvers,
+		StatusCode: http.StatusNotImplemented,
+		Headers:    make(map[string]string),
+		ExtraData:  make(map[string]interface{}),
+	}
+}
+
 func NewUnsupportedMediaTypeResponse(resolvers ContentTypeResolvers) *HTTPResponse {
 	return &HTTPResponse{
 		resolvers:  resolvers,
@@ -0,0 +1,138 @@
+// Package beacontest provides minimal beacon blocks and states for tests.
+package beacontest
+
+import (
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// Phase0State returns a minimal phase0 state at slot that can be SSZ encoded and hashed.
+func Phase0State(slot phase0.Slot) *phase0.BeaconState {
+	return &phase0.BeaconState{
+		Slot:                        slot,
+		Fork:                        &phase0.Fork{},
+		LatestBlockHeader:           &phase0.BeaconBlockHeader{},
+		BlockRoots:                  make([]phase0.Root, 8192),
+		StateRoots:                  make([]phase0.Root, 8192),
+		HistoricalRoots:             []phase0.Root{},
+		ETH1Data:                    &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+		ETH1DataVotes:               []*phase0.ETH1Data{},
+		Validators:                  []*phase0.Validator{},
+		Balances:                    []phase0.Gwei{},
+		RANDAOMixes:                 make([]phase0.Root, 65536),
+		Slashings:                   make([]phase0.Gwei, 8192),
+		PreviousEpochAttestations:   []*phase0.PendingAttestation{},
+		CurrentEpochAttestations:    []*phase0.PendingAttestation{},
+		JustificationBits:           []byte{0},
+		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
+		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
+		FinalizedCheckpoint:         &phase0.Checkpoint{},
+	}
+}
+
+// Phase0Block returns a phase0 block at slot with an empty body that commits to stateRoot.
+func Phase0Block(slot phase0.Slot, stateRoot phase0.Root) *spec.VersionedSignedBeaconBlock {
+	return &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionPhase0,
+		Phase0: &phase0.SignedBeaconBlock{
+			Message: &phase0.BeaconBlock{
+				Slot:      slot,
+				StateRoot: stateRoot,
+				Body: &phase0.BeaconBlockBody{
+					ETH1Data:          &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+					ProposerSlashings: []*phase0.ProposerSlashing{},
+					AttesterSlashings: []*phase0.AttesterSlashing{},
+					Attestations:      []*phase0.Attestation{},
+					Deposits:          []*phase0.Deposit{},
+					VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+				},
+			},
+		},
+	}
+}
+
+// Phase0Bundle returns a phase0 block at slot along with the SSZ encoding of the state it commits to.
+func Phase0Bundle(slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, []byte, error) {
+	state := Phase0State(slot)
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stateRoot, err := state.HashTreeRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return Phase0Block(slot, stateRoot), data, nil
+}
+
+// AltairState returns a minimal altair state at slot that can be SSZ encoded and hashed.
+func AltairState(slot phase0.Slot) *altair.BeaconState {
+	syncCommittee := &altair.SyncCommittee{Pubkeys: make([]phase0.BLSPubKey, 512)}
+
+	return &altair.BeaconState{
+		Slot:                        slot,
+		Fork:                        &phase0.Fork{},
+		LatestBlockHeader:           &phase0.BeaconBlockHeader{},
+		BlockRoots:                  make([]phase0.Root, 8192),
+		StateRoots:                  make([]phase0.Root, 8192),
+		HistoricalRoots:             []phase0.Root{},
+		ETH1Data:                    &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+		ETH1DataVotes:               []*phase0.ETH1Data{},
+		Validators:                  []*phase0.Validator{},
+		Balances:                    []phase0.Gwei{},
+		RANDAOMixes:                 make([]phase0.Root, 65536),
+		Slashings:                   make([]phase0.Gwei, 8192),
+		PreviousEpochParticipation:  []altair.ParticipationFlags{},
+		CurrentEpochParticipation:   []altair.ParticipationFlags{},
+		JustificationBits:           []byte{0},
+		PreviousJustifiedCheckpoint: &phase0.Checkpoint{},
+		CurrentJustifiedCheckpoint:  &phase0.Checkpoint{},
+		FinalizedCheckpoint:         &phase0.Checkpoint{},
+		InactivityScores:            []uint64{},
+		CurrentSyncCommittee:        syncCommittee,
+		NextSyncCommittee:           syncCommittee,
+	}
+}
+
+// AltairBlock returns an altair block at slot with an empty body that commits to stateRoot.
+func AltairBlock(slot phase0.Slot, stateRoot phase0.Root) *spec.VersionedSignedBeaconBlock {
+	return &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionAltair,
+		Altair: &altair.SignedBeaconBlock{
+			Message: &altair.BeaconBlock{
+				Slot:      slot,
+				StateRoot: stateRoot,
+				Body: &altair.BeaconBlockBody{
+					ETH1Data:          &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+					ProposerSlashings: []*phase0.ProposerSlashing{},
+					AttesterSlashings: []*phase0.AttesterSlashing{},
+					Attestations:      []*phase0.Attestation{},
+					Deposits:          []*phase0.Deposit{},
+					VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+					SyncAggregate:     &altair.SyncAggregate{SyncCommitteeBits: make([]byte, 64)},
+				},
+			},
+		},
+	}
+}
+
+// AltairBundle returns an altair block at slot along with the SSZ encoding of the state it commits to.
+func AltairBundle(slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, []byte, error) {
+	state := AltairState(slot)
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stateRoot, err := state.HashTreeRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return AltairBlock(slot, stateRoot), data, nil
+}
@@ -7,10 +7,17 @@ import (
 
 type Decider interface {
 	Decide(checkpoints []*v1.Finality) (*v1.Finality, error)
+	DecideWeighted(votes []majority.Vote) (*v1.Finality, error)
 }
 
 var _ Decider = (*majority.Decider)(nil)
 
-func NewMajorityDecider() *majority.Decider {
-	return &majority.Decider{}
+// NewMajorityDecider returns a Decider that requires more than the threshold fraction of checkpoints to agree. A
+// threshold of 1 or more is instead how many checkpoints must agree, as well as more than half of them.
+func NewMajorityDecider(threshold float64) *majority.Decider {
+	if threshold >= 1 {
+		return majority.NewWithMinVotes(int(threshold))
+	}
+
+	return majority.NewWithThreshold(threshold)
 }
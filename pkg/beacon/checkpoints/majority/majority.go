@@ -7,23 +7,79 @@ import (
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 )
 
-type Decider struct{}
+// DefaultThreshold requires a simple majority (more than half) of checkpoints to agree.
+const DefaultThreshold = 0.5
+
+type Decider struct {
+	// threshold is the fraction of checkpoints that must be exceeded for a finality to be decided.
+	threshold float64
+	// minVotes is how many checkpoints must agree for a finality to be decided, on top of the threshold.
+	minVotes int
+}
+
+// Vote is a finality checkpoint reported by an upstream, weighted by how much that upstream is trusted.
+type Vote struct {
+	Finality *v1.Finality
+	Weight   int
+}
 
 var (
 	ErrNoMajorityFound = errors.New("no majority finality found")
+	ErrNoQuorum        = errors.New("not enough checkpoints agree to reach quorum")
 )
 
 func New() *Decider {
-	return &Decider{}
+	return NewWithThreshold(DefaultThreshold)
 }
 
+// NewWithThreshold returns a Decider that requires more than the given fraction of checkpoints to agree.
+func NewWithThreshold(threshold float64) *Decider {
+	return &Decider{
+		threshold: threshold,
+	}
+}
+
+// NewWithMinVotes returns a Decider that requires at least minVotes checkpoints to agree, as well as more than half
+// of them.
+func NewWithMinVotes(minVotes int) *Decider {
+	return &Decider{
+		threshold: DefaultThreshold,
+		minVotes:  minVotes,
+	}
+}
+
+// Decide decides on a finality where every checkpoint carries an equal weight.
 func (m *Decider) Decide(checkpoints []*v1.Finality) (*v1.Finality, error) {
+	votes := make([]Vote, len(checkpoints))
+
+	for i, checkpoint := range checkpoints {
+		votes[i] = Vote{
+			Finality: checkpoint,
+			Weight:   1,
+		}
+	}
+
+	return m.DecideWeighted(votes)
+}
+
+// DecideWeighted decides on a finality by accumulating the weight of each vote per checkpoint.
+func (m *Decider) DecideWeighted(votes []Vote) (*v1.Finality, error) {
+	if len(votes) == 0 {
+		return nil, ErrNoMajorityFound
+	}
+
 	common := make(map[string]struct {
 		Finality *v1.Finality
 		Count    int
 	})
 
-	for _, checkpoint := range checkpoints {
+	totalWeight := 0
+
+	for _, vote := range votes {
+		checkpoint := vote.Finality
+
+		totalWeight += vote.Weight
+
 		key := eth.RootAsString(checkpoint.Finalized.Root) + "-" +
 			eth.RootAsString(checkpoint.Justified.Root) + "-" +
 			eth.RootAsString(checkpoint.PreviousJustified.Root)
@@ -40,16 +96,16 @@ func (m *Decider) Decide(checkpoints []*v1.Finality) (*v1.Finality, error) {
 
 		val, exists := common[key]
 		if exists {
-			val.Count++
+			val.Count += vote.Weight
 			common[key] = val
 		}
 	}
 
 	for _, v := range common {
-		if v.Count > len(checkpoints)/2 {
+		if float64(v.Count) > float64(totalWeight)*m.threshold && v.Count >= m.minVotes {
 			return v.Finality, nil
 		}
 	}
 
-	return nil, ErrNoMajorityFound
+	return nil, ErrNoQuorum
 }
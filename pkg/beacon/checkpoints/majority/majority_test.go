@@ -72,8 +72,8 @@ func TestNonMajority(t *testing.T) {
 	}
 
 	_, err := majority.Decide(payload)
-	if err != ErrNoMajorityFound {
-		t.Errorf("Expected %v, got %v", ErrNoMajorityFound, err)
+	if err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
 	}
 }
 
@@ -84,7 +84,136 @@ func TestSplitMajority(t *testing.T) {
 	}
 
 	_, err := majority.Decide(payload)
+	if err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
+	}
+}
+
+func TestNoCheckpoints(t *testing.T) {
+	_, err := majority.Decide([]*v1.Finality{})
 	if err != ErrNoMajorityFound {
 		t.Errorf("Expected %v, got %v", ErrNoMajorityFound, err)
 	}
 }
+
+func TestSuperMajorityThreshold(t *testing.T) {
+	superMajority := NewWithThreshold(0.66)
+
+	payload := []*v1.Finality{
+		finalityA,
+		finalityA,
+		finalityB,
+	}
+
+	finality, err := superMajority.Decide(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if finality.Finalized.Root != finalityA.Finalized.Root {
+		t.Errorf("Expected %v, got %v", finalityA, finality)
+	}
+
+	payload = []*v1.Finality{
+		finalityA,
+		finalityA,
+		finalityB,
+		finalityC,
+	}
+
+	_, err = superMajority.Decide(payload)
+	if err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
+	}
+}
+
+func TestThresholdMustBeExceeded(t *testing.T) {
+	// Exactly half agreeing doesn't exceed a threshold of 0.5.
+	payload := []*v1.Finality{
+		finalityA,
+		finalityA,
+		finalityB,
+		finalityC,
+	}
+
+	if _, err := majority.Decide(payload); err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
+	}
+}
+
+func TestMinVotes(t *testing.T) {
+	minVotes := NewWithMinVotes(3)
+
+	payload := []*v1.Finality{
+		finalityA,
+		finalityA,
+		finalityA,
+		finalityB,
+	}
+
+	finality, err := minVotes.Decide(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if finality.Finalized.Root != finalityA.Finalized.Root {
+		t.Errorf("Expected %v, got %v", finalityA, finality)
+	}
+
+	// A majority of fewer than 3 upstreams isn't enough.
+	payload = []*v1.Finality{
+		finalityA,
+		finalityA,
+		finalityB,
+	}
+
+	_, err = minVotes.Decide(payload)
+	if err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
+	}
+
+	// Nor are 3 upstreams that aren't a majority.
+	payload = []*v1.Finality{
+		finalityA,
+		finalityA,
+		finalityA,
+		finalityB,
+		finalityB,
+		finalityC,
+	}
+
+	_, err = minVotes.Decide(payload)
+	if err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
+	}
+}
+
+func TestWeightedMajority(t *testing.T) {
+	payload := []Vote{
+		{Finality: finalityA, Weight: 3},
+		{Finality: finalityB, Weight: 1},
+		{Finality: finalityC, Weight: 1},
+	}
+
+	finality, err := majority.DecideWeighted(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if finality.Finalized.Root != finalityA.Finalized.Root {
+		t.Errorf("Expected %v, got %v", finalityA, finality)
+	}
+}
+
+func TestWeightedEqualWeights(t *testing.T) {
+	payload := []Vote{
+		{Finality: finalityA, Weight: 2},
+		{Finality: finalityB, Weight: 2},
+		{Finality: finalityC, Weight: 2},
+	}
+
+	_, err := majority.DecideWeighted(payload)
+	if err != ErrNoQuorum {
+		t.Errorf("Expected %v, got %v", ErrNoQuorum, err)
+	}
+}
@@ -1,9 +1,14 @@
 package beacon
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
 )
 
@@ -17,8 +22,155 @@ type Config struct {
 	// HistoricalEpochCount determines how many historical epochs the provider will cache.
 	HistoricalEpochCount int `yaml:"historical_epoch_count" default:"20"`
 
+	// HistoricalFetchConcurrency determines how many historical blocks are fetched from an upstream at once.
+	HistoricalFetchConcurrency int `yaml:"historical_fetch_concurrency" default:"4"`
+
+	// WarmupEpochs determines how many of the most recent finalized epoch boundaries are downloaded once at startup,
+	// so a restarted instance can serve them straight away. 0 disables warmup.
+	WarmupEpochs int `yaml:"warmup_epochs" default:"0"`
+
+	// FetchGenesis determines if the genesis block and state are fetched and served. The mainnet genesis state is
+	// large and rarely needed by checkpoint syncing clients, so memory constrained instances may want to skip it.
+	FetchGenesis bool `yaml:"fetch_genesis" default:"true"`
+
+	// GenesisFetchDeadline is how long the genesis bundle is retried for before it is given up on, e.g. because the
+	// upstreams have pruned the genesis state. 0 retries forever.
+	GenesisFetchDeadline time.Duration `yaml:"genesis_fetch_deadline" default:"0s"`
+
+	// DownloadConcurrency determines how many different bundles are downloaded at once, each from its own
+	// randomly chosen upstream. 0 is unlimited.
+	DownloadConcurrency int `yaml:"download_concurrency" default:"0"`
+
+	// BundleDownloadStaleTimeout is how long a bundle download can be in flight before it is abandoned so the bundle
+	// can be downloaded again, e.g. if an upstream stops responding without the request timing out. 0 disables it.
+	BundleDownloadStaleTimeout time.Duration `yaml:"bundle_download_stale_timeout" default:"30m"`
+
+	// BundleDownloadMaxAttempts determines how many upstreams a bundle download is attempted against before giving up.
+	BundleDownloadMaxAttempts int `yaml:"bundle_download_max_attempts" default:"3"`
+
+	// BlockRetention is how long blocks and states are served for after their slot. This bounds how long we will
+	// happily serve finality data for after the chain has stopped finalizing. The genesis block and state never expire.
+	// TODO(sam.calder-mason): Derive from weak subjectivity period.
+	BlockRetention time.Duration `yaml:"block_retention" default:"336h"`
+
+	// StartupJitter is the upper bound of a random delay before the first finality and genesis checks, so that
+	// many instances pointed at the same upstream don't poll it in lockstep. 0 disables the delay.
+	StartupJitter time.Duration `yaml:"startup_jitter" default:"5s"`
+
+	// ServedBundleHistory is how many of the most recently served bundles are remembered, so the finalized
+	// state can still be served from a just-superseded bundle if the current one's state is unavailable.
+	ServedBundleHistory int `yaml:"served_bundle_history" default:"2"`
+
+	// FinalityPollInterval is how often the upstreams are polled for finality.
+	FinalityPollInterval time.Duration `yaml:"finality_poll_interval" default:"5s"`
+
+	// FinalityFailureLogInterval is how often a repeated failure to get finality from the same upstream is logged.
+	// The suppressed repeats are counted in the next log line. 0 logs every failure.
+	FinalityFailureLogInterval time.Duration `yaml:"finality_failure_log_interval" default:"1m"`
+
+	// Tracing logs how long each stage of bundle downloads and finality checks takes, at debug level.
+	// Ignored if Tracer is set.
+	Tracing bool `yaml:"tracing"`
+	// Tracer traces the stages of bundle downloads and finality checks, e.g. with an OpenTelemetry adapter.
+	// It can only be set in code.
+	Tracer Tracer `yaml:"-"`
+
+	// MaxStateBytes is the largest state that will be stored. A bigger state is assumed to be corrupt or malicious
+	// and is fetched from another upstream instead. 0 is unlimited.
+	MaxStateBytes int64 `yaml:"max_state_bytes"`
+
+	// ServedStateCheckInterval is how often the served state is decoded and checked against its block's state root.
+	// The instance is unhealthy while the check fails. Decoding a state is expensive so this shouldn't be frequent.
+	// 0 disables the check.
+	ServedStateCheckInterval time.Duration `yaml:"served_state_check_interval" default:"5m"`
+
+	// FinalityMode sets how the finalized checkpoint is decided.
+	FinalityMode FinalityMode `yaml:"finality_mode" default:"majority"`
+
+	// HealthStrategy sets how upstreams are judged ready to be used.
+	HealthStrategy HealthStrategyName `yaml:"health_strategy" default:"loose"`
+
+	// MaxReadyFinalityLag is how many epochs an upstream's finality can be behind the finalized head before it is
+	// dropped from the ready upstreams until it catches up. 0 disables the check.
+	MaxReadyFinalityLag int `yaml:"max_ready_finality_lag" default:"0"`
+
+	// MaxFinalityAge is how many epochs an upstream's finalized epoch can be behind the wall clock epoch before its
+	// finality is left out of the vote. Unlike MaxReadyFinalityLag it still applies when every upstream is stuck.
+	// 0 disables the check.
+	MaxFinalityAge int `yaml:"max_finality_age" default:"1024"`
+
+	// TrustedNode is the name of the upstream finality is taken from in single-trusted finality mode.
+	TrustedNode string `yaml:"trusted_node"`
+
+	// MinFinalityAgreement is the fraction of ready upstreams that must be exceeded before a finalized checkpoint is
+	// accepted, or, if it is a whole number of 1 or more, how many upstreams must agree on top of a simple majority.
+	// Defaults to a simple majority.
+	MinFinalityAgreement float64 `yaml:"min_finality_agreement" default:"0.5"`
+
+	// MinReadyNodes is how many upstreams must be ready before the finalized head is updated. The instance is
+	// unhealthy while fewer are ready.
+	MinReadyNodes int `yaml:"min_ready_nodes" default:"1"`
+
+	// MinEpochsBehindHead is how many epochs a finalized checkpoint must be behind the wall clock epoch
+	// before it is served. 0 serves new checkpoints as soon as they are finalized.
+	MinEpochsBehindHead int `yaml:"min_epochs_behind_head" default:"0"`
+
+	// MaxFinalityStallEpochs is how many epochs may pass without the upstreams agreeing on finality before
+	// the provider stops serving its checkpoint and reports itself as unhealthy. 0 disables the check.
+	MaxFinalityStallEpochs int `yaml:"max_finality_stall_epochs" default:"0"`
+
+	// ExpectedGenesisValidatorsRoot pins the network upstreams must be on. If empty, the network of the
+	// first upstream we fetch genesis from is pinned instead.
+	ExpectedGenesisValidatorsRoot string `yaml:"expected_genesis_validators_root"`
+
+	// ExpectedDepositChainID excludes upstreams whose DEPOSIT_CHAIN_ID differs. 0 disables the check.
+	ExpectedDepositChainID uint64 `yaml:"expected_deposit_chain_id"`
+
+	// ExpectedDepositContractAddress excludes upstreams whose DEPOSIT_CONTRACT_ADDRESS differs. Empty disables the check.
+	ExpectedDepositContractAddress string `yaml:"expected_deposit_contract_address"`
+
+	// VerifyAcrossUpstreams requires two upstreams to serve the finalized block at the finalized slot before a new
+	// checkpoint is served, so a single upstream can't decide what is served even if it wins a weak majority.
+	// Requires at least two data provider upstreams.
+	VerifyAcrossUpstreams bool `yaml:"verify_across_upstreams" default:"false"`
+
+	// DebugCacheEndpoint enables an endpoint listing everything in the block and state stores.
+	// The response can be large so it is disabled by default.
+	DebugCacheEndpoint bool `yaml:"debug_cache_endpoint" default:"false"`
+
+	// ValidatorsEndpoints enables the validators and validator balances endpoints, which are served by decoding
+	// cached states. Decoding a state is expensive so they are disabled by default.
+	ValidatorsEndpoints bool `yaml:"validators_endpoints" default:"false"`
+
+	// InitialBlockFile and InitialStateFile are an SSZ encoded block and state that are imported and served at startup,
+	// instead of waiting for a bundle to be downloaded from an upstream. Both must be set, along with the fork
+	// version of the bundle in InitialBundleVersion, e.g. "capella".
+	InitialBlockFile     string `yaml:"initial_block_file"`
+	InitialStateFile     string `yaml:"initial_state_file"`
+	InitialBundleVersion string `yaml:"initial_bundle_version"`
+
+	// AdminEndpoints enables endpoints that change how the instance is operating, e.g. forcing a finality check.
+	// They are not authenticated so should only be enabled if the API is not publicly reachable.
+	AdminEndpoints bool `yaml:"admin_endpoints" default:"false"`
+
+	// MaxConcurrentServes is how many blocks and states are served at once. Requests over the limit wait up to
+	// ServeQueueTimeout for another to finish, then get a 503 with a Retry-After of ServeRetryAfter. 0 is unlimited.
+	MaxConcurrentServes int           `yaml:"max_concurrent_serves" default:"0"`
+	ServeQueueTimeout   time.Duration `yaml:"serve_queue_timeout" default:"0s"`
+	ServeRetryAfter     time.Duration `yaml:"serve_retry_after" default:"5s"`
+
+	// CacheHead enables periodically fetching the unfinalized head block and state so they can be served as
+	// "head". Head states are large and change every slot so this is disabled by default.
+	CacheHead bool `yaml:"cache_head" default:"false"`
+
 	// Cache holds configuration for the caches.
 	Frontend FrontendConfig `yaml:"frontend"`
+
+	// Persistence holds configuration for persisting the caches to disk.
+	Persistence PersistenceConfig `yaml:"persistence"`
+
+	// SpecOverrides holds values that take precedence over the spec and genesis fetched from the upstreams.
+	SpecOverrides SpecOverridesConfig `yaml:"spec_overrides"`
 }
 
 // Cache configuration holds configuration for the caches.
@@ -45,11 +197,106 @@ type FrontendConfig struct {
 	BrandImageURL string `yaml:"brand_image_url"`
 }
 
+// PersistenceConfig holds configuration for persisting the block and state caches across restarts.
+type PersistenceConfig struct {
+	// Enabled flag enables writing the caches to disk on shutdown and loading them on startup.
+	Enabled bool `yaml:"enabled" default:"false"`
+
+	// Directory is the directory the caches are written to.
+	Directory string `yaml:"directory" default:"./data"`
+}
+
+// SpecOverridesConfig holds values that replace those fetched from the upstreams, for custom networks whose upstreams
+// report them unreliably. Zero values aren't overridden.
+type SpecOverridesConfig struct {
+	// SlotsPerEpoch overrides SLOTS_PER_EPOCH.
+	SlotsPerEpoch uint64 `yaml:"slots_per_epoch"`
+
+	// SecondsPerSlot overrides SECONDS_PER_SLOT.
+	SecondsPerSlot uint64 `yaml:"seconds_per_slot"`
+
+	// GenesisTime overrides the genesis time, as a unix timestamp in seconds.
+	GenesisTime uint64 `yaml:"genesis_time"`
+}
+
+func (c *SpecOverridesConfig) Validate() error {
+	if c.SlotsPerEpoch > maxSlotsPerEpochOverride {
+		return fmt.Errorf("slots_per_epoch (%d) cannot be higher than %d", c.SlotsPerEpoch, maxSlotsPerEpochOverride)
+	}
+
+	if c.SecondsPerSlot > maxSecondsPerSlotOverride {
+		return fmt.Errorf("seconds_per_slot (%d) cannot be higher than %d", c.SecondsPerSlot, maxSecondsPerSlotOverride)
+	}
+
+	// Catches timestamps in milliseconds, which would put genesis thousands of years in the future.
+	if c.GenesisTime > maxGenesisTimeOverride {
+		return fmt.Errorf("genesis_time (%d) must be a unix timestamp in seconds", c.GenesisTime)
+	}
+
+	return nil
+}
+
 func (c *Config) Validate() error {
 	if c.HistoricalEpochCount < 1 {
 		return errors.New("historical_epoch_count must be at least 1")
 	}
 
+	if c.BlockRetention <= 0 {
+		return errors.New("block_retention must be positive")
+	}
+
+	if c.StartupJitter < 0 {
+		return errors.New("startup_jitter cannot be negative")
+	}
+
+	if c.MaxStateBytes < 0 {
+		return errors.New("max_state_bytes cannot be negative")
+	}
+
+	if c.ServedStateCheckInterval < 0 {
+		return errors.New("served_state_check_interval cannot be negative")
+	}
+
+	if c.FinalityPollInterval < time.Second {
+		return fmt.Errorf("finality_poll_interval (%s) must be at least 1s", c.FinalityPollInterval)
+	}
+
+	if c.FinalityFailureLogInterval < 0 {
+		return errors.New("finality_failure_log_interval cannot be negative")
+	}
+
+	switch c.FinalityMode {
+	case FinalityModeMajority:
+	case FinalityModeSingleTrusted:
+		if c.TrustedNode == "" {
+			return errors.New("trusted_node is required in single-trusted finality mode")
+		}
+	default:
+		return fmt.Errorf("invalid finality_mode: %s", c.FinalityMode)
+	}
+
+	switch c.HealthStrategy {
+	case HealthStrategyLoose, HealthStrategyStrict:
+	default:
+		return fmt.Errorf("invalid health_strategy: %s", c.HealthStrategy)
+	}
+
+	if c.BundleDownloadMaxAttempts < 1 {
+		return errors.New("bundle_download_max_attempts must be at least 1")
+	}
+
+	if c.DownloadConcurrency < 0 {
+		return errors.New("download_concurrency cannot be negative")
+	}
+
+	if c.BundleDownloadStaleTimeout < 0 {
+		return errors.New("bundle_download_stale_timeout cannot be negative")
+	}
+
+	if c.HistoricalFetchConcurrency < 1 {
+		return errors.New("historical_fetch_concurrency must be at least 1")
+	}
+
 	if err := c.Caches.Validate(); err != nil {
 		return fmt.Errorf("invalid caches config: %s", err)
 	}
@@ -58,10 +305,103 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("historical_epoch_count (%d) must be less than caches.blocks.max_items (%d)", c.HistoricalEpochCount, c.Caches.Blocks.MaxItems)
 	}
 
+	if c.WarmupEpochs < 0 {
+		return errors.New("warmup_epochs cannot be negative")
+	}
+
+	if c.WarmupEpochs > c.HistoricalEpochCount {
+		return fmt.Errorf("warmup_epochs (%d) cannot be higher than historical_epoch_count (%d)", c.WarmupEpochs, c.HistoricalEpochCount)
+	}
+
+	if c.Mode == OperatingModeFull && c.WarmupEpochs >= c.Caches.States.MaxItems {
+		return fmt.Errorf("warmup_epochs (%d) must be less than caches.states.max_items (%d) in full mode", c.WarmupEpochs, c.Caches.States.MaxItems)
+	}
+
+	if c.ServedBundleHistory < 1 {
+		return errors.New("served_bundle_history must be at least 1")
+	}
+
+	if c.ServedBundleHistory > c.Caches.States.MaxItems {
+		return fmt.Errorf("served_bundle_history (%d) cannot be higher than caches.states.max_items (%d)", c.ServedBundleHistory, c.Caches.States.MaxItems)
+	}
+
 	if c.HistoricalEpochCount > 200 {
 		return fmt.Errorf("historical_epoch_count (%d) cannot be higher than 200", c.HistoricalEpochCount)
 	}
 
+	if c.ExpectedGenesisValidatorsRoot != "" {
+		if _, err := parseRoot(c.ExpectedGenesisValidatorsRoot); err != nil {
+			return fmt.Errorf("invalid expected_genesis_validators_root: %s", err)
+		}
+	}
+
+	if c.ExpectedDepositContractAddress != "" {
+		b, err := hex.DecodeString(strings.TrimPrefix(c.ExpectedDepositContractAddress, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid expected_deposit_contract_address: %s", err)
+		}
+
+		if len(b) != 20 {
+			return fmt.Errorf("invalid expected_deposit_contract_address: incorrect length %d for address", len(b))
+		}
+	}
+
+	if c.GenesisFetchDeadline < 0 {
+		return errors.New("genesis_fetch_deadline cannot be negative")
+	}
+
+	if c.MaxConcurrentServes < 0 {
+		return errors.New("max_concurrent_serves cannot be negative")
+	}
+
+	if c.ServeQueueTimeout < 0 {
+		return errors.New("serve_queue_timeout cannot be negative")
+	}
+
+	if c.ServeRetryAfter < 0 {
+		return errors.New("serve_retry_after cannot be negative")
+	}
+
+	if c.MaxReadyFinalityLag < 0 {
+		return errors.New("max_ready_finality_lag cannot be negative")
+	}
+
+	if c.MaxFinalityAge < 0 {
+		return errors.New("max_finality_age cannot be negative")
+	}
+
+	if c.MinEpochsBehindHead < 0 {
+		return errors.New("min_epochs_behind_head cannot be negative")
+	}
+
+	if c.MaxFinalityStallEpochs < 0 {
+		return errors.New("max_finality_stall_epochs cannot be negative")
+	}
+
+	if (c.InitialBlockFile == "") != (c.InitialStateFile == "") {
+		return errors.New("initial_block_file and initial_state_file must be set together")
+	}
+
+	if c.InitialBlockFile != "" && c.InitialBundleVersion == "" {
+		return errors.New("initial_bundle_version is required when importing an initial bundle")
+	}
+
+	if c.Persistence.Enabled && c.Persistence.Directory == "" {
+		return errors.New("persistence.directory is required when persistence is enabled")
+	}
+
+	if c.MinFinalityAgreement < 0.5 || (c.MinFinalityAgreement >= 1 && c.MinFinalityAgreement != math.Trunc(c.MinFinalityAgreement)) {
+		return fmt.Errorf("min_finality_agreement (%v) must be a fraction of at least 0.5, or a whole number of upstreams", c.MinFinalityAgreement)
+	}
+
+	if c.MinReadyNodes < 1 {
+		return errors.New("min_ready_nodes must be at least 1")
+	}
+
+	if err := c.SpecOverrides.Validate(); err != nil {
+		return fmt.Errorf("invalid spec_overrides config: %s", err)
+	}
+
 	return nil
 }
 
@@ -82,5 +422,26 @@ func (c *CacheConfig) Validate() error {
 		return errors.New("states.max_items must be at least 3")
 	}
 
+	if c.Blocks.MaxBytes != 0 {
+		return errors.New("blocks.max_bytes is not supported, use blocks.max_items")
+	}
+
 	return nil
 }
+
+func parseRoot(s string) (phase0.Root, error) {
+	root := phase0.Root{}
+
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return root, err
+	}
+
+	if len(b) != len(root) {
+		return root, fmt.Errorf("incorrect length %d for root", len(b))
+	}
+
+	copy(root[:], b)
+
+	return root, nil
+}
@@ -0,0 +1,76 @@
+package beacon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creasty/defaults"
+)
+
+func TestConfigValidateFinalityPollInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		valid    bool
+	}{
+		{"default", 5 * time.Second, true},
+		{"minimum", time.Second, true},
+		{"sub-second", 500 * time.Millisecond, false},
+		{"zero", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &Config{}
+			if err := defaults.Set(config); err != nil {
+				t.Fatal(err)
+			}
+
+			config.FinalityPollInterval = test.interval
+
+			err := config.Validate()
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestConfigValidateMinFinalityAgreement(t *testing.T) {
+	tests := []struct {
+		name      string
+		agreement float64
+		valid     bool
+	}{
+		{"simple majority", 0.5, true},
+		{"super majority", 0.66, true},
+		{"less than half", 0.4, false},
+		{"one upstream", 1, true},
+		{"three upstreams", 3, true},
+		{"fraction of upstreams", 2.5, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &Config{}
+			if err := defaults.Set(config); err != nil {
+				t.Fatal(err)
+			}
+
+			config.MinFinalityAgreement = test.agreement
+
+			err := config.Validate()
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
@@ -13,6 +17,7 @@ import (
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/checkpoints"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/checkpoints/majority"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
@@ -25,20 +30,82 @@ type Default struct {
 
 	config      *Config
 	nodeConfigs []node.Config
-	nodes       Nodes
 	broker      *emission.Emitter
+	namespace   string
 
-	head          *v1.Finality
+	// nodes are the upstreams. They can be replaced by ReloadUpstreams so are read through upstreams().
+	nodes   Nodes
+	nodesMu sync.RWMutex
+	// upstreamNames are the names of every upstream created, as the beacon library's metrics can only be
+	// registered once per name.
+	upstreamNames map[string]struct{}
+
+	// healthStrategy decides which upstreams are ready to be used.
+	healthStrategy HealthStrategy
+
+	// finalityCheckMu serialises scheduled and manually triggered finality checks.
+	finalityCheckMu sync.Mutex
+
+	// head is the finalized head decided by the last finality check. It is read through finalityHead().
+	head   *v1.Finality
+	headMu sync.RWMutex
+	// servingBundle is the finalized bundle being served, and pinned is the bundle an operator has pinned, if any.
 	servingBundle *v1.Finality
+	pinned        *v1.Finality
+	servingMu     sync.RWMutex
+	// servingJustified is the justified checkpoint whose bundle has been downloaded. Guarded by servingMu.
+	servingJustified *phase0.Checkpoint
+	// servedBundles holds the most recently served bundles, newest first, so clients mid-sync against a
+	// just-superseded checkpoint can still be served.
+	servedBundles   []*v1.Finality
+	servedBundlesMu sync.RWMutex
+	// servingSince is when the bundle being served was first served. Guarded by servedBundlesMu.
+	servingSince time.Time
+	// lastQuorumAt is when the upstreams last agreed on finality.
+	lastQuorumAt time.Time
+	lastQuorumMu sync.RWMutex
+
+	// tracer traces bundle downloads and finality checks. Nil when tracing is disabled.
+	tracer Tracer
+
+	// forkMismatches holds why each upstream whose fork schedule differs from the majority's isn't used as a data
+	// provider, by upstream name.
+	forkMismatches   map[string]error
+	forkMismatchesMu sync.RWMutex
+
+	// genesisGaveUp is set once the genesis bundle couldn't be fetched within GenesisFetchDeadline.
+	genesisGaveUp        bool
+	genesisUnavailableMu sync.RWMutex
+
+	// servedStateErr is the result of the last check of the served state.
+	servedStateErr error
+	servedStateMu  sync.RWMutex
+
+	// cachedHead is the unfinalized head, only fetched if CacheHead is enabled.
+	cachedHead   *cachedHead
+	cachedHeadMu sync.RWMutex
 
 	blocks           *store.Block
 	states           *store.BeaconState
 	depositSnapshots *store.DepositSnapshot
 
-	spec    *state.Spec
-	genesis *v1.Genesis
+	spec      *state.Spec
+	specMu    sync.Mutex
+	genesis   *v1.Genesis
+	genesisMu sync.Mutex
+
+	// networkRoot is the genesis validators root upstreams must share with us to be used.
+	networkRoot   *phase0.Root
+	networkRootMu sync.RWMutex
 
 	historicalSlotFailures map[phase0.Slot]int
+	bundleDownloads        *bundleDownloads
+
+	// fetchQueue holds the roots of the bundle fetches queued by QueueBundleFetch that haven't finished yet.
+	fetchQueue   map[phase0.Root]time.Time
+	fetchQueueMu sync.Mutex
+
+	scheduler *gocron.Scheduler
 
 	metrics *Metrics
 }
@@ -49,23 +116,27 @@ var (
 	topicFinalityHeadUpdated = "finality_head_updated"
 )
 
-const (
-	// FinalityHaltedServingPeriod defines how long we will happily serve finality data for after the chain has stopped finality.
-	// TODO(sam.calder-mason): Derive from weak subjectivity period.
-	FinalityHaltedServingPeriod = 14 * 24 * time.Hour
-)
+// genesisLoopInterval is how often the genesis time and bundle are checked for.
+const genesisLoopInterval = 5 * time.Second
 
 func NewDefaultProvider(namespace string, log logrus.FieldLogger, nodes []node.Config, config *Config) FinalityProvider {
-	return &Default{
+	d := &Default{
 		nodeConfigs: nodes,
 		log:         log.WithField("module", "beacon/default"),
 		nodes:       NewNodesFromConfig(log, nodes, namespace),
 		config:      config,
+		namespace:   namespace,
+
+		upstreamNames: make(map[string]struct{}),
+
+		healthStrategy: NewHealthStrategy(config.HealthStrategy),
 
 		head:          &v1.Finality{},
 		servingBundle: &v1.Finality{},
 
 		historicalSlotFailures: make(map[phase0.Slot]int),
+		bundleDownloads:        newBundleDownloads(config.DownloadConcurrency, config.BundleDownloadStaleTimeout, log),
+		fetchQueue:             make(map[phase0.Root]time.Time),
 
 		broker:           emission.NewEmitter(),
 		blocks:           store.NewBlock(log, config.Caches.Blocks, namespace),
@@ -74,21 +145,72 @@ func NewDefaultProvider(namespace string, log logrus.FieldLogger, nodes []node.C
 
 		metrics: NewMetrics(namespace + "_beacon"),
 	}
+
+	switch {
+	case config.Tracer != nil:
+		d.tracer = config.Tracer
+	case config.Tracing:
+		d.tracer = newLogTracer(log)
+	}
+
+	for _, upstream := range d.nodes {
+		d.setupUpstream(upstream)
+	}
+
+	if config.ExpectedGenesisValidatorsRoot != "" {
+		if root, err := parseRoot(config.ExpectedGenesisValidatorsRoot); err == nil {
+			d.networkRoot = &root
+		}
+	}
+
+	d.blocks.OnEvicted(func(root phase0.Root, slot phase0.Slot) {
+		d.log.WithField("root", eth.RootAsString(root)).WithField("slot", slot).Debug("Block evicted from the store")
+	})
+
+	d.states.OnEvicted(func(stateRoot phase0.Root, slot phase0.Slot) {
+		d.log.WithField("state_root", eth.RootAsString(stateRoot)).WithField("slot", slot).Debug("State evicted from the store")
+	})
+
+	return d
 }
 
 func (d *Default) Start(ctx context.Context) error {
 	d.log.Infof("Starting Finality provider in %s mode", d.OperatingMode())
+	d.log.WithField("block_retention", d.config.BlockRetention.String()).Info("Blocks and states will be retained after their slot")
+	d.log.WithField("interval", d.config.FinalityPollInterval.String()).Info("Upstreams will be polled for finality")
+
+	if !d.config.FetchGenesis {
+		d.log.Warn("Genesis bundle fetching is disabled - saving memory, but clients that sync from genesis or request the genesis block or state will get a 404")
+	}
 
 	d.metrics.ObserveOperatingMode(d.OperatingMode())
+	d.metrics.ObserveServingCheckpointUpdated(time.Now())
+
+	if d.config.Persistence.Enabled {
+		d.loadPersistedCaches()
+	}
+
+	if d.config.InitialBlockFile != "" {
+		block, err := d.importInitialBundle()
+		if err != nil {
+			return fmt.Errorf("failed to import initial bundle: %w", err)
+		}
 
-	if err := d.nodes.StartAll(ctx); err != nil {
+		go func() {
+			if err := d.serveInitialBundle(ctx, block); err != nil && !errors.Is(err, context.Canceled) {
+				d.log.WithError(err).Error("Failed to serve the initial checkpoint bundle")
+			}
+		}()
+	}
+
+	if err := d.upstreams().StartAll(ctx); err != nil {
 		return err
 	}
 
 	go func() {
 		for {
 			// Wait until we have a single healthy node.
-			_, err := d.nodes.Healthy(ctx).NotSyncing(ctx).RandomNode(ctx)
+			_, err := d.upstreams().Healthy(ctx).NotSyncing(ctx).RandomNode(ctx)
 			if err != nil {
 				d.log.WithError(err).Error("Waiting for a healthy, non-syncing node before beginning..")
 				time.Sleep(time.Second * 5)
@@ -96,6 +218,16 @@ func (d *Default) Start(ctx context.Context) error {
 				continue
 			}
 
+			if delay := startupDelay(d.config.StartupJitter); delay > 0 {
+				d.log.WithField("delay", delay.String()).Debug("Delaying first checks to spread load on upstreams")
+
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+
 			if err := d.startCrons(ctx); err != nil {
 				d.log.WithError(err).Fatal("Failed to start crons")
 			}
@@ -107,10 +239,21 @@ func (d *Default) Start(ctx context.Context) error {
 	return nil
 }
 
+// startupDelay returns a random delay between 0 and jitter.
+func startupDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(jitter)))
+}
+
 func (d *Default) startCrons(ctx context.Context) error {
 	s := gocron.NewScheduler(time.Local)
 
-	if _, err := s.Every("5s").Do(func() {
+	d.scheduler = s
+
+	if _, err := s.Every(d.config.FinalityPollInterval).Do(func() {
 		if err := d.checkFinality(ctx); err != nil {
 			d.log.WithError(err).Error("Failed to check finality")
 		}
@@ -119,6 +262,8 @@ func (d *Default) startCrons(ctx context.Context) error {
 	}
 
 	if _, err := s.Every("10s").Do(func() {
+		d.checkForkSchedules(ctx)
+
 		if err := d.checkBeaconSpec(ctx); err != nil {
 			d.log.WithError(err).Error("Failed to check beacon chain spec")
 		}
@@ -126,6 +271,16 @@ func (d *Default) startCrons(ctx context.Context) error {
 		return err
 	}
 
+	if d.config.ServedStateCheckInterval > 0 {
+		if _, err := s.Every(d.config.ServedStateCheckInterval).Do(func() {
+			if err := d.checkServedState(ctx); err != nil {
+				d.log.WithError(err).Error("Served state failed its integrity check")
+			}
+		}); err != nil {
+			return err
+		}
+	}
+
 	go func() {
 		if err := d.startGenesisLoop(ctx); err != nil {
 			d.log.WithError(err).Fatal("Failed to start genesis loop")
@@ -144,11 +299,84 @@ func (d *Default) startCrons(ctx context.Context) error {
 		}
 	}()
 
+	if d.config.CacheHead {
+		go func() {
+			if err := d.startHeadLoop(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				d.log.WithError(err).Error("Failed to start head loop")
+			}
+		}()
+	}
+
+	if d.config.WarmupEpochs > 0 {
+		go func() {
+			if err := d.startWarmup(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				d.log.WithError(err).Error("Failed to warm up recent finalized checkpoints")
+			}
+		}()
+	}
+
 	s.StartAsync()
 
 	return nil
 }
 
+func (d *Default) Stop(ctx context.Context) error {
+	d.log.Info("Stopping Finality provider")
+
+	if d.scheduler != nil {
+		d.scheduler.Stop()
+	}
+
+	// Give in-flight bundle downloads a chance to finish so we don't persist a half downloaded bundle, cancelling
+	// them if they don't finish in time.
+	if err := d.bundleDownloads.Wait(ctx); err != nil {
+		d.log.WithError(err).Warn("Cancelling in-flight bundle downloads")
+
+		d.bundleDownloads.Cancel()
+	}
+
+	for _, node := range d.upstreams() {
+		if err := node.Beacon.Stop(ctx); err != nil {
+			d.log.WithError(err).WithField("upstream", node.Config.Name).Warn("Failed to stop upstream")
+		}
+	}
+
+	if !d.config.Persistence.Enabled {
+		return nil
+	}
+
+	if err := d.blocks.Persist(filepath.Join(d.config.Persistence.Directory, "blocks")); err != nil {
+		return fmt.Errorf("failed to persist blocks: %w", err)
+	}
+
+	if err := d.states.Persist(filepath.Join(d.config.Persistence.Directory, "states")); err != nil {
+		return fmt.Errorf("failed to persist states: %w", err)
+	}
+
+	return nil
+}
+
+func (d *Default) loadPersistedCaches() {
+	if err := d.blocks.Load(filepath.Join(d.config.Persistence.Directory, "blocks")); err != nil {
+		d.log.WithError(err).Warn("Failed to load persisted blocks")
+	}
+
+	if err := d.states.Load(filepath.Join(d.config.Persistence.Directory, "states"), d.verifyPersistedState); err != nil {
+		d.log.WithError(err).Warn("Failed to load persisted states")
+	}
+}
+
+// verifyPersistedState checks a state loaded from disk hashes to the state root of its block. The blocks are loaded
+// first, so a state whose block wasn't loaded is rejected.
+func (d *Default) verifyPersistedState(stateRoot phase0.Root, state []byte) error {
+	block, err := d.blocks.GetByStateRoot(stateRoot)
+	if err != nil {
+		return err
+	}
+
+	return VerifyStateRoot(block, state)
+}
+
 func (d *Default) StartAsync(ctx context.Context) {
 	go func() {
 		if err := d.Start(ctx); err != nil {
@@ -158,39 +386,62 @@ func (d *Default) StartAsync(ctx context.Context) {
 }
 
 func (d *Default) startGenesisLoop(ctx context.Context) error {
-	if err := d.checkGenesis(ctx); err != nil {
-		d.log.WithError(err).Error("Failed to check for genesis bundle")
-	}
+	// Retry a failing genesis bundle fetch with backoff so a transient failure at boot doesn't hammer upstreams
+	// with requests for the (potentially very large) genesis state.
+	backoff := node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
 
-	if err := d.checkGenesisTime(ctx); err != nil {
-		d.log.WithError(err).Error("Failed to check genesis time")
-	}
+	deadline := time.Now().Add(d.config.GenesisFetchDeadline)
 
 	for {
-		select {
-		case <-time.After(time.Second * 15):
-			if err := d.checkGenesisTime(ctx); err != nil {
-				d.log.WithError(err).Error("Failed to check genesis time")
-			}
+		if err := d.checkGenesisTime(ctx); err != nil {
+			d.log.WithError(err).Error("Failed to check genesis time")
+		}
 
-			if err := d.checkGenesis(ctx); err != nil {
-				d.log.WithError(err).Error("Failed to check for genesis")
-			}
+		d.checkGenesisWithBackoff(ctx, backoff, deadline)
+
+		select {
+		case <-time.After(genesisLoopInterval):
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
+// checkGenesisWithBackoff checks for the genesis bundle unless a previous failure is still being backed off from,
+// giving up on it once the deadline has passed.
+func (d *Default) checkGenesisWithBackoff(ctx context.Context, backoff *node.Backoff, deadline time.Time) {
+	if !d.config.FetchGenesis || d.genesisUnavailable() || !backoff.Ready(time.Now()) {
+		return
+	}
+
+	if err := d.checkGenesis(ctx); err != nil {
+		backoff.Failure(time.Now())
+
+		if d.config.GenesisFetchDeadline > 0 && time.Now().After(deadline) {
+			d.giveUpOnGenesis()
+		}
+
+		d.log.
+			WithError(err).
+			WithField("backoff", backoff.Interval().String()).
+			Error("Failed to check for genesis bundle")
+
+		return
+	}
+
+	backoff.Success()
+}
+
 func (d *Default) startHistoricalLoop(ctx context.Context) error {
 	for {
 		select {
 		case <-time.After(time.Second * 15):
-			if d.head == nil || d.head.Finalized == nil {
+			head := d.finalityHead()
+			if head == nil || head.Finalized == nil {
 				continue
 			}
 
-			if err := d.fetchHistoricalCheckpoints(ctx, d.head); err != nil {
+			if err := d.fetchHistoricalCheckpoints(ctx, head); err != nil {
 				d.log.WithError(err).Error("Failed to fetch historical checkpoints")
 			}
 		case <-ctx.Done():
@@ -207,6 +458,14 @@ func (d *Default) startServingLoop(ctx context.Context) error {
 				d.log.WithError(err).Error("Failed to check for new serving checkpoint")
 
 				time.Sleep(time.Second * 30)
+
+				continue
+			}
+
+			if err := d.checkForNewJustifiedCheckpoint(ctx); err != nil {
+				d.log.WithError(err).Error("Failed to check for new justified checkpoint")
+
+				time.Sleep(time.Second * 30)
 			}
 		case <-ctx.Done():
 			return ctx.Err()
@@ -215,29 +474,109 @@ func (d *Default) startServingLoop(ctx context.Context) error {
 }
 
 func (d *Default) checkForNewServingCheckpoint(ctx context.Context) error {
+	head := d.finalityHead()
+
 	// Don't bother checking if we don't know the head yet.
-	if d.head == nil {
+	if head == nil {
+		return nil
+	}
+
+	// An operator has pinned the checkpoint we serve.
+	if d.Pinned(ctx) != nil {
 		return nil
 	}
 
-	if d.head.Finalized == nil {
+	if head.Finalized == nil {
 		return nil
 	}
 
+	serving := d.serving()
+
 	// If head == serving, we're done.
-	if d.servingBundle != nil && d.servingBundle.Finalized != nil && d.servingBundle.Finalized.Epoch == d.head.Finalized.Epoch {
+	if serving != nil && serving.Finalized != nil && serving.Finalized.Epoch == head.Finalized.Epoch {
 		return nil
 	}
 
-	if err := d.downloadServingCheckpoint(ctx, d.head); err != nil {
+	// Keep serving the previous bundle until the new one is far enough behind the wall clock.
+	// If we're not serving anything yet there is nothing to fall back to, so serve it immediately.
+	if d.config.MinEpochsBehindHead > 0 && serving != nil && serving.Finalized != nil {
+		genesis, err := d.Genesis(ctx)
+		if err != nil {
+			return err
+		}
+
+		sp, err := d.Spec(ctx)
+		if err != nil {
+			return err
+		}
+
+		currentEpoch := eth.CalculateWallClockEpoch(time.Now(), genesis.GenesisTime, sp.SecondsPerSlot.AsDuration(), sp.SlotsPerEpoch)
+		if head.Finalized.Epoch+phase0.Epoch(d.config.MinEpochsBehindHead) > currentEpoch {
+			return nil
+		}
+	}
+
+	if err := d.downloadServingCheckpoint(ctx, head); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// Healthy returns false if no upstream is healthy or finality has stalled. Blocks and states that are already cached
+// keep being served while unhealthy.
 func (d *Default) Healthy(ctx context.Context) (bool, error) {
-	if len(d.nodes.Healthy(ctx)) == 0 {
+	if len(d.onExpectedNetwork(ctx, d.upstreams().Healthy(ctx))) == 0 {
+		return false, nil
+	}
+
+	if err := d.FinalityStalled(ctx); err != nil {
+		return false, nil
+	}
+
+	if err := d.servedStateCorrupt(); err != nil {
+		return false, nil
+	}
+
+	if _, ok := d.enoughReadyNodes(ctx); !ok {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// enoughReadyNodes returns how many upstreams are ready, and whether that meets min_ready_nodes.
+func (d *Default) enoughReadyNodes(ctx context.Context) (int, bool) {
+	ready := len(d.readyNodes(ctx))
+
+	return ready, ready >= d.config.MinReadyNodes
+}
+
+func (d *Default) Ready(ctx context.Context) (bool, error) {
+	finality, err := d.Finalized(ctx)
+	if err != nil {
+		return false, nil
+	}
+
+	if finality == nil || finality.Finalized == nil {
+		return false, nil
+	}
+
+	block, err := d.blocks.GetByRoot(finality.Finalized.Root)
+	if err != nil {
+		return false, nil
+	}
+
+	if !d.shouldDownloadStates() {
+		return true, nil
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); err != nil {
 		return false, nil
 	}
 
@@ -247,7 +586,7 @@ func (d *Default) Healthy(ctx context.Context) (bool, error) {
 func (d *Default) Peers(ctx context.Context) (types.Peers, error) {
 	peers := types.Peers{}
 
-	for _, node := range d.nodes {
+	for _, node := range d.upstreams() {
 		status := "connected"
 
 		if node.Beacon.Status().Syncing() || !node.Beacon.Status().Healthy() {
@@ -264,13 +603,15 @@ func (d *Default) Peers(ctx context.Context) (types.Peers, error) {
 	return peers, nil
 }
 
+// Syncing reports the sync status of the checkpoint being served, so Checkpointz looks like a beacon node to tools
+// that check it before syncing. The head slot is the first slot of the served checkpoint's epoch and the sync distance
+// is how far that is behind the wall clock. Checkpointz is syncing until it serves a checkpoint, and while every
+// healthy upstream is syncing.
 func (d *Default) Syncing(ctx context.Context) (*v1.SyncState, error) {
-	syncing := len(d.nodes.Healthy(ctx).Syncing(ctx)) == len(d.nodes.Healthy(ctx))
+	healthy := d.upstreams().Healthy(ctx)
 
 	syncState := &v1.SyncState{
-		IsSyncing:    syncing,
-		HeadSlot:     0,
-		SyncDistance: 0,
+		IsSyncing: true,
 	}
 
 	sp, err := d.Spec(ctx)
@@ -282,130 +623,781 @@ func (d *Default) Syncing(ctx context.Context) (*v1.SyncState, error) {
 		return syncState, errors.New("spec unknown")
 	}
 
-	if d.head != nil && d.head.Finalized != nil {
-		syncState.HeadSlot = phase0.Slot(d.head.Finalized.Epoch) * sp.SlotsPerEpoch
+	serving := d.serving()
+	if !HasFinalized(serving) {
+		return syncState, nil
+	}
+
+	syncState.IsSyncing = len(healthy.Syncing(ctx)) == len(healthy)
+	syncState.HeadSlot = phase0.Slot(serving.Finalized.Epoch) * sp.SlotsPerEpoch
+
+	genesis, err := d.Genesis(ctx)
+	if err != nil {
+		return syncState, err
 	}
 
-	if d.servingBundle != nil && d.servingBundle.Finalized != nil {
-		syncState.SyncDistance = syncState.HeadSlot - phase0.Slot(d.servingBundle.Finalized.Epoch)*sp.SlotsPerEpoch
+	if currentSlot := eth.CalculateWallClockSlot(time.Now(), genesis.GenesisTime, sp.SecondsPerSlot.AsDuration()); currentSlot > syncState.HeadSlot {
+		syncState.SyncDistance = currentSlot - syncState.HeadSlot
 	}
 
 	return syncState, nil
 }
 
-func (d *Default) Finalized(ctx context.Context) (*v1.Finality, error) {
-	return d.servingBundle, nil
-}
+// checkForNewJustifiedCheckpoint downloads the bundle for the head's justified checkpoint if we don't have it yet.
+func (d *Default) checkForNewJustifiedCheckpoint(ctx context.Context) error {
+	head := d.finalityHead()
+	if head == nil || head.Justified == nil || d.Pinned(ctx) != nil {
+		return nil
+	}
 
-func (d *Default) Head(ctx context.Context) (*v1.Finality, error) {
-	return d.head, nil
-}
+	justified := head.Justified
 
-func (d *Default) Genesis(ctx context.Context) (*v1.Genesis, error) {
-	if d.genesis == nil {
-		return nil, errors.New("genesis bundle not yet available")
+	if justified.Root == (phase0.Root{}) {
+		return nil
 	}
 
-	return d.genesis, nil
-}
+	if serving := d.justified(); serving != nil && serving.Root == justified.Root {
+		return nil
+	}
 
-func (d *Default) Spec(ctx context.Context) (*state.Spec, error) {
-	if d.spec == nil {
-		return nil, errors.New("config spec not yet available")
+	if _, err := d.fetchBundleWithFallback(ctx, justified.Root, d.dataProviders(ctx)); err != nil {
+		return err
 	}
 
-	return d.spec, nil
-}
+	d.servingMu.Lock()
+	d.servingJustified = justified
+	d.servingMu.Unlock()
 
-func (d *Default) OperatingMode() OperatingMode {
-	return d.config.Mode
-}
+	d.log.WithFields(
+		logrus.Fields{
+			"epoch": justified.Epoch,
+			"root":  fmt.Sprintf("%#x", justified.Root),
+		},
+	).Info("Serving a new justified checkpoint bundle")
 
-func (d *Default) shouldDownloadStates() bool {
-	return d.OperatingMode() == OperatingModeFull
+	return nil
 }
 
-func (d *Default) checkFinality(ctx context.Context) error {
-	aggFinality := []*v1.Finality{}
-	readyNodes := d.nodes.Ready(ctx)
+// Justified returns the justified checkpoint whose bundle is being served.
+func (d *Default) Justified(ctx context.Context) (*phase0.Checkpoint, error) {
+	if err := d.FinalityStalled(ctx); err != nil {
+		return nil, err
+	}
 
-	for _, node := range readyNodes {
-		finality, err := node.Beacon.Finality()
-		if err != nil {
-			d.log.Infof("Failed to get finality from node %s", node.Config.Name)
+	justified := d.justified()
+	if justified == nil {
+		return nil, ErrJustifiedNotAvailable
+	}
 
-			continue
-		}
+	return justified, nil
+}
 
-		aggFinality = append(aggFinality, finality)
-	}
+// justified returns the justified checkpoint whose bundle has been downloaded.
+func (d *Default) justified() *phase0.Checkpoint {
+	d.servingMu.RLock()
+	defer d.servingMu.RUnlock()
 
-	Default, err := checkpoints.NewMajorityDecider().Decide(aggFinality)
-	if err != nil {
-		return err
+	return d.servingJustified
+}
+
+func (d *Default) Finalized(ctx context.Context) (*v1.Finality, error) {
+	if err := d.FinalityStalled(ctx); err != nil {
+		return nil, err
 	}
 
-	if d.head == nil || d.head.Finalized == nil || d.head.Finalized.Root != Default.Finalized.Root {
-		d.head = Default
+	return d.serving(), nil
+}
 
-		d.publishFinalityCheckpointHeadUpdated(ctx, Default)
+// finalityHead returns the finalized head decided by the last finality check.
+func (d *Default) finalityHead() *v1.Finality {
+	d.headMu.RLock()
+	defer d.headMu.RUnlock()
 
-		d.log.WithField("epoch", Default.Finalized.Epoch).WithField("root", fmt.Sprintf("%#x", Default.Finalized.Root)).Info("New finalized head checkpoint")
+	return d.head
+}
 
-		d.metrics.ObserveHeadEpoch(Default.Finalized.Epoch)
-	}
+// serving returns the finalized bundle being served.
+func (d *Default) serving() *v1.Finality {
+	d.servingMu.RLock()
+	defer d.servingMu.RUnlock()
 
-	return nil
+	return d.servingBundle
 }
 
-func (d *Default) checkBeaconSpec(ctx context.Context) error {
-	// No-Op if we already have a beacon spec
-	if d.spec != nil {
+func (d *Default) LastFinalityQuorum(ctx context.Context) time.Time {
+	d.lastQuorumMu.RLock()
+	defer d.lastQuorumMu.RUnlock()
+
+	return d.lastQuorumAt
+}
+
+func (d *Default) FinalityStalled(ctx context.Context) error {
+	// A pinned checkpoint was explicitly chosen so is served regardless of the upstreams.
+	if d.Pinned(ctx) != nil {
 		return nil
 	}
 
-	d.log.Debug("Fetching beacon spec")
+	d.lastQuorumMu.RLock()
+	lastQuorumAt := d.lastQuorumAt
+	d.lastQuorumMu.RUnlock()
 
-	upstream, err := d.nodes.Ready(ctx).DataProviders(ctx).RandomNode(ctx)
-	if err != nil {
-		return err
+	if d.config.MaxFinalityStallEpochs == 0 || lastQuorumAt.IsZero() {
+		return nil
 	}
 
-	s, err := upstream.Beacon.Spec()
+	sp, err := d.Spec(ctx)
 	if err != nil {
-		return err
+		return nil
 	}
 
-	// store the beacon state spec
-	d.spec = s
+	epochDuration := sp.SecondsPerSlot.AsDuration() * time.Duration(sp.SlotsPerEpoch)
+	if epochDuration == 0 {
+		return nil
+	}
 
-	d.log.Info("Fetched beacon spec")
+	stalledEpochs := int(time.Since(lastQuorumAt) / epochDuration)
+	if stalledEpochs <= d.config.MaxFinalityStallEpochs {
+		return nil
+	}
 
-	return nil
+	return fmt.Errorf("%w for %d epochs", ErrFinalityStalled, stalledEpochs)
 }
 
-func (d *Default) checkGenesisTime(ctx context.Context) error {
-	// No-Op if we already have a genesis time
-	if d.genesis != nil {
-		return nil
+func (d *Default) Head(ctx context.Context) (*v1.Finality, error) {
+	return d.finalityHead(), nil
+}
+
+// Genesis returns the chain genesis, fetching it from a data provider the first time it is requested.
+func (d *Default) Genesis(ctx context.Context) (*v1.Genesis, error) {
+	if err := d.checkGenesisTime(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrGenesisNotAvailable, err)
 	}
 
-	d.log.Debug("Fetching genesis time")
+	d.genesisMu.Lock()
+	defer d.genesisMu.Unlock()
+
+	return d.genesis, nil
+}
+
+// Spec returns the chain spec, fetching it from a data provider the first time it is requested.
+func (d *Default) Spec(ctx context.Context) (*state.Spec, error) {
+	if err := d.checkBeaconSpec(ctx); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSpecNotAvailable, err)
+	}
+
+	d.specMu.Lock()
+	defer d.specMu.Unlock()
+
+	return d.spec, nil
+}
 
-	upstream, err := d.nodes.Ready(ctx).DataProviders(ctx).RandomNode(ctx)
+// ForkAtSlot returns the name of the fork active at the given slot, e.g. "capella".
+func (d *Default) ForkAtSlot(ctx context.Context, slot phase0.Slot) (string, error) {
+	sp, err := d.Spec(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	g, err := upstream.Beacon.Genesis()
+	return eth.ForkAtSlot(slot, sp)
+}
+
+// Pin serves the bundle of the given finalized checkpoint until Unpin is called, regardless of what the upstreams
+// finalize. As with QueueBundleFetch, an upstream is asked to confirm the root is a finalized checkpoint first,
+// returning ErrNotFinalizedCheckpoint if it isn't.
+func (d *Default) Pin(ctx context.Context, root phase0.Root) error {
+	upstreams := d.dataProviders(ctx)
+
+	_, epoch, err := d.verifyFinalizedCheckpoint(ctx, root, upstreams)
 	if err != nil {
 		return err
 	}
 
-	// store the genesis time
-	d.genesis = g
+	if !d.bundleCached(root) {
+		if _, err := d.fetchBundleWithFallback(ctx, root, upstreams); err != nil {
+			return fmt.Errorf("failed to fetch pinned bundle: %w", err)
+		}
+	}
 
-	d.log.Info("Fetched genesis time")
+	pinned := &v1.Finality{
+		Finalized: &phase0.Checkpoint{
+			Epoch: epoch,
+			Root:  root,
+		},
+	}
+
+	d.servingMu.Lock()
+	d.pinned = pinned
+	d.servingMu.Unlock()
+
+	d.serveBundle(pinned)
+
+	d.log.
+		WithField("epoch", pinned.Finalized.Epoch).
+		WithField("root", eth.RootAsString(root)).
+		Info("Pinned finalized checkpoint bundle")
+
+	return nil
+}
+
+// ServedBundles returns the most recently served bundles, newest first.
+func (d *Default) ServedBundles(ctx context.Context) []*v1.Finality {
+	d.servedBundlesMu.RLock()
+	defer d.servedBundlesMu.RUnlock()
+
+	bundles := make([]*v1.Finality, len(d.servedBundles))
+	copy(bundles, d.servedBundles)
+
+	return bundles
+}
+
+// serveBundle makes the given bundle the one being served, remembering it as recently served. While a bundle is
+// pinned no other bundle is served, so a download started before the pin can't replace it. Returns false if the
+// bundle wasn't served.
+func (d *Default) serveBundle(bundle *v1.Finality) bool {
+	d.servingMu.Lock()
+	defer d.servingMu.Unlock()
+
+	if d.pinned != nil && d.pinned != bundle {
+		return false
+	}
+
+	if d.servingBundle == nil || d.servingBundle.Finalized == nil || d.servingBundle.Finalized.Root != bundle.Finalized.Root {
+		now := time.Now()
+
+		d.metrics.ObserveServingCheckpointUpdated(now)
+		d.metrics.ObserveServingSince(now)
+
+		// The last check of the served state was of the previous bundle's state.
+		d.clearServedStateCorrupt()
+
+		d.servedBundlesMu.Lock()
+
+		d.servingSince = now
+
+		d.servedBundles = append([]*v1.Finality{bundle}, d.servedBundles...)
+		d.setBundleStatePinned(bundle, true)
+
+		if len(d.servedBundles) > d.config.ServedBundleHistory {
+			for _, dropped := range d.servedBundles[d.config.ServedBundleHistory:] {
+				d.setBundleStatePinned(dropped, false)
+			}
+
+			d.servedBundles = d.servedBundles[:d.config.ServedBundleHistory]
+		}
+
+		d.servedBundlesMu.Unlock()
+	}
+
+	d.servingBundle = bundle
+	d.metrics.ObserveServingEpoch(bundle.Finalized.Epoch)
+
+	return true
+}
+
+// setBundleStatePinned sets if the bundle's state is protected from being evicted to make room for other states,
+// so the states of the bundles we are serving, or could fall back to serving, are kept.
+func (d *Default) setBundleStatePinned(bundle *v1.Finality, pinned bool) {
+	if !d.shouldDownloadStates() {
+		return
+	}
+
+	block, err := d.blocks.GetByRoot(bundle.Finalized.Root)
+	if err != nil {
+		return
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return
+	}
+
+	if pinned {
+		err = d.states.Pin(stateRoot)
+	} else {
+		err = d.states.Unpin(stateRoot)
+	}
+
+	if err != nil {
+		d.log.WithError(err).WithField("state_root", eth.RootAsString(stateRoot)).WithField("pinned", pinned).Debug("Failed to update state pinning")
+	}
+}
+
+// ServingSince returns when the bundle being served was first served. Zero if nothing has been served.
+func (d *Default) ServingSince(ctx context.Context) time.Time {
+	d.servedBundlesMu.RLock()
+	defer d.servedBundlesMu.RUnlock()
+
+	return d.servingSince
+}
+
+// Unpin stops serving the pinned bundle. The finalized head is served again once the serving loop next runs.
+func (d *Default) Unpin(ctx context.Context) {
+	d.servingMu.Lock()
+	pinned := d.pinned
+	d.pinned = nil
+	d.servingMu.Unlock()
+
+	if pinned == nil {
+		return
+	}
+
+	d.log.Info("Unpinned finalized checkpoint bundle, resuming serving the finalized head")
+}
+
+// Pinned returns the pinned bundle, or nil if nothing is pinned.
+func (d *Default) Pinned(ctx context.Context) *v1.Finality {
+	d.servingMu.RLock()
+	defer d.servingMu.RUnlock()
+
+	return d.pinned
+}
+
+func (d *Default) OperatingMode() OperatingMode {
+	return d.config.Mode
+}
+
+func (d *Default) shouldDownloadStates() bool {
+	return d.OperatingMode() == OperatingModeFull
+}
+
+// RecheckFinality checks the upstreams for finality immediately instead of waiting for the next scheduled check.
+func (d *Default) RecheckFinality(ctx context.Context) (*v1.Finality, error) {
+	if err := d.checkFinality(ctx); err != nil {
+		return nil, err
+	}
+
+	return d.Head(ctx)
+}
+
+func (d *Default) checkFinality(ctx context.Context) (err error) {
+	ctx, span := d.startSpan(ctx, "check_finality")
+	defer func() { endSpan(span, err) }()
+
+	d.finalityCheckMu.Lock()
+	defer d.finalityCheckMu.Unlock()
+
+	var finality *v1.Finality
+
+	if ready, ok := d.enoughReadyNodes(ctx); !ok {
+		d.log.
+			WithField("ready_nodes", ready).
+			WithField("min_ready_nodes", d.config.MinReadyNodes).
+			Warn("Not enough upstreams are ready, not updating head")
+	} else {
+		switch d.config.FinalityMode {
+		case FinalityModeSingleTrusted:
+			finality, err = d.trustedFinality(ctx)
+		default:
+			finality, err = d.majorityFinality(ctx)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// No finality was agreed on this time around.
+	if finality == nil {
+		if stallErr := d.FinalityStalled(ctx); stallErr != nil {
+			d.log.WithError(stallErr).Warn("No longer serving the finalized checkpoint")
+		}
+
+		return nil
+	}
+
+	// Never serve the zero root that upstreams report before the network first finalizes. Their votes still count
+	// towards the quorum, so a minority of finalized upstreams can't decide the head on their own.
+	if !HasFinalized(finality) {
+		d.log.Debug("Network has not finalized yet")
+
+		return nil
+	}
+
+	d.lastQuorumMu.Lock()
+	d.lastQuorumAt = time.Now()
+	d.lastQuorumMu.Unlock()
+
+	if head := d.finalityHead(); head == nil || head.Finalized == nil || head.Finalized.Root != finality.Finalized.Root {
+		d.headMu.Lock()
+		d.head = finality
+		d.headMu.Unlock()
+
+		d.publishFinalityCheckpointHeadUpdated(ctx, finality)
+
+		d.log.WithField("epoch", finality.Finalized.Epoch).WithField("root", fmt.Sprintf("%#x", finality.Finalized.Root)).Info("New finalized head checkpoint")
+
+		d.metrics.ObserveHeadEpoch(finality.Finalized.Epoch)
+	}
+
+	for _, node := range d.upstreams() {
+		d.upstreamFinalityLag(node)
+	}
+
+	return nil
+}
+
+// upstreamFinalityLag returns how many epochs the node's finality is behind the head, recording it in the metrics.
+func (d *Default) upstreamFinalityLag(node *Node) (phase0.Epoch, bool) {
+	finality, err := node.Beacon.Finality()
+	if err != nil {
+		return 0, false
+	}
+
+	lag, ok := FinalityLag(d.finalityHead(), finality)
+	if ok {
+		d.metrics.ObserveUpstreamFinalityLag(node.Config.Name, lag)
+	}
+
+	return lag, ok
+}
+
+// majorityFinality returns the finality agreed on by the ready upstreams, or nil if they did not reach quorum.
+func (d *Default) majorityFinality(ctx context.Context) (*v1.Finality, error) {
+	aggFinality := []majority.Vote{}
+	readyNodes := d.readyNodes(ctx)
+
+	for _, node := range readyNodes {
+		finality, err := d.nodeFinality(ctx, node)
+		if err != nil {
+			continue
+		}
+
+		if err := d.finalityAgeError(finality); err != nil {
+			d.log.WithError(err).WithField("node", node.Config.Name).Debug("Leaving upstream's finality out of the vote")
+
+			continue
+		}
+
+		aggFinality = append(aggFinality, majority.Vote{
+			Finality: finality,
+			Weight:   node.Config.VoteWeight(),
+		})
+	}
+
+	// With every upstream down there is nothing to decide on, but we keep serving whatever is cached.
+	if len(aggFinality) == 0 {
+		d.log.
+			WithField("ready_nodes", len(readyNodes)).
+			Warn("No upstreams reported finality, serving from the cache without updating head")
+
+		return nil, nil
+	}
+
+	// Surface a split upstream set even when a majority can still be reached.
+	if roots := DistinctFinalizedRoots(aggFinality); roots > 1 {
+		d.metrics.ObserveFinalityDisagreement(roots)
+	}
+
+	finality, err := checkpoints.NewMajorityDecider(d.config.MinFinalityAgreement).DecideWeighted(aggFinality)
+	if err != nil {
+		if errors.Is(err, majority.ErrNoQuorum) {
+			d.log.
+				WithField("threshold", d.config.MinFinalityAgreement).
+				WithField("ready_nodes", len(readyNodes)).
+				Warn("Upstreams did not reach quorum on finality, not updating head")
+
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return finality, nil
+}
+
+// trustedFinality returns the finality of the designated trusted upstream.
+func (d *Default) trustedFinality(ctx context.Context) (*v1.Finality, error) {
+	for _, node := range d.readyNodes(ctx) {
+		if node.Config.Name != d.config.TrustedNode {
+			continue
+		}
+
+		finality, err := d.nodeFinality(ctx, node)
+		if err != nil {
+			return nil, nil
+		}
+
+		if err := d.finalityAgeError(finality); err != nil {
+			d.log.WithError(err).WithField("node", node.Config.Name).Warn("Not using trusted upstream's finality")
+
+			return nil, nil
+		}
+
+		return finality, nil
+	}
+
+	return nil, fmt.Errorf("trusted upstream %s is not ready", d.config.TrustedNode)
+}
+
+// finalityAgeError returns ErrFinalityTooOld if the finalized epoch is more than MaxFinalityAge epochs behind the
+// wall clock epoch. The check is skipped until the genesis and spec have been fetched.
+func (d *Default) finalityAgeError(finality *v1.Finality) error {
+	if d.config.MaxFinalityAge <= 0 || finality == nil || finality.Finalized == nil {
+		return nil
+	}
+
+	d.genesisMu.Lock()
+	genesis := d.genesis
+	d.genesisMu.Unlock()
+
+	d.specMu.Lock()
+	sp := d.spec
+	d.specMu.Unlock()
+
+	if genesis == nil || sp == nil {
+		return nil
+	}
+
+	currentEpoch := eth.CalculateWallClockEpoch(time.Now(), genesis.GenesisTime, sp.SecondsPerSlot.AsDuration(), sp.SlotsPerEpoch)
+	if finality.Finalized.Epoch+phase0.Epoch(d.config.MaxFinalityAge) < currentEpoch {
+		return fmt.Errorf("%w: finalized epoch %d, wall clock epoch %d", ErrFinalityTooOld, finality.Finalized.Epoch, currentEpoch)
+	}
+
+	return nil
+}
+
+// nodeFinality requests the finality of the node's head. A node whose requests keep failing is backed off and not
+// requested again until its backoff has passed.
+func (d *Default) nodeFinality(ctx context.Context, node *Node) (*v1.Finality, error) {
+	if !node.FinalityBackoff.Ready(time.Now()) {
+		return nil, errors.New("node is backed off")
+	}
+
+	finality, err := node.FetchFinality(ctx)
+	if err != nil {
+		node.FinalityBackoff.Failure(time.Now())
+
+		// A failing node is requested again each time its backoff passes, so only log repeats of the same failure
+		// occasionally.
+		if ok, suppressed := node.FinalityLogSampler.Allow(time.Now(), err.Error()); ok {
+			d.log.
+				WithError(err).
+				WithField("node", node.Config.Name).
+				WithField("backoff", node.FinalityBackoff.Interval().String()).
+				WithField("suppressed", suppressed).
+				Info("Failed to get finality from node")
+		}
+
+		return nil, err
+	}
+
+	node.FinalityBackoff.Success()
+	node.FinalityLogSampler.Reset()
+
+	return finality, nil
+}
+
+func (d *Default) checkBeaconSpec(ctx context.Context) error {
+	d.specMu.Lock()
+	defer d.specMu.Unlock()
+
+	// No-Op if we already have a beacon spec
+	if d.spec != nil {
+		return nil
+	}
+
+	d.log.Debug("Fetching beacon spec")
+
+	upstream, err := d.dataProviders(ctx).RandomNode(ctx)
+	if err != nil {
+		return err
+	}
+
+	s, err := upstream.Beacon.Spec()
+	if err != nil {
+		return err
+	}
+
+	// store the beacon state spec
+	d.spec = applySpecOverrides(s, d.config.SpecOverrides)
+
+	d.log.Info("Fetched beacon spec")
+
+	if o := d.config.SpecOverrides; o.SlotsPerEpoch != 0 || o.SecondsPerSlot != 0 {
+		d.log.WithFields(logrus.Fields{
+			"slots_per_epoch":  d.spec.SlotsPerEpoch,
+			"seconds_per_slot": d.spec.SecondsPerSlot.AsDuration(),
+		}).Warn("Overriding the fetched beacon spec with values from config")
+	}
+
+	return nil
+}
+
+// slotsPerEpoch returns SLOTS_PER_EPOCH from the cached beacon spec, fetching the spec from a data provider if
+// we haven't seen it yet. Falls back to the configured override, or the mainnet value of 32, if the spec has never
+// been retrieved.
+func (d *Default) slotsPerEpoch(ctx context.Context) phase0.Slot {
+	if d.config.SpecOverrides.SlotsPerEpoch != 0 {
+		return phase0.Slot(d.config.SpecOverrides.SlotsPerEpoch)
+	}
+
+	sp, err := d.Spec(ctx)
+	if err != nil {
+		d.log.WithError(err).Warn("Failed to fetch beacon spec, falling back to 32 slots per epoch")
+
+		return phase0.Slot(32)
+	}
+
+	if sp.SlotsPerEpoch == 0 {
+		return phase0.Slot(32)
+	}
+
+	return sp.SlotsPerEpoch
+}
+
+// secondsPerSlot returns SECONDS_PER_SLOT from the beacon spec, falling back to the configured override, or the
+// mainnet value of 12 seconds, if the spec has never been retrieved.
+func (d *Default) secondsPerSlot(ctx context.Context) time.Duration {
+	if d.config.SpecOverrides.SecondsPerSlot != 0 {
+		return time.Duration(d.config.SpecOverrides.SecondsPerSlot) * time.Second
+	}
+
+	sp, err := d.Spec(ctx)
+	if err != nil || sp.SecondsPerSlot == 0 {
+		return 12 * time.Second
+	}
+
+	return sp.SecondsPerSlot.AsDuration()
+}
+
+func (d *Default) checkGenesisTime(ctx context.Context) error {
+	d.genesisMu.Lock()
+	defer d.genesisMu.Unlock()
+
+	// No-Op if we already have a genesis time
+	if d.genesis != nil {
+		return nil
+	}
+
+	d.log.Debug("Fetching genesis time")
+
+	upstream, err := d.dataProviders(ctx).RandomNode(ctx)
+	if err != nil {
+		return err
+	}
+
+	g, err := upstream.Beacon.Genesis()
+	if err != nil {
+		return err
+	}
+
+	// store the genesis time
+	d.genesis = applyGenesisOverrides(g, d.config.SpecOverrides)
+
+	if d.config.SpecOverrides.GenesisTime != 0 {
+		d.log.WithField("genesis_time", d.genesis.GenesisTime).Warn("Overriding the fetched genesis time with the value from config")
+	}
+
+	d.networkRootMu.Lock()
+	if d.networkRoot == nil {
+		root := g.GenesisValidatorsRoot
+		d.networkRoot = &root
+
+		d.log.WithField("genesis_validators_root", eth.RootAsString(root)).Info("Pinned upstream network")
+	}
+	d.networkRootMu.Unlock()
+
+	d.log.Info("Fetched genesis time")
+
+	return nil
+}
+
+// onExpectedNetwork filters out nodes that are not on the network we have pinned or that have a different
+// deposit contract to the one configured.
+func (d *Default) onExpectedNetwork(ctx context.Context, nodes Nodes) Nodes {
+	if d.config.ExpectedDepositChainID != 0 || d.config.ExpectedDepositContractAddress != "" {
+		nodes = nodes.WithDepositContract(ctx, d.config.ExpectedDepositChainID, d.config.ExpectedDepositContractAddress)
+	}
+
+	d.networkRootMu.RLock()
+	defer d.networkRootMu.RUnlock()
+
+	if d.networkRoot == nil {
+		return nodes
+	}
+
+	return nodes.OnNetwork(ctx, *d.networkRoot)
+}
+
+// readyNodes returns the nodes that the health strategy considers ready, are on the expected network and are not
+// lagging too far behind the finalized head.
+func (d *Default) readyNodes(ctx context.Context) Nodes {
+	nodes := d.onExpectedNetwork(ctx, d.healthStrategy.Ready(ctx, d.upstreams(), d.finalityHead()))
+
+	if d.config.MaxReadyFinalityLag == 0 {
+		return nodes
+	}
+
+	return nodes.Filter(ctx, func(node *Node) bool {
+		return d.staleError(node) == nil
+	})
+}
+
+// staleError returns an error if the node's finality is more than max_ready_finality_lag epochs behind the head.
+func (d *Default) staleError(node *Node) error {
+	if d.config.MaxReadyFinalityLag == 0 {
+		return nil
+	}
+
+	finality, err := node.Beacon.Finality()
+	if err != nil {
+		return nil
+	}
+
+	lag, ok := FinalityLag(d.finalityHead(), finality)
+	if !ok || lag <= phase0.Epoch(d.config.MaxReadyFinalityLag) {
+		return nil
+	}
+
+	return fmt.Errorf("upstream is stale: finality is %d epochs behind the head, more than the %d allowed", lag, d.config.MaxReadyFinalityLag)
+}
+
+// networkError returns an error if the node is on a different network to the one we have pinned.
+func (d *Default) networkError(node *Node) error {
+	if err := d.depositContractError(node); err != nil {
+		return err
+	}
+
+	d.networkRootMu.RLock()
+	defer d.networkRootMu.RUnlock()
+
+	if d.networkRoot == nil {
+		return nil
+	}
+
+	genesis, err := node.Beacon.Genesis()
+	if err != nil || genesis == nil {
+		return errors.New("genesis unknown, unable to verify upstream network")
+	}
+
+	if genesis.GenesisValidatorsRoot != *d.networkRoot {
+		return fmt.Errorf("upstream is on the wrong network: genesis validators root %s, expected %s", eth.RootAsString(genesis.GenesisValidatorsRoot), eth.RootAsString(*d.networkRoot))
+	}
+
+	return nil
+}
+
+// depositContractError returns an error if the node's deposit contract differs from the one configured.
+func (d *Default) depositContractError(node *Node) error {
+	chainID := d.config.ExpectedDepositChainID
+	address := d.config.ExpectedDepositContractAddress
+
+	if chainID == 0 && address == "" {
+		return nil
+	}
+
+	sp, err := node.Beacon.Spec()
+	if err != nil || sp == nil {
+		return errors.New("spec unknown, unable to verify upstream deposit contract")
+	}
+
+	if chainID != 0 && sp.DepositChainID != chainID {
+		return fmt.Errorf("upstream has the wrong deposit chain id: %d, expected %d", sp.DepositChainID, chainID)
+	}
+
+	if address != "" && !strings.EqualFold(sp.DepositContractAddress, address) {
+		return fmt.Errorf("upstream has the wrong deposit contract address: %s, expected %s", sp.DepositContractAddress, address)
+	}
 
 	return nil
 }
@@ -423,64 +1415,135 @@ func (d *Default) publishFinalityCheckpointHeadUpdated(ctx context.Context, chec
 }
 
 func (d *Default) GetBlockBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
+	block, err := d.getBlockBySlot(slot)
+
+	d.metrics.ObserveStoreLookup(storeBlock, lookupSlot, err)
+
+	return block, err
+}
+
+func (d *Default) getBlockBySlot(slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
+	// Don't serve a genesis block that slipped in to the cache (e.g. from persistence) when genesis is disabled.
+	if slot == phase0.Slot(0) && !d.config.FetchGenesis {
+		return nil, store.ErrBlockNotFound
+	}
+
+	if slot == phase0.Slot(0) && d.genesisUnavailable() {
+		return nil, ErrGenesisBundleUnavailable
+	}
+
 	block, err := d.blocks.GetBySlot(slot)
 	if err != nil {
 		return nil, err
 	}
 
 	if block == nil {
-		return nil, errors.New("block not found")
+		return nil, store.ErrBlockNotFound
 	}
 
 	return block, nil
 }
 
 func (d *Default) GetBlockByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	block, err := d.getBlockByRoot(root)
+
+	d.metrics.ObserveStoreLookup(storeBlock, lookupRoot, err)
+
+	return block, err
+}
+
+func (d *Default) getBlockByRoot(root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
 	block, err := d.blocks.GetByRoot(root)
 	if err != nil {
 		return nil, err
 	}
 
 	if block == nil {
-		return nil, errors.New("block not found")
+		return nil, store.ErrBlockNotFound
 	}
 
 	return block, nil
 }
 
-func (d *Default) GetBlockByStateRoot(ctx context.Context, stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
-	block, err := d.blocks.GetByStateRoot(stateRoot)
+func (d *Default) GetBlockSSZ(ctx context.Context, root phase0.Root) ([]byte, error) {
+	data, err := d.blocks.GetSSZ(root)
+
+	d.metrics.ObserveStoreLookup(storeBlock, lookupSSZ, err)
+
+	return data, err
+}
+
+// GetFinalizedBlock returns the block of the finalized checkpoint being served.
+func (d *Default) GetFinalizedBlock(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+	finality, err := d.Finalized(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if block == nil {
-		return nil, errors.New("block not found")
+	if finality == nil || finality.Finalized == nil {
+		return nil, ErrFinalityNotFound
 	}
 
-	return block, nil
+	return d.GetBlockByRoot(ctx, finality.Finalized.Root)
 }
 
-func (d *Default) GetBeaconStateBySlot(ctx context.Context, slot phase0.Slot) (*[]byte, error) {
-	block, err := d.GetBlockBySlot(ctx, slot)
+func (d *Default) GetBlockByParentRoot(ctx context.Context, parentRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	block, err := d.blocks.GetByParentRoot(parentRoot)
+	if err == nil && block == nil {
+		err = store.ErrBlockNotFound
+	}
+
+	d.metrics.ObserveStoreLookup(storeBlock, lookupParentRoot, err)
+
 	if err != nil {
 		return nil, err
 	}
 
-	stateRoot, err := block.StateRoot()
+	return block, nil
+}
+
+func (d *Default) GetBlockByStateRoot(ctx context.Context, stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	block, err := d.blocks.GetByStateRoot(stateRoot)
+	if err == nil && block == nil {
+		err = store.ErrBlockNotFound
+	}
+
+	d.metrics.ObserveStoreLookup(storeBlock, lookupStateRoot, err)
+
 	if err != nil {
 		return nil, err
 	}
 
-	return d.states.GetByStateRoot(stateRoot)
+	return block, nil
+}
+
+func (d *Default) GetBeaconStateBySlot(ctx context.Context, slot phase0.Slot) (*[]byte, error) {
+	state, err := d.getBeaconStateByBlock(d.getBlockBySlot(slot))
+
+	d.metrics.ObserveStoreLookup(storeState, lookupSlot, err)
+
+	return state, err
 }
 
 func (d *Default) GetBeaconStateByStateRoot(ctx context.Context, stateRoot phase0.Root) (*[]byte, error) {
-	return d.states.GetByStateRoot(stateRoot)
+	state, err := d.states.GetByStateRoot(stateRoot)
+
+	d.metrics.ObserveStoreLookup(storeState, lookupStateRoot, err)
+
+	return state, err
 }
 
 func (d *Default) GetBeaconStateByRoot(ctx context.Context, root phase0.Root) (*[]byte, error) {
-	block, err := d.GetBlockByRoot(ctx, root)
+	state, err := d.getBeaconStateByBlock(d.getBlockByRoot(root))
+
+	d.metrics.ObserveStoreLookup(storeState, lookupRoot, err)
+
+	return state, err
+}
+
+// getBeaconStateByBlock returns the state of a block found by one of the unmetered block lookups, so
+// that a state lookup isn't also counted as a block lookup.
+func (d *Default) getBeaconStateByBlock(block *spec.VersionedSignedBeaconBlock, err error) (*[]byte, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -494,12 +1557,12 @@ func (d *Default) GetBeaconStateByRoot(ctx context.Context, root phase0.Root) (*
 }
 
 func (d *Default) storeBlock(ctx context.Context, block *spec.VersionedSignedBeaconBlock) error {
-	if d.spec == nil {
-		return errors.New("beacon chain spec is unknown")
+	if _, err := d.Spec(ctx); err != nil {
+		return err
 	}
 
-	if d.genesis == nil {
-		return errors.New("genesis time is unknown")
+	if _, err := d.Genesis(ctx); err != nil {
+		return err
 	}
 
 	if block == nil {
@@ -521,10 +1584,9 @@ func (d *Default) storeBlock(ctx context.Context, block *spec.VersionedSignedBea
 		return err
 	}
 
-	expiresAt := time.Now().Add(FinalityHaltedServingPeriod)
-
-	if slot == phase0.Slot(0) {
-		expiresAt = time.Now().Add(999999 * time.Hour)
+	expiresAt, ok := d.blockExpiration(ctx, slot)
+	if !ok {
+		return fmt.Errorf("%w: slot %d expired at %s", ErrBlockExpired, slot, expiresAt)
 	}
 
 	if err := d.blocks.Add(block, expiresAt); err != nil {
@@ -534,10 +1596,23 @@ func (d *Default) storeBlock(ctx context.Context, block *spec.VersionedSignedBea
 	return nil
 }
 
+// blockExpiration returns when a block or state at the given slot should expire, and whether it should be stored at all.
+func (d *Default) blockExpiration(ctx context.Context, slot phase0.Slot) (time.Time, bool) {
+	now := time.Now()
+
+	slotTime, err := d.GetSlotTime(ctx, slot)
+	if err != nil {
+		// Without genesis or the spec we can't place the slot in time, so retain it from now.
+		return CalculateBlockExpiration(slot, now, d.config.BlockRetention, now)
+	}
+
+	return CalculateBlockExpiration(slot, slotTime.StartTime, d.config.BlockRetention, now)
+}
+
 func (d *Default) UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStatus, error) {
 	rsp := make(map[string]*UpstreamStatus)
 
-	for _, node := range d.nodes {
+	for _, node := range d.upstreams() {
 		rsp[node.Config.Name] = &UpstreamStatus{
 			Name:    node.Config.Name,
 			Healthy: false,
@@ -545,6 +1620,22 @@ func (d *Default) UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStat
 
 		rsp[node.Config.Name].Healthy = node.Beacon.Status().Healthy()
 
+		if err := d.networkError(node); err != nil {
+			rsp[node.Config.Name].Error = err.Error()
+		} else if err := d.staleError(node); err != nil {
+			rsp[node.Config.Name].Error = err.Error()
+		} else if err := d.forkMismatchError(node); err != nil {
+			rsp[node.Config.Name].Error = err.Error()
+		}
+
+		rsp[node.Config.Name].RateLimit = node.RateLimiter.Rate()
+		rsp[node.Config.Name].ActiveAddress = node.ActiveAddress()
+		rsp[node.Config.Name].ClientType = string(node.ClientType())
+
+		if backoff := node.FinalityBackoff.Interval(); backoff > 0 {
+			rsp[node.Config.Name].FinalityBackoff = backoff.String()
+		}
+
 		//nolint:gocritic // invalid
 		if spec, err := node.Beacon.Spec(); err == nil {
 			network := spec.ConfigName
@@ -566,15 +1657,33 @@ func (d *Default) UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStat
 		}
 
 		rsp[node.Config.Name].Finality = finality
+
+		if lag, ok := d.upstreamFinalityLag(node); ok {
+			rsp[node.Config.Name].FinalityLagEpochs = &lag
+		}
 	}
 
 	return rsp, nil
 }
 
+func (d *Default) CacheContents(ctx context.Context) *CacheContents {
+	return &CacheContents{
+		Blocks: d.blocks.List(),
+		States: d.states.List(),
+	}
+}
+
+// Checkpoints returns the finalized checkpoints whose block and state are both cached, newest first.
+func (d *Default) Checkpoints(ctx context.Context) []Checkpoint {
+	return DownloadableCheckpoints(d.blocks.List(), d.states.List(), d.slotsPerEpoch(ctx))
+}
+
 func (d *Default) ListFinalizedSlots(ctx context.Context) ([]phase0.Slot, error) {
 	slots := []phase0.Slot{}
-	if d.spec == nil {
-		return slots, errors.New("no beacon chain spec available")
+
+	sp, err := d.Spec(ctx)
+	if err != nil {
+		return slots, err
 	}
 
 	finality, err := d.Head(ctx)
@@ -586,9 +1695,9 @@ func (d *Default) ListFinalizedSlots(ctx context.Context) ([]phase0.Slot, error)
 		return slots, errors.New("no finalized checkpoint available")
 	}
 
-	latestSlot := phase0.Slot(uint64(finality.Finalized.Epoch) * uint64(d.spec.SlotsPerEpoch))
+	latestSlot := phase0.Slot(uint64(finality.Finalized.Epoch) * uint64(sp.SlotsPerEpoch))
 
-	for i, val := uint64(latestSlot), uint64(latestSlot)-uint64(d.spec.SlotsPerEpoch)*uint64(d.config.HistoricalEpochCount); i > val; i -= uint64(d.spec.SlotsPerEpoch) {
+	for i, val := uint64(latestSlot), uint64(latestSlot)-uint64(sp.SlotsPerEpoch)*uint64(d.config.HistoricalEpochCount); i > val; i -= uint64(sp.SlotsPerEpoch) {
 		slots = append(slots, phase0.Slot(i))
 	}
 
@@ -596,29 +1705,95 @@ func (d *Default) ListFinalizedSlots(ctx context.Context) ([]phase0.Slot, error)
 }
 
 func (d *Default) GetEpochBySlot(ctx context.Context, slot phase0.Slot) (phase0.Epoch, error) {
-	if d.spec == nil {
-		return phase0.Epoch(0), errors.New("no upstream beacon state spec available")
+	sp, err := d.Spec(ctx)
+	if err != nil {
+		return phase0.Epoch(0), err
+	}
+
+	return phase0.Epoch(uint64(slot) / uint64(sp.SlotsPerEpoch)), nil
+}
+
+func (d *Default) GetFinalityByEpoch(ctx context.Context, epoch phase0.Epoch) (*v1.Finality, error) {
+	// The serving bundle carries the full finality (including justified checkpoints) so prefer it.
+	if serving := d.serving(); serving != nil && serving.Finalized != nil && serving.Finalized.Epoch == epoch {
+		return serving, nil
+	}
+
+	block, err := d.checkpointBlock(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return nil, err
 	}
 
-	return phase0.Epoch(uint64(slot) / uint64(d.spec.SlotsPerEpoch)), nil
+	return &v1.Finality{
+		Finalized: &phase0.Checkpoint{
+			Epoch: epoch,
+			Root:  root,
+		},
+	}, nil
+}
+
+// checkpointBlock returns the cached checkpoint block of the epoch: the block at the epoch's first slot, or if that
+// slot was missed, the latest block before it. Bundles are only downloaded for finalized checkpoints, so a block in
+// the previous epoch whose state is cached is known to be the latest before the first slot. Other blocks there may
+// be from a fork, and are ignored.
+func (d *Default) checkpointBlock(ctx context.Context, epoch phase0.Epoch) (*spec.VersionedSignedBeaconBlock, error) {
+	sp, err := d.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	slot := phase0.Slot(uint64(epoch) * uint64(sp.SlotsPerEpoch))
+
+	if block, err := d.blocks.GetBySlot(slot); err == nil && block != nil {
+		return block, nil
+	}
+
+	for earlier := slot; earlier > 0 && slot-earlier < sp.SlotsPerEpoch-1; {
+		earlier--
+
+		block, err := d.blocks.GetBySlot(earlier)
+		if err != nil || block == nil {
+			continue
+		}
+
+		stateRoot, err := block.StateRoot()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := d.states.GetByStateRoot(stateRoot); err != nil {
+			continue
+		}
+
+		return block, nil
+	}
+
+	return nil, fmt.Errorf("%w: epoch %d", ErrFinalityNotFound, epoch)
 }
 
 func (d *Default) PeerCount(ctx context.Context) (uint64, error) {
-	return uint64(len(d.nodes.Healthy(ctx).NotSyncing(ctx))), nil
+	return uint64(len(d.readyNodes(ctx))), nil
 }
 
 func (d *Default) GetSlotTime(ctx context.Context, slot phase0.Slot) (eth.SlotTime, error) {
 	SlotTime := eth.SlotTime{}
 
-	if d.spec == nil {
-		return SlotTime, errors.New("no upstream beacon state spec available")
+	sp, err := d.Spec(ctx)
+	if err != nil {
+		return SlotTime, err
 	}
 
-	if d.genesis == nil {
-		return SlotTime, errors.New("genesis time is unknown")
+	genesis, err := d.Genesis(ctx)
+	if err != nil {
+		return SlotTime, err
 	}
 
-	return eth.CalculateSlotTime(slot, d.genesis.GenesisTime, d.spec.SecondsPerSlot.AsDuration()), nil
+	return eth.CalculateSlotTime(slot, genesis.GenesisTime, sp.SecondsPerSlot.AsDuration()), nil
 }
 
 func (d *Default) GetDepositSnapshot(ctx context.Context, epoch phase0.Epoch) (*types.DepositSnapshot, error) {
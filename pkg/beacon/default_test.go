@@ -0,0 +1,1169 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestServesCachedDataWhenAllUpstreamsAreDown(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_degraded")
+
+	// Populate the stores while the upstream is healthy.
+	healthy := newHealthyStatus()
+
+	upstream := &fakeUpstream{block: block, state: data, status: healthy}
+
+	n := newTestNode("a", upstream)
+	n.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+	n.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+
+	d.nodes = Nodes{n}
+
+	if _, err := d.fetchBundleWithFallback(ctx, root, d.nodes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ok, _ := d.Healthy(ctx); !ok {
+		t.Fatal("expected the provider to be healthy while its upstream is")
+	}
+
+	// Every upstream goes down.
+	upstream.status.Health().RecordFail(nil)
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("expected finality checks to keep running without upstreams, got %v", err)
+	}
+
+	if ok, _ := d.Healthy(ctx); ok {
+		t.Error("expected the provider to be unhealthy with no healthy upstreams")
+	}
+
+	if _, err := d.GetBlockBySlot(ctx, slot); err != nil {
+		t.Errorf("expected the cached block to be served by slot, got %v", err)
+	}
+
+	if _, err := d.GetBlockByRoot(ctx, root); err != nil {
+		t.Errorf("expected the cached block to be served by root, got %v", err)
+	}
+
+	for name, get := range map[string]func() (*[]byte, error){
+		"slot":       func() (*[]byte, error) { return d.GetBeaconStateBySlot(ctx, slot) },
+		"root":       func() (*[]byte, error) { return d.GetBeaconStateByRoot(ctx, root) },
+		"state root": func() (*[]byte, error) { return d.GetBeaconStateByStateRoot(ctx, stateRoot) },
+	} {
+		cached, err := get()
+		if err != nil {
+			t.Errorf("expected the cached state to be served by %s, got %v", name, err)
+
+			continue
+		}
+
+		if !bytes.Equal(*cached, data) {
+			t.Errorf("expected the cached state to be served by %s unchanged", name)
+		}
+	}
+}
+
+func TestRecheckFinalityReturnsTheNewHead(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_recheck")
+
+	d.nodes = Nodes{
+		newHealthyTestNode("a", finalizedAt(100, 0x01)),
+		newHealthyTestNode("b", finalizedAt(100, 0x01)),
+	}
+
+	for _, upstream := range d.nodes {
+		upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+		upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+	}
+
+	head, err := d.RecheckFinality(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if head == nil || head.Finalized.Epoch != 100 || head.Finalized.Root != finalizedAt(100, 0x01).Finalized.Root {
+		t.Errorf("expected the head agreed on by the upstreams, got %v", head)
+	}
+}
+
+func TestMinReadyNodes(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_min_ready_nodes")
+	d.config.MinReadyNodes = 2
+
+	a := newHealthyTestNode("a", finalizedAt(100, 0x01))
+	b := newHealthyTestNode("b", finalizedAt(100, 0x01))
+
+	d.nodes = Nodes{a, b}
+
+	for _, upstream := range d.nodes {
+		upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+		upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+	}
+
+	b.Beacon.Status().Health().RecordFail(nil)
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.head != nil {
+		t.Errorf("expected the head to not be decided by a single ready upstream, got %v", d.head)
+	}
+
+	if ok, _ := d.Healthy(ctx); ok {
+		t.Error("expected the provider to be unhealthy with fewer than min_ready_nodes ready")
+	}
+
+	b.Beacon.Status().Health().RecordSuccess()
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.head == nil || d.head.Finalized.Epoch != 100 {
+		t.Errorf("expected the head to be updated once enough upstreams are ready, got %v", d.head)
+	}
+}
+
+func TestMaxReadyFinalityLag(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_max_ready_finality_lag")
+	d.config.MaxReadyFinalityLag = 2
+	d.head = finalizedAt(100, 0x01)
+
+	current := newHealthyTestNode("current", finalizedAt(100, 0x01))
+	stale := newHealthyTestNode("stale", finalizedAt(97, 0x02))
+
+	d.nodes = Nodes{current, stale}
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 1 || names[0] != "current" {
+		t.Errorf("expected only the current upstream to be ready, got %v", names)
+	}
+
+	if err := d.staleError(stale); err == nil {
+		t.Error("expected an error saying why the stale upstream isn't ready")
+	}
+
+	if err := d.staleError(current); err != nil {
+		t.Errorf("expected no error for the current upstream, got %v", err)
+	}
+
+	stale.Beacon.(*fakeUpstream).finality = finalizedAt(98, 0x01)
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 2 {
+		t.Errorf("expected the upstream to be ready again once it caught up, got %v", names)
+	}
+}
+
+func TestMaxFinalityAge(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_max_finality_age")
+	d.config.MaxFinalityAge = 50
+	// The wall clock is at epoch 200.
+	d.genesis.GenesisTime = time.Now().Add(-200 * 32 * 12 * time.Second)
+
+	stuck := []*Node{
+		newHealthyTestNode("a", finalizedAt(100, 0x01)),
+		newHealthyTestNode("b", finalizedAt(100, 0x01)),
+	}
+
+	d.nodes = Nodes{stuck[0], stuck[1], newHealthyTestNode("c", finalizedAt(190, 0x02))}
+
+	for _, upstream := range d.nodes {
+		upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+		upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+	}
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.head == nil || d.head.Finalized.Epoch != 190 {
+		t.Errorf("expected the stuck upstreams to be left out of the vote, got %v", d.head)
+	}
+
+	if err := d.finalityAgeError(finalizedAt(100, 0x01)); !errors.Is(err, ErrFinalityTooOld) {
+		t.Errorf("expected %v, got %v", ErrFinalityTooOld, err)
+	}
+
+	d.nodes = Nodes{stuck[0], stuck[1]}
+	d.head = nil
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.head != nil {
+		t.Errorf("expected no head when every upstream is stuck, got %v", d.head)
+	}
+
+	d.config.MaxFinalityAge = 0
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.head == nil || d.head.Finalized.Epoch != 100 {
+		t.Errorf("expected the check to be disabled by a max_finality_age of 0, got %v", d.head)
+	}
+}
+
+func TestSyncingReflectsTheServedCheckpoint(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_syncing")
+	// The wall clock is at slot 100.
+	d.genesis.GenesisTime = time.Now().Add(-100*12*time.Second - 6*time.Second)
+	d.nodes = Nodes{newHealthyTestNode("a", finalizedAt(2, 0x01))}
+
+	syncing, err := d.Syncing(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !syncing.IsSyncing {
+		t.Error("expected to be syncing before a checkpoint is served")
+	}
+
+	d.servingBundle = finalizedAt(2, 0x01)
+
+	syncing, err = d.Syncing(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if syncing.IsSyncing {
+		t.Error("expected to not be syncing once a checkpoint is served")
+	}
+
+	if syncing.HeadSlot != 64 {
+		t.Errorf("expected the head slot to be the served checkpoint's slot, got %d", syncing.HeadSlot)
+	}
+
+	if syncing.SyncDistance != 36 {
+		t.Errorf("expected the sync distance to the wall clock slot to be 36, got %d", syncing.SyncDistance)
+	}
+}
+
+func TestGenesisGivenUpOn(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_genesis_given_up")
+	d.config.FetchGenesis = true
+
+	d.giveUpOnGenesis()
+
+	_, err := d.GetBlockBySlot(ctx, 0)
+	if !errors.Is(err, ErrGenesisBundleUnavailable) {
+		t.Fatalf("expected %v, got %v", ErrGenesisBundleUnavailable, err)
+	}
+
+	if !errors.Is(err, store.ErrBlockNotFound) {
+		t.Error("expected the genesis block to be treated as not found")
+	}
+
+	if _, err := d.GetBeaconStateBySlot(ctx, 0); !errors.Is(err, store.ErrStateNotFound) {
+		t.Errorf("expected the genesis state to be treated as not found, got %v", err)
+	}
+}
+
+func TestServingBundleStatesSurviveEvictionPressure(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_pinning")
+	d.config.ServedBundleHistory = 1
+	d.states = store.NewBeaconState(d.log, store.Config{MaxItems: 3}, "test_pinning_small")
+
+	now := time.Now()
+	genesisRoot := phase0.Root{0xaa}
+
+	if err := d.states.Add(genesisRoot, &data, now.Add(time.Minute), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.blocks.Add(block, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The serving state expires first, so it would be the first evicted if it wasn't pinned.
+	if err := d.states.Add(stateRoot, &data, now.Add(time.Minute), 64); err != nil {
+		t.Fatal(err)
+	}
+
+	serving := finalizedAt(2, 0)
+	serving.Finalized.Root = root
+
+	d.serveBundle(serving)
+
+	for i := 1; i <= 5; i++ {
+		if err := d.states.Add(phase0.Root{byte(i)}, &data, now.Add(time.Hour*time.Duration(i)), phase0.Slot(100+i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := d.states.GetByStateRoot(genesisRoot); err != nil {
+		t.Errorf("expected the genesis state to survive eviction, got %v", err)
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); err != nil {
+		t.Fatalf("expected the serving state to survive eviction, got %v", err)
+	}
+
+	// Once superseded and dropped from the served history, the state can be evicted again.
+	d.serveBundle(finalizedAt(3, 0xbb))
+
+	if err := d.states.Add(phase0.Root{0xcc}, &data, now.Add(time.Hour*10), 200); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); err == nil {
+		t.Error("expected the superseded state to have been evicted")
+	}
+}
+
+func TestGetFinalizedBlock(t *testing.T) {
+	ctx := context.Background()
+
+	block := newTestPhase0Block(phase0.Root{0x01})
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_finalized_block")
+
+	if _, err := d.GetFinalizedBlock(ctx); !errors.Is(err, ErrFinalityNotFound) {
+		t.Fatalf("expected %v before anything is served, got %v", ErrFinalityNotFound, err)
+	}
+
+	serving := finalizedAt(2, 0)
+	serving.Finalized.Root = root
+
+	d.serveBundle(serving)
+
+	if _, err := d.GetFinalizedBlock(ctx); !errors.Is(err, store.ErrBlockNotFound) {
+		t.Fatalf("expected %v before the block is cached, got %v", store.ErrBlockNotFound, err)
+	}
+
+	if err := d.blocks.Add(block, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	finalized, err := d.GetFinalizedBlock(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if finalizedRoot, _ := finalized.Root(); finalizedRoot != root {
+		t.Errorf("expected block %#x, got %#x", root, finalizedRoot)
+	}
+}
+
+func TestSlotsPerEpoch(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     *state.Spec
+		expected phase0.Slot
+	}{
+		{name: "from the spec", spec: &state.Spec{SlotsPerEpoch: 8}, expected: 8},
+		{name: "spec without slots per epoch", spec: &state.Spec{}, expected: 32},
+		{name: "spec can't be fetched", spec: nil, expected: 32},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &Default{log: logrus.New(), config: &Config{}, spec: test.spec, healthStrategy: NewHealthStrategy(HealthStrategyLoose)}
+
+			if got := d.slotsPerEpoch(context.Background()); got != test.expected {
+				t.Errorf("slotsPerEpoch() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestGetFinalityByEpoch(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_finality_by_epoch")
+
+	roots := map[phase0.Slot]phase0.Root{}
+
+	add := func(slot phase0.Slot, bundle bool) {
+		stateRoot := phase0.Root{byte(slot)}
+		block := beacontest.Phase0Block(slot, stateRoot)
+
+		root, err := block.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		roots[slot] = root
+
+		if err := d.blocks.Add(block, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bundle {
+			return
+		}
+
+		state := []byte{byte(slot)}
+
+		if err := d.states.Add(stateRoot, &state, time.Now().Add(time.Hour), slot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Epoch 3's first slot has a block, the first slots of epochs 4, 5 and 7 were missed.
+	add(96, false)
+	add(126, true)
+	add(158, false)
+	add(192, true)
+
+	tests := []struct {
+		name  string
+		epoch phase0.Epoch
+		slot  phase0.Slot
+	}{
+		{name: "block at the first slot", epoch: 3, slot: 96},
+		{name: "missed first slot with an earlier bundle", epoch: 4, slot: 126},
+		{name: "missed first slot with an earlier block that may be from a fork", epoch: 5},
+		{name: "block at the first slot with a bundle", epoch: 6, slot: 192},
+		{name: "missed first slot with a bundle from an earlier epoch", epoch: 7},
+		{name: "nothing cached", epoch: 8},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			finality, err := d.GetFinalityByEpoch(ctx, test.epoch)
+			if test.slot == 0 {
+				if !errors.Is(err, ErrFinalityNotFound) {
+					t.Errorf("expected %v, got %v", ErrFinalityNotFound, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if finality.Finalized.Epoch != test.epoch || finality.Finalized.Root != roots[test.slot] {
+				t.Errorf("expected epoch %d's checkpoint to be the block at slot %d, got %v", test.epoch, test.slot, finality.Finalized)
+			}
+		})
+	}
+}
+
+func TestVerifyPersistedState(t *testing.T) {
+	_, data := newTestAltairStateSSZ(t)
+
+	stateRoot, err := computeStateRoot(spec.DataVersionAltair, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_verify_persisted_state")
+
+	if err := d.blocks.Add(newTestAltairBlock(stateRoot), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.verifyPersistedState(stateRoot, data); err != nil {
+		t.Errorf("expected the state to be verified, got %v", err)
+	}
+
+	if err := d.verifyPersistedState(stateRoot, data[:len(data)-1]); err == nil {
+		t.Error("expected a truncated state to be rejected")
+	}
+
+	if err := d.verifyPersistedState(phase0.Root{0x01}, data); !errors.Is(err, store.ErrBlockNotFound) {
+		t.Errorf("expected a state without its block to be rejected, got %v", err)
+	}
+}
+
+func TestFailingUpstreamsAreNotPolledForFinalityWhileBackedOff(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_finality_backoff")
+
+	up := &fakeUpstream{status: newHealthyStatus(), finality: finalizedAt(100, 0x01)}
+	down := &fakeUpstream{status: newHealthyStatus()}
+
+	d.nodes = Nodes{newTestNode("up", up), newTestNode("down", down)}
+
+	for _, upstream := range d.nodes {
+		upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+		upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := d.checkFinality(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if fetches := atomic.LoadInt32(&up.finalityFetches); fetches != 3 {
+		t.Errorf("expected the healthy upstream to be polled on every check, got %d requests", fetches)
+	}
+
+	if fetches := atomic.LoadInt32(&down.finalityFetches); fetches != 1 {
+		t.Errorf("expected the failing upstream to not be polled while backed off, got %d requests", fetches)
+	}
+}
+
+func TestRepeatedFinalityFailuresAreSampled(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_finality_failure_sampling")
+
+	log, hook := logtest.NewNullLogger()
+	d.log = log
+
+	upstream := &fakeUpstream{status: newHealthyStatus()}
+
+	n := newTestNode("a", upstream)
+	n.FinalityBackoff = node.NewBackoff(time.Nanosecond, time.Nanosecond)
+	n.FinalityLogSampler = node.NewSampler(time.Hour)
+
+	poll := func() {
+		time.Sleep(time.Millisecond)
+
+		_, _ = d.nodeFinality(ctx, n)
+	}
+
+	for i := 0; i < 3; i++ {
+		poll()
+	}
+
+	if fetches := atomic.LoadInt32(&upstream.finalityFetches); fetches != 3 {
+		t.Fatalf("expected the upstream to be requested on every poll, got %d requests", fetches)
+	}
+
+	if entries := len(hook.AllEntries()); entries != 1 {
+		t.Errorf("expected the repeated failure to be logged once, got %d log lines", entries)
+	}
+
+	// Recovering resets the sampler, so the next failure is logged straight away.
+	upstream.finality = finalizedAt(100, 0x01)
+	poll()
+
+	upstream.finality = nil
+	poll()
+
+	if entries := len(hook.AllEntries()); entries != 2 {
+		t.Errorf("expected a failure after recovering to be logged, got %d log lines", entries)
+	}
+}
+
+func TestFinalityStalled(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_finality_stalled")
+	d.config.MaxFinalityStallEpochs = 2
+	d.servingBundle = finalizedAt(100, 0x01)
+
+	d.nodes = Nodes{
+		newHealthyTestNode("a", finalizedAt(100, 0x01)),
+		newHealthyTestNode("b", finalizedAt(100, 0x01)),
+		newHealthyTestNode("c", finalizedAt(100, 0x01)),
+	}
+
+	for _, upstream := range d.nodes {
+		upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+		upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+	}
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if finalized, err := d.Finalized(ctx); err != nil || finalized == nil {
+		t.Fatalf("expected the checkpoint to be served while upstreams agree, got %v", err)
+	}
+
+	// The upstreams stop agreeing, and have not agreed for longer than max_finality_stall_epochs.
+	for i, upstream := range d.nodes {
+		upstream.Beacon.(*fakeUpstream).finality = finalizedAt(101, byte(0x02+i))
+	}
+
+	d.lastQuorumAt = time.Now().Add(-3 * 32 * 12 * time.Second)
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := d.Finalized(ctx); !errors.Is(err, ErrFinalityStalled) {
+		t.Errorf("expected %v, got %v", ErrFinalityStalled, err)
+	}
+
+	d.config.MaxFinalityStallEpochs = 3
+
+	if _, err := d.Finalized(ctx); err != nil {
+		t.Errorf("expected the checkpoint to be served within max_finality_stall_epochs, got %v", err)
+	}
+
+	d.config.MaxFinalityStallEpochs = 2
+
+	// Agreeing again resumes serving.
+	for _, upstream := range d.nodes {
+		upstream.Beacon.(*fakeUpstream).finality = finalizedAt(101, 0x02)
+	}
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := d.Finalized(ctx); err != nil {
+		t.Errorf("expected the checkpoint to be served once upstreams agree again, got %v", err)
+	}
+}
+
+func TestSlotLookupsFetchTheSpecWhenUnknown(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_slot_lookups_spec")
+	d.spec = nil
+
+	if _, err := d.GetEpochBySlot(ctx, 64); !errors.Is(err, ErrSpecNotAvailable) {
+		t.Errorf("expected %v without an upstream to fetch the spec from, got %v", ErrSpecNotAvailable, err)
+	}
+
+	if _, err := d.GetSlotTime(ctx, 64); !errors.Is(err, ErrSpecNotAvailable) {
+		t.Errorf("expected %v without an upstream to fetch the spec from, got %v", ErrSpecNotAvailable, err)
+	}
+
+	upstream := newHealthyTestNode("a", finalizedAt(2, 0x01))
+	upstream.Beacon.(*fakeUpstream).spec = &state.Spec{SlotsPerEpoch: 8, SecondsPerSlot: state.StringerDuration(12 * time.Second)}
+
+	d.nodes = Nodes{upstream}
+
+	epoch, err := d.GetEpochBySlot(ctx, 64)
+	if err != nil {
+		t.Fatalf("expected the spec to be fetched from the upstream, got %v", err)
+	}
+
+	if epoch != 8 {
+		t.Errorf("expected slot 64 to be in epoch 8 with the upstream's spec, got %d", epoch)
+	}
+
+	slotTime, err := d.GetSlotTime(ctx, 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if expected := d.genesis.GenesisTime.Add(64 * 12 * time.Second); !slotTime.StartTime.Equal(expected) {
+		t.Errorf("expected slot 64 to start at %v, got %v", expected, slotTime.StartTime)
+	}
+}
+
+func TestUpstreamsOnAnotherNetworkAreExcluded(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_network_root")
+	d.genesis = nil
+
+	onNetwork := func(name string, genesisValidatorsRoot byte) *Node {
+		n := newHealthyTestNode(name, finalizedAt(2, 0x01))
+		n.Beacon.(*fakeUpstream).genesis = &v1.Genesis{
+			GenesisTime:           time.Now().Add(-time.Hour),
+			GenesisValidatorsRoot: phase0.Root{genesisValidatorsRoot},
+		}
+
+		return n
+	}
+
+	// The network of the first upstream genesis is fetched from is pinned.
+	d.nodes = Nodes{onNetwork("a", 0x01)}
+
+	if _, err := d.Genesis(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	unknown := newHealthyTestNode("unknown", finalizedAt(2, 0x01))
+	other := onNetwork("other", 0x02)
+
+	d.nodes = Nodes{d.nodes[0], other, unknown}
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 1 || names[0] != "a" {
+		t.Errorf("expected only the upstream on the pinned network to be ready, got %v", names)
+	}
+
+	if err := d.networkError(other); err == nil {
+		t.Error("expected an error saying the upstream is on the wrong network")
+	}
+
+	if err := d.networkError(unknown); err == nil {
+		t.Error("expected an error saying the upstream's network can't be verified")
+	}
+
+	// A configured network takes precedence over the upstreams'.
+	d = newTestDownloadProvider("test_network_root_configured")
+	d.networkRoot = &phase0.Root{0x02}
+	d.nodes = Nodes{onNetwork("a", 0x01), other}
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 1 || names[0] != "other" {
+		t.Errorf("expected only the upstream on the configured network to be ready, got %v", names)
+	}
+}
+
+func TestSingleTrustedFinalityMode(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_single_trusted")
+	d.config.FinalityMode = FinalityModeSingleTrusted
+	d.config.TrustedNode = "trusted"
+
+	trusted := newHealthyTestNode("trusted", finalizedAt(100, 0x01))
+
+	// The other upstreams agree with each other, and outvote the trusted upstream in majority mode.
+	d.nodes = Nodes{
+		trusted,
+		newHealthyTestNode("b", finalizedAt(101, 0x02)),
+		newHealthyTestNode("c", finalizedAt(101, 0x02)),
+	}
+
+	for _, upstream := range d.nodes {
+		upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+		upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+	}
+
+	if err := d.checkFinality(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.head == nil || d.head.Finalized.Root != (phase0.Root{0x01}) {
+		t.Fatalf("expected the head to follow the trusted upstream, got %v", d.head)
+	}
+
+	// The head isn't decided by the others while the trusted upstream is down.
+	trusted.Beacon.Status().Health().RecordFail(nil)
+
+	if err := d.checkFinality(ctx); err == nil {
+		t.Error("expected an error saying the trusted upstream is not ready")
+	}
+
+	if d.head.Finalized.Root != (phase0.Root{0x01}) {
+		t.Errorf("expected the head to be unchanged while the trusted upstream is down, got %v", d.head)
+	}
+}
+
+func TestServingCheckpointUpdatedAt(t *testing.T) {
+	d := newTestDownloadProvider("test_serving_checkpoint_updated_at")
+
+	if !d.serveBundle(finalizedAt(2, 0x01)) {
+		t.Fatal("expected the bundle to be served")
+	}
+
+	if updatedAt := gaugeValue(t, d.metrics.servingCheckpointUpdatedAt); time.Since(time.Unix(int64(updatedAt), 0)) > time.Minute {
+		t.Fatalf("expected the update to be recorded, got %v", updatedAt)
+	}
+
+	d.metrics.ObserveServingCheckpointUpdated(time.Unix(1, 0))
+
+	// Serving the same checkpoint again, as happens every finality check, isn't a change.
+	d.serveBundle(finalizedAt(2, 0x01))
+
+	if updatedAt := gaugeValue(t, d.metrics.servingCheckpointUpdatedAt); updatedAt != 1 {
+		t.Errorf("expected serving the same checkpoint to not be recorded as a change, got %v", updatedAt)
+	}
+
+	d.serveBundle(finalizedAt(3, 0x02))
+
+	if updatedAt := gaugeValue(t, d.metrics.servingCheckpointUpdatedAt); updatedAt == 1 {
+		t.Error("expected serving a new checkpoint to be recorded as a change")
+	}
+}
+
+func TestReadyRequiresTheServingBundleToBeCached(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_ready")
+
+	if ready, _ := d.Ready(ctx); ready {
+		t.Fatal("expected to not be ready without a serving checkpoint")
+	}
+
+	checkpoint := finalizedAt(2, 0)
+	checkpoint.Finalized.Root = root
+
+	d.serveBundle(checkpoint)
+
+	if ready, _ := d.Ready(ctx); ready {
+		t.Fatal("expected to not be ready without the serving block")
+	}
+
+	if err := d.blocks.Add(block, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if ready, _ := d.Ready(ctx); ready {
+		t.Fatal("expected to not be ready without the serving state")
+	}
+
+	d.config.Mode = OperatingModeLight
+
+	if ready, _ := d.Ready(ctx); !ready {
+		t.Fatal("expected to be ready with the serving block in light mode")
+	}
+
+	d.config.Mode = OperatingModeFull
+
+	if err := d.states.Add(stateRoot, &data, time.Now().Add(time.Hour), 64); err != nil {
+		t.Fatal(err)
+	}
+
+	if ready, err := d.Ready(ctx); !ready {
+		t.Errorf("expected to be ready with the serving bundle cached, got %v", err)
+	}
+}
+
+func TestUpstreamsWithAnotherDepositContractAreExcluded(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_deposit_contract")
+	d.config.ExpectedDepositChainID = 1
+	d.config.ExpectedDepositContractAddress = "0x00000000219ab540356cBB839Cbe05303d7705Fa"
+
+	withDepositContract := func(name string, chainID uint64, address string) *Node {
+		n := newHealthyTestNode(name, finalizedAt(2, 0x01))
+		n.Beacon.(*fakeUpstream).spec = &state.Spec{DepositChainID: chainID, DepositContractAddress: address}
+
+		return n
+	}
+
+	d.nodes = Nodes{
+		// Addresses are compared case insensitively, as clients differ in how they checksum them.
+		withDepositContract("mainnet", 1, "0x00000000219ab540356cbb839cbe05303d7705fa"),
+		withDepositContract("other chain", 5, "0x00000000219ab540356cbb839cbe05303d7705fa"),
+		withDepositContract("other contract", 1, "0xff50ed3d0ec03ac01d4c79aad74928bff48a7b2b"),
+		newHealthyTestNode("unknown", finalizedAt(2, 0x01)),
+	}
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 1 || names[0] != "mainnet" {
+		t.Errorf("expected only the upstream with the configured deposit contract to be ready, got %v", names)
+	}
+
+	for _, upstream := range d.nodes[1:] {
+		if err := d.networkError(upstream); err == nil {
+			t.Errorf("expected an error saying why %s isn't used", upstream.Config.Name)
+		}
+	}
+
+	d.config.ExpectedDepositChainID = 0
+	d.config.ExpectedDepositContractAddress = ""
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 4 {
+		t.Errorf("expected every upstream to be ready without a configured deposit contract, got %v", names)
+	}
+}
+
+func TestStartupDelay(t *testing.T) {
+	for _, jitter := range []time.Duration{0, -time.Second} {
+		if delay := startupDelay(jitter); delay != 0 {
+			t.Errorf("expected no delay for a jitter of %s, got %s", jitter, delay)
+		}
+	}
+
+	jitter := 100 * time.Millisecond
+	delays := map[time.Duration]struct{}{}
+
+	for i := 0; i < 100; i++ {
+		delay := startupDelay(jitter)
+		if delay < 0 || delay >= jitter {
+			t.Fatalf("expected a delay between 0 and %s, got %s", jitter, delay)
+		}
+
+		delays[delay] = struct{}{}
+	}
+
+	if len(delays) == 1 {
+		t.Error("expected the delay to be random")
+	}
+}
+
+func TestServedBundleHistory(t *testing.T) {
+	d := newTestDownloadProvider("test_served_bundle_history")
+	d.config.ServedBundleHistory = 2
+
+	for epoch := phase0.Epoch(1); epoch <= 3; epoch++ {
+		d.serveBundle(finalizedAt(epoch, byte(epoch)))
+	}
+
+	// Serving the same bundle again, as happens every finality check, doesn't push older bundles out.
+	d.serveBundle(finalizedAt(3, 0x03))
+
+	served := d.ServedBundles(context.Background())
+	if len(served) != 2 {
+		t.Fatalf("expected %d bundles to be remembered, got %d", 2, len(served))
+	}
+
+	for i, epoch := range []phase0.Epoch{3, 2} {
+		if served[i].Finalized.Epoch != epoch {
+			t.Errorf("expected the bundle at epoch %d to be remembered at position %d, got %d", epoch, i, served[i].Finalized.Epoch)
+		}
+	}
+}
+
+func TestServingSince(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_serving_since")
+
+	if since := d.ServingSince(ctx); !since.IsZero() {
+		t.Fatalf("expected no serving time before anything is served, got %v", since)
+	}
+
+	d.serveBundle(finalizedAt(2, 0x01))
+
+	first := d.ServingSince(ctx)
+	if first.IsZero() {
+		t.Fatal("expected the serving time to be recorded")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Serving the same bundle again, as happens every finality check, keeps when it was first served.
+	d.serveBundle(finalizedAt(2, 0x01))
+
+	if since := d.ServingSince(ctx); !since.Equal(first) {
+		t.Errorf("expected the serving time to be unchanged, got %v", since)
+	}
+
+	d.serveBundle(finalizedAt(3, 0x02))
+
+	if since := d.ServingSince(ctx); !since.After(first) {
+		t.Errorf("expected a new serving time for a new bundle, got %v", since)
+	}
+}
+
+func TestGenesisFetchingDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	blocks := map[string]*spec.VersionedSignedBeaconBlock{}
+	for _, slot := range []phase0.Slot{0, 32} {
+		blocks[eth.SlotAsString(slot)] = beacontest.Phase0Block(slot, phase0.Root{byte(slot)})
+	}
+
+	tests := []struct {
+		name         string
+		fetchGenesis bool
+	}{
+		{name: "enabled", fetchGenesis: true},
+		{name: "disabled", fetchGenesis: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := newTestDownloadProvider("test_fetch_genesis_" + test.name)
+			d.config.FetchGenesis = test.fetchGenesis
+			d.config.HistoricalEpochCount = 2
+			d.nodes = Nodes{newTestNode("a", &fakeUpstream{status: newHealthyStatus(), finality: finalizedAt(2, 0x01), blocks: blocks})}
+
+			if err := d.fetchHistoricalCheckpoints(ctx, finalizedAt(2, 0x01)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, err := d.blocks.GetBySlot(32); err != nil {
+				t.Errorf("expected the historical block to be fetched, got %v", err)
+			}
+
+			if _, err := d.blocks.GetBySlot(0); (err == nil) != test.fetchGenesis {
+				t.Errorf("expected the genesis block to be fetched to be %v, got %v", test.fetchGenesis, err)
+			}
+
+			// A genesis block that was cached before genesis fetching was disabled isn't served.
+			if err := d.blocks.Add(blocks[eth.SlotAsString(0)], time.Now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := d.GetBlockBySlot(ctx, 0); (err == nil) != test.fetchGenesis {
+				t.Errorf("expected the genesis block to be served to be %v, got %v", test.fetchGenesis, err)
+			}
+		})
+	}
+}
+
+func TestGenesisFetchesBackOff(t *testing.T) {
+	ctx := context.Background()
+
+	upstream := &fakeUpstream{status: newHealthyStatus(), finality: finalizedAt(2, 0x01), blockErr: errors.New("unavailable")}
+
+	d := newTestDownloadProvider("test_genesis_backoff")
+	d.config.FetchGenesis = true
+	d.nodes = Nodes{newTestNode("a", upstream)}
+
+	backoff := node.NewBackoff(50*time.Millisecond, time.Second)
+	deadline := time.Now().Add(time.Hour)
+
+	d.checkGenesisWithBackoff(ctx, backoff, deadline)
+	d.checkGenesisWithBackoff(ctx, backoff, deadline)
+
+	if fetches := atomic.LoadInt32(&upstream.blockFetches); fetches != 1 {
+		t.Fatalf("expected the genesis bundle to not be requested again while backed off, got %d requests", fetches)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	d.checkGenesisWithBackoff(ctx, backoff, deadline)
+
+	if fetches := atomic.LoadInt32(&upstream.blockFetches); fetches != 2 {
+		t.Fatalf("expected the genesis bundle to be requested again once the backoff passed, got %d requests", fetches)
+	}
+
+	if interval := backoff.Interval(); interval != 100*time.Millisecond {
+		t.Errorf("expected the backoff to double after another failure, got %s", interval)
+	}
+
+	// Light mode doesn't need the genesis state, so the check succeeds.
+	time.Sleep(300 * time.Millisecond)
+
+	d.config.Mode = OperatingModeLight
+
+	d.checkGenesisWithBackoff(ctx, backoff, deadline)
+
+	if interval := backoff.Interval(); interval != 0 {
+		t.Errorf("expected the backoff to be reset by a successful check, got %s", interval)
+	}
+
+	if d.genesisUnavailable() {
+		t.Error("expected genesis to not be given up on before the deadline")
+	}
+
+	// Failing after the deadline gives up on genesis.
+	d.config.Mode = OperatingModeFull
+	d.config.GenesisFetchDeadline = time.Minute
+
+	d.checkGenesisWithBackoff(ctx, backoff, time.Now().Add(-time.Second))
+
+	if !d.genesisUnavailable() {
+		t.Error("expected genesis to be given up on after the deadline")
+	}
+}
+
+func TestJustifiedCheckpointIsServedOnceDownloaded(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head := finalizedAt(1, 0x01)
+	head.Justified = &phase0.Checkpoint{Epoch: 2, Root: root}
+
+	upstream := &fakeUpstream{block: block, state: data, status: newHealthyStatus(), finality: head}
+
+	d := newTestDownloadProvider("test_justified")
+	d.nodes = Nodes{newTestNode("a", upstream)}
+	d.head = head
+
+	if _, err := d.Justified(ctx); !errors.Is(err, ErrJustifiedNotAvailable) {
+		t.Fatalf("expected %v before the justified bundle is downloaded, got %v", ErrJustifiedNotAvailable, err)
+	}
+
+	if err := d.checkForNewJustifiedCheckpoint(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	justified, err := d.Justified(ctx)
+	if err != nil {
+		t.Fatalf("expected the justified checkpoint to be served, got %v", err)
+	}
+
+	if *justified != *head.Justified {
+		t.Errorf("expected justified checkpoint %v, got %v", head.Justified, justified)
+	}
+
+	if _, err := d.GetBeaconStateByRoot(ctx, root); err != nil {
+		t.Errorf("expected the justified state to be cached, got %v", err)
+	}
+
+	// The bundle isn't downloaded again while the justified checkpoint is unchanged.
+	if err := d.checkForNewJustifiedCheckpoint(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fetches := atomic.LoadInt32(&upstream.stateFetches); fetches != 1 {
+		t.Errorf("expected the justified state to be downloaded once, got %d", fetches)
+	}
+}
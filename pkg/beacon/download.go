@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
@@ -13,17 +14,14 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// crossCheckUpstreams is how many upstreams must agree on the finalized block when VerifyAcrossUpstreams is enabled.
+const crossCheckUpstreams = 2
+
 func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.Finality) error {
-	upstream, err := d.nodes.
-		Ready(ctx).
-		DataProviders(ctx).
-		PastFinalizedCheckpoint(ctx, checkpoint). // Ensure we attempt to fetch the bundle from a node that knows about the checkpoint.
-		RandomNode(ctx)
-	if err != nil {
-		return err
-	}
+	upstreams := d.dataProviders(ctx).
+		PastFinalizedCheckpoint(ctx, checkpoint) // Ensure we attempt to fetch the bundle from a node that knows about the checkpoint.
 
-	block, err := d.fetchBundle(ctx, checkpoint.Finalized.Root, upstream)
+	block, err := d.fetchBundleWithFallback(ctx, checkpoint.Finalized.Root, upstreams)
 	if err != nil {
 		return err
 	}
@@ -35,15 +33,22 @@ func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.
 		return fmt.Errorf("failed to get slot from block: %w", err)
 	}
 
-	// For simplicity we'll hardcode SLOTS_PER_EPOCH to 32.
-	// TODO(sam.calder-mason): Fetch this from a beacon node and store it in the instance.
-	const slotsPerEpoch = 32
+	slotsPerEpoch := d.slotsPerEpoch(ctx)
 	if blockSlot%slotsPerEpoch != 0 {
 		return fmt.Errorf("block slot is not aligned from an epoch boundary: %d", blockSlot)
 	}
 
-	d.servingBundle = checkpoint
-	d.metrics.ObserveServingEpoch(checkpoint.Finalized.Epoch)
+	if d.config.VerifyAcrossUpstreams {
+		if err := d.crossCheckFinalizedBlock(ctx, checkpoint, blockSlot, upstreams); err != nil {
+			return err
+		}
+	}
+
+	if !d.serveBundle(checkpoint) {
+		d.log.Info("Not serving the new finalized checkpoint bundle as a bundle is pinned")
+
+		return nil
+	}
 
 	d.log.WithFields(
 		logrus.Fields{
@@ -55,6 +60,42 @@ func (d *Default) downloadServingCheckpoint(ctx context.Context, checkpoint *v1.
 	return nil
 }
 
+// crossCheckFinalizedBlock fetches the block at the finalized slot from upstreams until crossCheckUpstreams of them agree
+// it is the finalized block, so a single upstream can't decide what we serve. Any upstream serving a different block
+// fails the check outright.
+func (d *Default) crossCheckFinalizedBlock(ctx context.Context, checkpoint *v1.Finality, slot phase0.Slot, upstreams Nodes) error {
+	agreed := 0
+
+	for _, upstream := range upstreams.Shuffled(ctx) {
+		block, err := upstream.FetchBlock(ctx, eth.SlotAsString(slot))
+		if err != nil || block == nil {
+			d.log.
+				WithError(err).
+				WithField("upstream", upstream.Config.Name).
+				WithField("slot", slot).
+				Debug("Failed to fetch finalized block to cross check")
+
+			continue
+		}
+
+		if err := VerifyBlockAtSlot(block, slot, checkpoint.Finalized, d.slotsPerEpoch(ctx)); err != nil {
+			d.metrics.ObserveCrossCheckFailure()
+
+			return fmt.Errorf("%w: upstream %s disagrees: %s", ErrCheckpointNotCrossChecked, upstream.Config.Name, err)
+		}
+
+		agreed++
+
+		if agreed >= crossCheckUpstreams {
+			return nil
+		}
+	}
+
+	d.metrics.ObserveCrossCheckFailure()
+
+	return fmt.Errorf("%w: only %d of %d required upstreams agreed", ErrCheckpointNotCrossChecked, agreed, crossCheckUpstreams)
+}
+
 func (d *Default) checkGenesis(ctx context.Context) error {
 	// Don't bother checking for genesis state if we don't care about states.
 	if !d.shouldDownloadStates() {
@@ -74,7 +115,7 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 
 	d.log.Debug("Fetching genesis state")
 
-	readyNodes := d.nodes.Ready(ctx)
+	readyNodes := d.readyNodes(ctx)
 	if len(readyNodes) == 0 {
 		return errors.New("no nodes ready")
 	}
@@ -85,7 +126,7 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 		return err
 	}
 
-	genesisBlock, err := randomNode.Beacon.FetchBlock(ctx, "genesis")
+	genesisBlock, err := randomNode.FetchBlock(ctx, "genesis")
 	if err != nil {
 		return err
 	}
@@ -99,13 +140,8 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 		return err
 	}
 
-	upstream, err := d.nodes.Ready(ctx).DataProviders(ctx).RandomNode(ctx)
-	if err != nil {
-		return err
-	}
-
 	// Fetch the bundle
-	if _, err := d.fetchBundle(ctx, genesisBlockRoot, upstream); err != nil {
+	if _, err := d.fetchBundleWithFallback(ctx, genesisBlockRoot, d.dataProviders(ctx)); err != nil {
 		return err
 	}
 
@@ -117,30 +153,29 @@ func (d *Default) checkGenesis(ctx context.Context) error {
 }
 
 func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1.Finality) error {
-	if d.spec == nil {
-		return errors.New("beacon spec unavailable")
+	sp, err := d.Spec(ctx)
+	if err != nil {
+		return err
 	}
 
-	if d.genesis == nil {
-		return errors.New("genesis time unavailable")
+	if _, err := d.Genesis(ctx); err != nil {
+		return err
 	}
 
 	// Download the previous n epochs worth of epoch boundaries if they don't already exist
-	upstream, err := d.nodes.
-		Ready(ctx).
-		DataProviders(ctx).
+	upstream, err := d.dataProviders(ctx).
 		PastFinalizedCheckpoint(ctx, checkpoint).
 		RandomNode(ctx)
 	if err != nil {
 		return errors.New("no data provider node available")
 	}
 
-	sp := d.spec
-
 	slotsInScope := make(map[phase0.Slot]struct{})
 
-	// We always care about the genesis slot.
-	slotsInScope[0] = struct{}{}
+	// We always care about the genesis slot, unless genesis fetching is disabled or has been given up on.
+	if d.config.FetchGenesis && !d.genesisUnavailable() {
+		slotsInScope[0] = struct{}{}
+	}
 
 	// historicalFailureLimit is the amount of times we'll try to download a block
 	// before we permanently give up.
@@ -154,13 +189,14 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 		slotsInScope[slot] = struct{}{}
 	}
 
+	missing := []phase0.Slot{}
+
 	for slot := range slotsInScope {
-		failureCount, exists := d.historicalSlotFailures[slot]
-		if !exists {
+		if _, exists := d.historicalSlotFailures[slot]; !exists {
 			d.historicalSlotFailures[slot] = 0
 		}
 
-		if failureCount >= historicalFailureLimit {
+		if d.historicalSlotFailures[slot] >= historicalFailureLimit {
 			continue
 		}
 
@@ -168,15 +204,27 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 			continue
 		}
 
-		if _, err := d.downloadBlock(ctx, slot, upstream); err != nil {
-			failureCount++
-
-			d.log.WithError(err).
+		// Don't bother fetching a block that would expire as soon as it was stored.
+		if expiresAt, ok := d.blockExpiration(ctx, slot); !ok {
+			d.log.
 				WithField("slot", eth.SlotAsString(slot)).
-				WithField("failure_count", failureCount).
-				Error("Failed to download historical block")
+				WithField("expires_at", expiresAt).
+				Debug("Skipping historical block as it is older than the block retention")
+
+			continue
 		}
 
+		missing = append(missing, slot)
+	}
+
+	for slot, err := range d.downloadBlocks(ctx, missing, checkpoint.Finalized, upstream) {
+		failureCount := d.historicalSlotFailures[slot] + 1
+
+		d.log.WithError(err).
+			WithField("slot", eth.SlotAsString(slot)).
+			WithField("failure_count", failureCount).
+			Error("Failed to download historical block")
+
 		if failureCount == historicalFailureLimit {
 			d.log.WithField("slot", eth.SlotAsString(slot)).
 				WithField("failure_count", failureCount).
@@ -184,8 +232,6 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 		}
 
 		d.historicalSlotFailures[slot] = failureCount
-
-		time.Sleep(50 * time.Millisecond)
 	}
 
 	// Cleanup any banned slots that we don't care about anymore to prevent leaking memory.
@@ -198,16 +244,62 @@ func (d *Default) fetchHistoricalCheckpoints(ctx context.Context, checkpoint *v1
 	return nil
 }
 
-func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream *Node) (*spec.VersionedSignedBeaconBlock, error) {
+// downloadBlocks downloads the given slots from the upstream using a bounded pool of workers.
+// A failure to download one slot does not stop the others; the errors are returned keyed by slot.
+func (d *Default) downloadBlocks(ctx context.Context, slots []phase0.Slot, finalized *phase0.Checkpoint, upstream *Node) map[phase0.Slot]error {
+	concurrency := d.config.HistoricalFetchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errs   = make(map[phase0.Slot]error)
+		queued = make(chan phase0.Slot)
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for slot := range queued {
+				if _, err := d.downloadBlock(ctx, slot, finalized, upstream); err != nil {
+					mu.Lock()
+					errs[slot] = err
+					mu.Unlock()
+				}
+
+				time.Sleep(50 * time.Millisecond)
+			}
+		}()
+	}
+
+	for _, slot := range slots {
+		queued <- slot
+	}
+
+	close(queued)
+
+	wg.Wait()
+
+	return errs
+}
+
+// downloadBlock downloads and stores the block at the given slot, refusing to store a block that isn't for the slot
+// or, at the finalized slot, isn't the finalized block.
+func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, finalized *phase0.Checkpoint, upstream *Node) (*spec.VersionedSignedBeaconBlock, error) {
 	// If we don't know genesis time yet, don't bother fetching blocks as
 	// we won't be able to calculate an expiry.
-	if d.genesis == nil {
-		return nil, errors.New("genesis time not known")
+	if _, err := d.Genesis(ctx); err != nil {
+		return nil, err
 	}
 
 	// Same thing with the chain spec.
-	if d.spec == nil {
-		return nil, errors.New("chain spec not known")
+	if _, err := d.Spec(ctx); err != nil {
+		return nil, err
 	}
 
 	// Check if we already have the block.
@@ -217,7 +309,7 @@ func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream
 	}
 
 	// Download the block from our upstream.
-	block, err := upstream.Beacon.FetchBlock(ctx, eth.SlotAsString(slot))
+	block, err := upstream.FetchBlock(ctx, eth.SlotAsString(slot))
 	if err != nil {
 		return nil, err
 	}
@@ -226,6 +318,10 @@ func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream
 		return nil, errors.New("invalid block")
 	}
 
+	if err := VerifyBlockAtSlot(block, slot, finalized, d.slotsPerEpoch(ctx)); err != nil {
+		return nil, err
+	}
+
 	stateRoot, err := block.StateRoot()
 	if err != nil {
 		return nil, err
@@ -251,13 +347,107 @@ func (d *Default) downloadBlock(ctx context.Context, slot phase0.Slot, upstream
 	return block, nil
 }
 
+// bundleCached returns true if the block of the given root is cached, along with its state if we serve states.
+func (d *Default) bundleCached(root phase0.Root) bool {
+	block, err := d.blocks.GetByRoot(root)
+	if err != nil || block == nil {
+		return false
+	}
+
+	if !d.shouldDownloadStates() {
+		return true
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return false
+	}
+
+	state, err := d.states.GetByStateRoot(stateRoot)
+
+	return err == nil && state != nil
+}
+
+// fetchBundleWithFallback attempts to fetch the bundle from each of the given upstreams in a random order,
+// giving up after BundleDownloadMaxAttempts failures. Each attempt fetches the whole bundle from a single upstream
+// so the halves of a bundle are never mixed across upstreams that may disagree.
+func (d *Default) fetchBundleWithFallback(ctx context.Context, root phase0.Root, upstreams Nodes) (block *spec.VersionedSignedBeaconBlock, err error) {
+	ctx, span := d.startSpan(ctx, "fetch_bundle")
+	defer func() { endSpan(span, err) }()
+
+	span.SetAttribute("root", eth.RootAsString(root))
+
+	if len(upstreams) == 0 {
+		return nil, errors.New("no data provider node available")
+	}
+
+	var lastErr error
+
+	for attempt, upstream := range upstreams.Shuffled(ctx) {
+		if attempt >= d.config.BundleDownloadMaxAttempts {
+			break
+		}
+
+		block, err = d.fetchBundle(ctx, root, upstream)
+		// Another upstream can't help if the download was cancelled or the bundle has already expired.
+		if errors.Is(err, ErrBundleDownloadCancelled) || errors.Is(err, ErrBlockExpired) {
+			return nil, err
+		}
+
+		if err != nil {
+			d.log.
+				WithError(err).
+				WithField("upstream", upstream.Config.Name).
+				WithField("attempt", attempt+1).
+				WithField("root", eth.RootAsString(root)).
+				Warn("Failed to fetch bundle from upstream")
+
+			lastErr = err
+
+			continue
+		}
+
+		d.log.
+			WithField("upstream", upstream.Config.Name).
+			WithField("attempt", attempt+1).
+			WithField("root", eth.RootAsString(root)).
+			Info("Bundle download satisfied by upstream")
+
+		return block, nil
+	}
+
+	err = fmt.Errorf("failed to fetch bundle from any upstream: %w", lastErr)
+
+	return nil, err
+}
+
 func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *Node) (*spec.VersionedSignedBeaconBlock, error) {
+	return d.bundleDownloads.Do(ctx, root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+		ctx, span := d.startSpan(ctx, "download_bundle")
+		span.SetAttribute("upstream", upstream.Config.Name)
+
+		d.metrics.ObserveBundleDownloadStarted()
+
+		block, err := d.downloadBundle(ctx, root, upstream)
+
+		d.metrics.ObserveBundleDownloadFinished(err)
+
+		endSpan(span, err)
+
+		return block, err
+	})
+}
+
+func (d *Default) downloadBundle(ctx context.Context, root phase0.Root, upstream *Node) (*spec.VersionedSignedBeaconBlock, error) {
 	d.log.Infof("Fetching bundle from node %s with root %#x", upstream.Config.Name, root)
 
 	block, err := d.blocks.GetByRoot(root)
 	if err != nil || block == nil {
 		// Download the block.
-		block, err = upstream.Beacon.FetchBlock(ctx, fmt.Sprintf("%#x", root))
+		_, span := d.startSpan(ctx, "fetch_block")
+		block, err = upstream.FetchBlock(ctx, fmt.Sprintf("%#x", root))
+		endSpan(span, err)
+
 		if err != nil {
 			return nil, err
 		}
@@ -292,21 +482,27 @@ func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *N
 		WithField("state_root", fmt.Sprintf("%#x", stateRoot)).
 		Info("Fetched beacon block")
 
-	err = d.storeBlock(ctx, block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store block: %w", err)
-	}
+	// Fetch and verify the state before storing anything, so a bad state never leaves its block behind to be served
+	// as half a bundle.
+	var beaconState []byte
 
 	if d.shouldDownloadStates() {
 		// If the state already exists, don't bother downloading it again.
 		existingState, err := d.states.GetByStateRoot(stateRoot)
 		if err == nil && existingState != nil {
+			if err := d.storeBlock(ctx, block); err != nil {
+				return nil, fmt.Errorf("failed to store block: %w", err)
+			}
+
 			d.log.Infof("Successfully fetched bundle from %s", upstream.Config.Name)
 
 			return block, nil
 		}
 
-		beaconState, err := upstream.Beacon.FetchRawBeaconState(ctx, eth.SlotAsString(slot), "application/octet-stream")
+		_, span := d.startSpan(ctx, "fetch_state")
+		beaconState, err = upstream.FetchRawBeaconState(ctx, eth.SlotAsString(slot), "application/octet-stream")
+		endSpan(span, err)
+
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch beacon state: %w", err)
 		}
@@ -315,22 +511,59 @@ func (d *Default) fetchBundle(ctx context.Context, root phase0.Root, upstream *N
 			return nil, errors.New("beacon state is nil")
 		}
 
-		expiresAt := time.Now().Add(FinalityHaltedServingPeriod)
-		if slot == phase0.Slot(0) {
-			expiresAt = time.Now().Add(999999 * time.Hour)
+		// Check the size first as it's cheap, and an oversized state is likely corrupt or malicious.
+		if err := VerifyStateSize(beaconState, d.config.MaxStateBytes); err != nil {
+			return nil, err
 		}
 
-		if err := d.states.Add(stateRoot, &beaconState, expiresAt, slot); err != nil {
+		// Refuse to store a state that doesn't match the block so we never serve an inconsistent bundle.
+		if err := VerifyStateRoot(block, beaconState); err != nil {
+			return nil, err
+		}
+	}
+
+	err = d.storeBlock(ctx, block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store block: %w", err)
+	}
+
+	if beaconState != nil {
+		expiresAt, ok := d.blockExpiration(ctx, slot)
+		if !ok {
+			return nil, fmt.Errorf("%w: state for slot %d expired at %s", ErrBlockExpired, slot, expiresAt)
+		}
+
+		_, span := d.startSpan(ctx, "store_state")
+		err = d.states.Add(stateRoot, &beaconState, expiresAt, slot)
+		endSpan(span, err)
+
+		if err != nil {
 			return nil, fmt.Errorf("failed to store beacon state: %w", err)
 		}
+
+		d.metrics.ObserveStateSize(len(beaconState))
 	}
 
-	if slot != phase0.Slot(0) {
-		epoch := phase0.Epoch(slot / d.spec.SlotsPerEpoch)
+	if slot != phase0.Slot(0) && !upstream.ClientType().SupportsDepositSnapshot() {
+		d.log.
+			WithField("upstream", upstream.Config.Name).
+			WithField("client_type", upstream.ClientType()).
+			Debug("Skipping deposit snapshot download as the upstream's client doesn't serve them")
+	} else if slot != phase0.Slot(0) {
+		epoch := phase0.Epoch(slot / d.slotsPerEpoch(ctx))
 
-		// Download and store deposit snapshots
+		// Download and store deposit snapshots. Not every upstream supports EIP-4881 so the bundle is still
+		// usable without one; we'll try again the next time the bundle is fetched.
 		if err := d.downloadAndStoreDepositSnapshot(ctx, epoch, upstream); err != nil {
-			return nil, fmt.Errorf("failed to download and store deposit snapshot: %w", err)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			d.log.
+				WithError(err).
+				WithField("epoch", epoch).
+				WithField("upstream", upstream.Config.Name).
+				Warn("Failed to download deposit snapshot")
 		}
 	}
 
@@ -346,7 +579,7 @@ func (d *Default) downloadAndStoreDepositSnapshot(ctx context.Context, epoch pha
 	}
 
 	// Download the deposit snapshot from our upstream.
-	depositSnapshot, err := node.Beacon.FetchDepositSnapshot(ctx)
+	depositSnapshot, err := node.FetchDepositSnapshot(ctx)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,430 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+func TestFetchBundleNeverMixesUpstreams(t *testing.T) {
+	st, data := newTestAltairStateSSZ(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestAltairBlock(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both upstreams serve the same block, but one serves a state that doesn't belong to it.
+	good := &fakeUpstream{block: block, state: data}
+	bad := &fakeUpstream{block: block, state: data[:len(data)-1]}
+
+	d := newTestDownloadProvider("test_download_mix")
+
+	upstreams := Nodes{
+		newTestNode("good", good),
+		newTestNode("bad", bad),
+	}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored, err := d.states.GetByStateRoot(stateRoot)
+	if err != nil {
+		t.Fatalf("expected the state to be stored: %v", err)
+	}
+
+	if !bytes.Equal(*stored, data) {
+		t.Fatal("stored state was not the one served by the upstream that completed the bundle")
+	}
+
+	// The rest of the bundle must come from the upstream whose state was accepted.
+	if atomic.LoadInt32(&bad.snapshotFetches) != 0 {
+		t.Error("deposit snapshot was fetched from the upstream whose state was rejected")
+	}
+
+	if atomic.LoadInt32(&good.stateFetches) != 1 || atomic.LoadInt32(&good.snapshotFetches) != 1 {
+		t.Errorf("expected the bundle to be completed by a single upstream, got %d state and %d snapshot fetches",
+			good.stateFetches, good.snapshotFetches)
+	}
+}
+
+func TestFetchBundleGivesUpWhenNoUpstreamIsConsistent(t *testing.T) {
+	st, data := newTestAltairStateSSZ(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestAltairBlock(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_download_inconsistent")
+
+	upstreams := Nodes{
+		newTestNode("a", &fakeUpstream{block: block, state: data[:len(data)-1]}),
+		newTestNode("b", &fakeUpstream{block: block, state: data[:len(data)-2]}),
+	}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err == nil {
+		t.Fatal("expected the download to fail")
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); !errors.Is(err, store.ErrStateNotFound) {
+		t.Errorf("expected no state to be stored, got %v", err)
+	}
+
+	// The block is only stored along with a verified state, so it can't be served as half a bundle.
+	if stored, err := d.blocks.GetByRoot(root); err == nil && stored != nil {
+		t.Error("expected the block not to be stored without its state")
+	}
+}
+
+func TestDownloadBlockRejectsTamperedBlock(t *testing.T) {
+	block := newTestPhase0Block(phase0.Root{})
+
+	d := newTestDownloadProvider("test_download_tampered")
+
+	upstream := newTestNode("a", &fakeUpstream{block: block})
+
+	// The upstream claims the block at slot 64 is canonical at slot 96.
+	if _, err := d.downloadBlock(context.Background(), 96, nil, upstream); !errors.Is(err, ErrBlockSlotMismatch) {
+		t.Fatalf("expected %v, got %v", ErrBlockSlotMismatch, err)
+	}
+
+	if _, err := d.blocks.GetBySlot(64); err == nil {
+		t.Error("expected the tampered block not to be stored")
+	}
+
+	// The upstream serves a block at the finalized slot that isn't the finalized block.
+	finalized := &phase0.Checkpoint{Epoch: 2, Root: phase0.Root{0x01}}
+	if _, err := d.downloadBlock(context.Background(), 64, finalized, upstream); !errors.Is(err, ErrBlockRootMismatch) {
+		t.Fatalf("expected %v, got %v", ErrBlockRootMismatch, err)
+	}
+
+	if _, err := d.blocks.GetBySlot(64); err == nil {
+		t.Error("expected the tampered block not to be stored")
+	}
+}
+
+func TestFetchBundleStopsWhenCancelled(t *testing.T) {
+	st, _ := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_download_cancelled")
+
+	a := &fakeUpstream{block: block, hang: true}
+	b := &fakeUpstream{block: block, hang: true}
+
+	upstreams := Nodes{
+		newTestNode("a", a),
+		newTestNode("b", b),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := d.fetchBundleWithFallback(ctx, root, upstreams); !errors.Is(err, ErrBundleDownloadCancelled) {
+		t.Fatalf("expected %v, got %v", ErrBundleDownloadCancelled, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the download to return promptly after cancellation, took %s", elapsed)
+	}
+
+	// A cancelled download shouldn't fall back to the next upstream.
+	if fetches := atomic.LoadInt32(&a.stateFetches) + atomic.LoadInt32(&b.stateFetches); fetches != 1 {
+		t.Errorf("expected a single state request, got %d", fetches)
+	}
+
+	if err := d.bundleDownloads.Wait(context.Background()); err != nil {
+		t.Errorf("expected no downloads to be left in flight: %v", err)
+	}
+}
+
+func TestFetchBundleWithoutDepositSnapshot(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_download_no_snapshot")
+
+	upstreams := Nodes{
+		newTestNode("a", &fakeUpstream{block: block, state: data, noSnapshot: true}),
+	}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err != nil {
+		t.Fatalf("expected the bundle to be usable without a deposit snapshot, got %v", err)
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); err != nil {
+		t.Fatalf("expected the state to be stored: %v", err)
+	}
+
+	if _, err := d.GetDepositSnapshot(context.Background(), 2); !errors.Is(err, store.ErrDepositSnapshotNotFound) {
+		t.Errorf("expected %v, got %v", store.ErrDepositSnapshotNotFound, err)
+	}
+}
+
+func TestFetchBundleSkipsDepositSnapshotForUnsupportedClients(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_download_client_quirks")
+
+	upstream := &fakeUpstream{block: block, state: data, version: "Prysm/v4.0.0/linux-amd64"}
+
+	upstreams := Nodes{
+		newTestNode("a", upstream),
+	}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fetches := atomic.LoadInt32(&upstream.snapshotFetches); fetches != 0 {
+		t.Errorf("expected no deposit snapshot requests to a client that doesn't serve them, got %d", fetches)
+	}
+}
+
+func TestFetchBundleRejectsOversizedState(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_download_oversized")
+	d.config.MaxStateBytes = int64(len(data)) - 1
+
+	upstreams := Nodes{
+		newTestNode("a", &fakeUpstream{block: block, state: data}),
+	}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); !errors.Is(err, ErrStateTooLarge) {
+		t.Fatalf("expected %v, got %v", ErrStateTooLarge, err)
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); err == nil {
+		t.Error("expected the oversized state to not be stored")
+	}
+
+	d.config.MaxStateBytes = int64(len(data))
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err != nil {
+		t.Fatalf("expected a state at the limit to be stored, got %v", err)
+	}
+}
+
+func TestDownloadBlocksIsBoundedAndIndependent(t *testing.T) {
+	blocks := map[string]*spec.VersionedSignedBeaconBlock{}
+
+	slots := []phase0.Slot{32, 64, 96, 128, 160, 192}
+	for _, slot := range slots {
+		blocks[eth.SlotAsString(slot)] = beacontest.Phase0Block(slot, phase0.Root{byte(slot)})
+	}
+
+	// The upstream doesn't have the block at slot 96.
+	delete(blocks, eth.SlotAsString(96))
+
+	upstream := &fakeUpstream{status: newHealthyStatus(), blocks: blocks, blockDelay: 20 * time.Millisecond}
+
+	d := newTestDownloadProvider("test_download_blocks")
+	d.config.HistoricalFetchConcurrency = 2
+
+	errs := d.downloadBlocks(context.Background(), slots, finalizedAt(10, 0x01).Finalized, newTestNode("a", upstream))
+
+	if len(errs) != 1 || errs[96] == nil {
+		t.Errorf("expected only slot 96 to fail, got %v", errs)
+	}
+
+	for _, slot := range slots {
+		if _, err := d.blocks.GetBySlot(slot); (err == nil) != (slot != 96) {
+			t.Errorf("unexpected result storing the block at slot %d: %v", slot, err)
+		}
+	}
+
+	if inFlight := atomic.LoadInt32(&upstream.maxBlockRequestsInFlight); inFlight != 2 {
+		t.Errorf("expected 2 blocks to be requested at once, got %d", inFlight)
+	}
+}
+
+func TestFetchBundleFallbackIsBoundedByMaxAttempts(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := []*fakeUpstream{
+		{blockErr: errors.New("unavailable")},
+		{blockErr: errors.New("unavailable")},
+		{blockErr: errors.New("unavailable")},
+	}
+
+	upstreams := Nodes{newTestNode("a", failing[0]), newTestNode("b", failing[1]), newTestNode("c", failing[2])}
+
+	d := newTestDownloadProvider("test_download_max_attempts")
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err == nil {
+		t.Fatal("expected the download to fail")
+	}
+
+	requested := 0
+
+	for _, upstream := range failing {
+		requested += int(atomic.LoadInt32(&upstream.blockFetches))
+	}
+
+	if requested != d.config.BundleDownloadMaxAttempts {
+		t.Errorf("expected %d upstreams to be tried, got %d", d.config.BundleDownloadMaxAttempts, requested)
+	}
+
+	// An upstream serving the bundle is fallen back to when the others fail.
+	d = newTestDownloadProvider("test_download_fallback")
+	d.config.BundleDownloadMaxAttempts = 3
+
+	upstreams = Nodes{upstreams[0], upstreams[1], newTestNode("good", &fakeUpstream{block: block, state: data})}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err != nil {
+		t.Fatalf("expected the download to fall back to the upstream serving the bundle, got %v", err)
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); err != nil {
+		t.Errorf("expected the state to be stored, got %v", err)
+	}
+}
+
+func TestFetchBundleReportsExpiredBlocks(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_download_expired")
+	// Slot 64 was more than the hour of block retention ago.
+	d.genesis.GenesisTime = time.Now().Add(-2 * time.Hour)
+
+	a := &fakeUpstream{block: block, state: data}
+	b := &fakeUpstream{block: block, state: data}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, Nodes{newTestNode("a", a), newTestNode("b", b)}); !errors.Is(err, ErrBlockExpired) {
+		t.Fatalf("expected %v, got %v", ErrBlockExpired, err)
+	}
+
+	if fetches := atomic.LoadInt32(&a.blockFetches) + atomic.LoadInt32(&b.blockFetches); fetches != 1 {
+		t.Errorf("expected the other upstream not to be tried for an expired block, got %d block fetches", fetches)
+	}
+
+	if _, err := d.blocks.GetByRoot(root); !errors.Is(err, store.ErrBlockNotFound) {
+		t.Errorf("expected the expired block not to be stored, got %v", err)
+	}
+}
+
+func TestFetchHistoricalCheckpointsSkipsExpiredBlocks(t *testing.T) {
+	blocks := map[string]*spec.VersionedSignedBeaconBlock{}
+	for _, slot := range []phase0.Slot{192, 224, 256} {
+		blocks[eth.SlotAsString(slot)] = beacontest.Phase0Block(slot, phase0.Root{byte(slot)})
+	}
+
+	upstream := &fakeUpstream{status: newHealthyStatus(), finality: finalizedAt(9, 0x01), blocks: blocks}
+
+	d := newTestDownloadProvider("test_historical_retention")
+	// Slot 256 started 8.8 minutes ago, slot 224 15.2 minutes ago and slot 192 21.6 minutes ago.
+	d.genesis.GenesisTime = time.Now().Add(-time.Hour)
+	d.config.BlockRetention = 12 * time.Minute
+	d.config.HistoricalEpochCount = 4
+	d.nodes = Nodes{newTestNode("a", upstream)}
+
+	if err := d.fetchHistoricalCheckpoints(context.Background(), finalizedAt(9, 0x01)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := d.blocks.GetBySlot(256); err != nil {
+		t.Errorf("expected the block within the retention to be fetched, got %v", err)
+	}
+
+	if fetches := atomic.LoadInt32(&upstream.blockFetches); fetches != 1 {
+		t.Errorf("expected only the block within the retention to be requested, got %d requests", fetches)
+	}
+}
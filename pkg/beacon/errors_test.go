@@ -0,0 +1,73 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+	"github.com/sirupsen/logrus"
+)
+
+func TestNotFoundErrorsThroughProvider(t *testing.T) {
+	log := logrus.New()
+
+	d := &Default{
+		blocks:  store.NewBlock(log, store.Config{MaxItems: 10}, "errors_test"),
+		states:  store.NewBeaconState(log, store.Config{MaxItems: 10}, "errors_test"),
+		metrics: NewMetrics("errors_test_beacon"),
+	}
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		call   func() error
+		expect error
+	}{
+		{"block by root", func() error { _, err := d.GetBlockByRoot(ctx, phase0.Root{0x01}); return err }, store.ErrBlockNotFound},
+		{"block by slot", func() error { _, err := d.GetBlockBySlot(ctx, phase0.Slot(32)); return err }, store.ErrBlockNotFound},
+		{"block by state root", func() error { _, err := d.GetBlockByStateRoot(ctx, phase0.Root{0x01}); return err }, store.ErrBlockNotFound},
+		{"block by parent root", func() error { _, err := d.GetBlockByParentRoot(ctx, phase0.Root{0x01}); return err }, store.ErrBlockNotFound},
+		{"state by state root", func() error { _, err := d.GetBeaconStateByStateRoot(ctx, phase0.Root{0x01}); return err }, store.ErrStateNotFound},
+		{"state by root", func() error { _, err := d.GetBeaconStateByRoot(ctx, phase0.Root{0x01}); return err }, store.ErrBlockNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call()
+
+			if !errors.Is(err, test.expect) {
+				t.Errorf("expected %v, got %v", test.expect, err)
+			}
+
+			if !errors.Is(err, cache.ErrNotFound) {
+				t.Errorf("expected %v to also match cache.ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreLookupResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect string
+	}{
+		{"found", nil, "hit"},
+		{"block not found", store.ErrBlockNotFound, "miss"},
+		{"state not found", store.ErrStateNotFound, "miss"},
+		{"genesis unavailable", ErrGenesisBundleUnavailable, "miss"},
+		{"other error", errors.New("boom"), "error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := storeLookupResult(test.err); got != test.expect {
+				t.Fatalf("expected %q, got %q", test.expect, got)
+			}
+		})
+	}
+}
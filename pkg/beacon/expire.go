@@ -6,6 +6,11 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 )
 
+const (
+	// genesisRetentionPeriod is used for the genesis block and state, which should never expire.
+	genesisRetentionPeriod = 999999 * time.Hour
+)
+
 func CalculateSlotExpiration(slot phase0.Slot, slotsOfHistory int) phase0.Slot {
 	return slot + phase0.Slot(slotsOfHistory)
 }
@@ -13,3 +18,16 @@ func CalculateSlotExpiration(slot phase0.Slot, slotsOfHistory int) phase0.Slot {
 func GetSlotTime(slot phase0.Slot, secondsPerSlot time.Duration, genesis time.Time) time.Time {
 	return genesis.Add(time.Duration(slot) * secondsPerSlot)
 }
+
+// CalculateBlockExpiration returns when a block or state at the given slot should expire, measured from the slot's
+// wall clock time. The genesis slot never expires. The returned bool is false if the expiration has already passed,
+// in which case the item should not be stored at all.
+func CalculateBlockExpiration(slot phase0.Slot, slotTime time.Time, retention time.Duration, now time.Time) (time.Time, bool) {
+	if slot == phase0.Slot(0) {
+		return now.Add(genesisRetentionPeriod), true
+	}
+
+	expiresAt := slotTime.Add(retention)
+
+	return expiresAt, expiresAt.After(now)
+}
@@ -1,6 +1,7 @@
 package beacon
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -77,3 +78,74 @@ func TestExpireMultiple(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateBlockExpiration(t *testing.T) {
+	t.Parallel()
+
+	genesis, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+	retention := 14 * 24 * time.Hour
+	now := genesis.Add(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		slot      phase0.Slot
+		expiresAt time.Time
+		store     bool
+	}{
+		{"genesis", 0, now.Add(genesisRetentionPeriod), true},
+		{"recent", 180000, GetSlotTime(180000, defaultSecondsPerSlot, genesis).Add(retention), true},
+		{"retention boundary", 115200, now, false},
+		{"far in the past", 1, GetSlotTime(1, defaultSecondsPerSlot, genesis).Add(retention), false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			slotTime := GetSlotTime(test.slot, defaultSecondsPerSlot, genesis)
+			expiresAt, store := CalculateBlockExpiration(test.slot, slotTime, retention, now)
+
+			if !expiresAt.Equal(test.expiresAt) {
+				t.Errorf("expected expiry %v, got %v", test.expiresAt, expiresAt)
+			}
+
+			if store != test.store {
+				t.Errorf("expected store %v, got %v", test.store, store)
+			}
+		})
+	}
+}
+
+func TestBlockExpirationUsesTheConfiguredRetention(t *testing.T) {
+	d := newTestDownloadProvider("test_block_retention")
+
+	// Slot 10 started 8 minutes ago.
+	slotStart := d.genesis.GenesisTime.Add(10 * 12 * time.Second)
+
+	tests := []struct {
+		name      string
+		retention time.Duration
+		store     bool
+	}{
+		{name: "within retention", retention: time.Hour, store: true},
+		{name: "past retention", retention: time.Minute, store: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d.config.BlockRetention = test.retention
+
+			expiresAt, store := d.blockExpiration(context.Background(), 10)
+
+			if store != test.store {
+				t.Errorf("expected store %v, got %v", test.store, store)
+			}
+
+			if expected := slotStart.Add(test.retention); !expiresAt.Equal(expected) {
+				t.Errorf("expected expiry %v, got %v", expected, expiresAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	sbeacon "github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/sirupsen/logrus"
+)
+
+// failoverBeacon is one logical upstream reachable at a primary address and one or more fallback addresses. Each
+// address is its own beacon node, and requests go to the active one. It fails over to the next address when the
+// active one can't be connected to or its health check fails, and goes back to the primary after retryPrimary.
+//
+// Only the methods checkpointz uses are routed to the active address. Anything else goes to the primary.
+type failoverBeacon struct {
+	sbeacon.Node
+
+	log          logrus.FieldLogger
+	beacons      []sbeacon.Node
+	retryPrimary time.Duration
+
+	mu           sync.Mutex
+	active       int
+	failedOverAt time.Time
+}
+
+func newFailoverBeacon(log logrus.FieldLogger, beacons []sbeacon.Node, retryPrimary time.Duration) *failoverBeacon {
+	return &failoverBeacon{
+		Node:         beacons[0],
+		log:          log,
+		beacons:      beacons,
+		retryPrimary: retryPrimary,
+	}
+}
+
+// current returns the beacon node requests should go to, and its index.
+func (f *failoverBeacon) current() (sbeacon.Node, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.active != 0 && time.Since(f.failedOverAt) >= f.retryPrimary {
+		f.log.Info("Retrying the primary address")
+
+		f.active = 0
+	}
+
+	if !isHealthy(f.beacons[f.active]) {
+		if next, ok := f.nextHealthy(f.active); ok {
+			f.switchTo(next, "active address is unhealthy")
+		}
+	}
+
+	return f.beacons[f.active], f.active
+}
+
+// failed fails over to the next healthy address if the request to the given address failed to connect. If no other
+// address is healthy the active address is kept.
+func (f *failoverBeacon) failed(index int, err error) {
+	if !isConnectionError(err) {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Another request may have already failed over.
+	if f.active != index {
+		return
+	}
+
+	next, ok := f.nextHealthy(index)
+	if !ok {
+		f.log.WithError(err).Warn("Failed to connect to the active address and no other address is healthy")
+
+		return
+	}
+
+	f.switchTo(next, err.Error())
+}
+
+// nextHealthy returns the first healthy address after the given one, wrapping around to the primary.
+func (f *failoverBeacon) nextHealthy(index int) (int, bool) {
+	for i := 1; i < len(f.beacons); i++ {
+		next := (index + i) % len(f.beacons)
+
+		if isHealthy(f.beacons[next]) {
+			return next, true
+		}
+	}
+
+	return 0, false
+}
+
+func isHealthy(beacon sbeacon.Node) bool {
+	status := beacon.Status()
+
+	return status != nil && status.Healthy()
+}
+
+func (f *failoverBeacon) switchTo(index int, reason string) {
+	f.log.WithFields(logrus.Fields{
+		"from":   addressLabel(f.active),
+		"to":     addressLabel(index),
+		"reason": reason,
+	}).Warn("Failing over to another address")
+
+	f.active = index
+	f.failedOverAt = time.Now()
+}
+
+// ActiveAddress describes which of the addresses requests are going to.
+func (f *failoverBeacon) ActiveAddress() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return addressLabel(f.active)
+}
+
+// addressLabel names an address without revealing it, as it may contain credentials.
+func addressLabel(index int) string {
+	if index == 0 {
+		return "primary"
+	}
+
+	return fmt.Sprintf("fallback-%d", index)
+}
+
+// connectionErrorMessages are the messages of the errors the net package returns when it can't connect.
+var connectionErrorMessages = []string{
+	"connection refused",
+	"connection reset by peer",
+	"no such host",
+	"network is unreachable",
+}
+
+// isConnectionError returns true if the error is from failing to connect to the node, rather than the node
+// responding with an error.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	// Not every library between the HTTP client and here wraps errors with %w, so fall back to the message.
+	msg := err.Error()
+
+	for _, connectionErr := range connectionErrorMessages {
+		if strings.Contains(msg, connectionErr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StartAsync starts every address so they are all health checked.
+func (f *failoverBeacon) StartAsync(ctx context.Context) {
+	for _, beacon := range f.beacons {
+		beacon.StartAsync(ctx)
+	}
+}
+
+// Stop stops every address.
+func (f *failoverBeacon) Stop(ctx context.Context) error {
+	var firstErr error
+
+	for _, beacon := range f.beacons {
+		if err := beacon.Stop(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (f *failoverBeacon) Status() *sbeacon.Status {
+	beacon, _ := f.current()
+
+	return beacon.Status()
+}
+
+func (f *failoverBeacon) Finality() (*v1.Finality, error) {
+	beacon, _ := f.current()
+
+	return beacon.Finality()
+}
+
+func (f *failoverBeacon) Spec() (*state.Spec, error) {
+	beacon, _ := f.current()
+
+	return beacon.Spec()
+}
+
+func (f *failoverBeacon) Genesis() (*v1.Genesis, error) {
+	beacon, _ := f.current()
+
+	return beacon.Genesis()
+}
+
+func (f *failoverBeacon) NodeVersion() (string, error) {
+	beacon, _ := f.current()
+
+	return beacon.NodeVersion()
+}
+
+func (f *failoverBeacon) FetchBlock(ctx context.Context, blockID string) (*spec.VersionedSignedBeaconBlock, error) {
+	beacon, index := f.current()
+
+	block, err := beacon.FetchBlock(ctx, blockID)
+	f.failed(index, err)
+
+	return block, err
+}
+
+func (f *failoverBeacon) FetchRawBeaconState(ctx context.Context, stateID, contentType string) ([]byte, error) {
+	beacon, index := f.current()
+
+	data, err := beacon.FetchRawBeaconState(ctx, stateID, contentType)
+	f.failed(index, err)
+
+	return data, err
+}
+
+func (f *failoverBeacon) FetchFinality(ctx context.Context, stateID string) (*v1.Finality, error) {
+	beacon, index := f.current()
+
+	finality, err := beacon.FetchFinality(ctx, stateID)
+	f.failed(index, err)
+
+	return finality, err
+}
+
+func (f *failoverBeacon) FetchDepositSnapshot(ctx context.Context) (*types.DepositSnapshot, error) {
+	beacon, index := f.current()
+
+	snapshot, err := beacon.FetchDepositSnapshot(ctx)
+	f.failed(index, err)
+
+	return snapshot, err
+}
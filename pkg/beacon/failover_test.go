@@ -0,0 +1,156 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	sbeacon "github.com/ethpandaops/beacon/pkg/beacon"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func TestFailoverBeacon(t *testing.T) {
+	ctx := context.Background()
+
+	healthy := newHealthyStatus()
+
+	primary := &fakeUpstream{status: healthy}
+	fallback := &fakeUpstream{status: healthy}
+
+	failover := newFailoverBeacon(logrus.New(), []sbeacon.Node{primary, fallback}, time.Hour)
+
+	if active := failover.ActiveAddress(); active != "primary" {
+		t.Fatalf("expected the primary to be active, got %s", active)
+	}
+
+	// A node that responds with an error is still reachable, so there's no failover.
+	primary.blockErr = errors.New("block not found")
+
+	if _, err := failover.FetchBlock(ctx, "head"); err == nil {
+		t.Fatal("expected the block request to fail")
+	}
+
+	if active := failover.ActiveAddress(); active != "primary" {
+		t.Fatalf("expected the primary to stay active, got %s", active)
+	}
+
+	primary.blockErr = &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+
+	if _, err := failover.FetchBlock(ctx, "head"); err == nil {
+		t.Fatal("expected the block request to fail")
+	}
+
+	if active := failover.ActiveAddress(); active != "fallback-1" {
+		t.Fatalf("expected to fail over after a connection error, got %s", active)
+	}
+
+	if _, err := failover.FetchBlock(ctx, "head"); err != nil {
+		t.Fatalf("expected the fallback to serve the block, got %v", err)
+	}
+
+	// The primary is retried once it has been failed over for long enough.
+	failover.failedOverAt = time.Now().Add(-2 * time.Hour)
+
+	failover.Status()
+
+	if active := failover.ActiveAddress(); active != "primary" {
+		t.Fatalf("expected the primary to be retried, got %s", active)
+	}
+
+	// An unhealthy active address fails over to a healthy one.
+	primary.status = sbeacon.NewStatus(1, 1)
+
+	if status := failover.Status(); !status.Healthy() {
+		t.Error("expected the status of the healthy fallback")
+	}
+
+	if active := failover.ActiveAddress(); active != "fallback-1" {
+		t.Errorf("expected to fail over from an unhealthy primary, got %s", active)
+	}
+}
+
+func TestFailoverSkipsUnhealthyAddresses(t *testing.T) {
+	ctx := context.Background()
+
+	refused := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+
+	primary := &fakeUpstream{status: newHealthyStatus(), blockErr: refused}
+	unhealthy := &fakeUpstream{status: sbeacon.NewStatus(1, 1)}
+	healthy := &fakeUpstream{status: sbeacon.NewStatus(1, 1)}
+
+	failover := newFailoverBeacon(logrus.New(), []sbeacon.Node{primary, unhealthy, healthy}, time.Hour)
+
+	// With no other address healthy there's nothing better to fail over to.
+	if _, err := failover.FetchBlock(ctx, "head"); err == nil {
+		t.Fatal("expected the block request to fail")
+	}
+
+	if active := failover.ActiveAddress(); active != "primary" {
+		t.Fatalf("expected the primary to stay active without a healthy fallback, got %s", active)
+	}
+
+	healthy.status.Health().RecordSuccess()
+
+	if _, err := failover.FetchBlock(ctx, "head"); err == nil {
+		t.Fatal("expected the block request to fail")
+	}
+
+	if active := failover.ActiveAddress(); active != "fallback-2" {
+		t.Errorf("expected to fail over to the healthy fallback, got %s", active)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing listens on the address once it's closed, so connecting to it is refused.
+	address := listener.Addr().String()
+
+	if err := listener.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+address, http.NoBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsp, refused := http.DefaultClient.Do(req)
+	if refused == nil {
+		rsp.Body.Close()
+
+		t.Fatal("expected connecting to a closed port to fail")
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "no error", err: nil, expected: false},
+		{name: "refused", err: refused, expected: true},
+		// go-eth2-client wraps errors with github.com/pkg/errors.
+		{name: "refused wrapped by go-eth2-client", err: pkgerrors.Wrap(refused, "failed to call GET endpoint"), expected: true},
+		{name: "refused wrapped with %w", err: fmt.Errorf("failed to fetch block: %w", refused), expected: true},
+		{name: "refused wrapped with %v", err: fmt.Errorf("failed to fetch block: %v", refused), expected: true},
+		{name: "error response", err: errors.New("GET failed with status 404: block not found"), expected: false},
+		{name: "timeout", err: fmt.Errorf("request timed out: %w", context.DeadlineExceeded), expected: false},
+		{name: "cancelled", err: fmt.Errorf("%w: %v", context.Canceled, refused), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isConnectionError(test.err); got != test.expected {
+				t.Errorf("isConnectionError(%v) = %v, want %v", test.err, got, test.expected)
+			}
+		})
+	}
+}
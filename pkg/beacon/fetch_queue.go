@@ -0,0 +1,117 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// BundleFetchQueue is the state of the bundle fetches queued by QueueBundleFetch.
+type BundleFetchQueue struct {
+	// Queued holds the queued fetches that haven't finished yet, oldest first.
+	Queued []QueuedBundleFetch `json:"queued"`
+	// InFlight is how many bundle downloads are in flight, including those started by the serving and historical loops.
+	InFlight int `json:"in_flight"`
+}
+
+// QueuedBundleFetch is a bundle fetch queued by QueueBundleFetch.
+type QueuedBundleFetch struct {
+	Root     string    `json:"root"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// QueueBundleFetch starts downloading the bundle for the given finalized block root in the background, e.g. so an
+// operator can recover a historical checkpoint that the historical loop has given up on. An upstream is asked to
+// confirm the root is a finalized checkpoint first, returning ErrNotFinalizedCheckpoint if it isn't.
+func (d *Default) QueueBundleFetch(ctx context.Context, root phase0.Root) (*BundleFetchQueue, error) {
+	if d.bundleCached(root) {
+		return nil, ErrBundleAlreadyCached
+	}
+
+	if d.bundleFetchQueued(root) {
+		return nil, ErrBundleAlreadyQueued
+	}
+
+	upstreams := d.dataProviders(ctx)
+
+	slot, _, err := d.verifyFinalizedCheckpoint(ctx, root, upstreams)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt, ok := d.blockExpiration(ctx, slot); !ok {
+		return nil, fmt.Errorf("%w: slot %d expired at %s", ErrBlockExpired, slot, expiresAt)
+	}
+
+	d.fetchQueueMu.Lock()
+
+	if _, exists := d.fetchQueue[root]; exists || d.bundleDownloads.InFlight(root) {
+		d.fetchQueueMu.Unlock()
+
+		return nil, ErrBundleAlreadyQueued
+	}
+
+	d.fetchQueue[root] = time.Now()
+	d.metrics.ObserveBundleFetchQueueSize(len(d.fetchQueue))
+
+	d.fetchQueueMu.Unlock()
+
+	go func() {
+		// The request that queued the fetch doesn't wait for it, so it can't be cancelled by the request's context.
+		_, err := d.fetchBundleWithFallback(context.Background(), root, upstreams)
+
+		d.fetchQueueMu.Lock()
+		delete(d.fetchQueue, root)
+		d.metrics.ObserveBundleFetchQueueSize(len(d.fetchQueue))
+		d.fetchQueueMu.Unlock()
+
+		log := d.log.WithField("root", eth.RootAsString(root))
+
+		if err != nil {
+			log.WithError(err).Error("Failed to fetch queued bundle")
+
+			return
+		}
+
+		log.Info("Fetched queued bundle")
+	}()
+
+	return d.bundleFetchQueue(), nil
+}
+
+// bundleFetchQueued returns true if a fetch of root is queued or in flight.
+func (d *Default) bundleFetchQueued(root phase0.Root) bool {
+	d.fetchQueueMu.Lock()
+	defer d.fetchQueueMu.Unlock()
+
+	_, exists := d.fetchQueue[root]
+
+	return exists || d.bundleDownloads.InFlight(root)
+}
+
+func (d *Default) bundleFetchQueue() *BundleFetchQueue {
+	d.fetchQueueMu.Lock()
+	defer d.fetchQueueMu.Unlock()
+
+	queue := &BundleFetchQueue{
+		Queued:   make([]QueuedBundleFetch, 0, len(d.fetchQueue)),
+		InFlight: d.bundleDownloads.Len(),
+	}
+
+	for root, queuedAt := range d.fetchQueue {
+		queue.Queued = append(queue.Queued, QueuedBundleFetch{
+			Root:     eth.RootAsString(root),
+			QueuedAt: queuedAt,
+		})
+	}
+
+	sort.Slice(queue.Queued, func(i, j int) bool {
+		return queue.Queued[i].QueuedAt.Before(queue.Queued[j].QueuedAt)
+	})
+
+	return queue
+}
@@ -0,0 +1,196 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+func TestQueueBundleFetch(t *testing.T) {
+	ctx := context.Background()
+
+	block := newTestPhase0Block(phase0.Root{0x01})
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// newTestQueueProvider returns a provider whose upstream confirms the block is the finalized checkpoint at epoch 2.
+	newTestQueueProvider := func(namespace string) (*Default, *fakeUpstream) {
+		d := newTestDownloadProvider(namespace)
+		d.head = finalizedAt(10, 0x02)
+
+		upstream := &fakeUpstream{status: newHealthyStatus(), block: block}
+		d.nodes = Nodes{newTestNode("a", upstream)}
+
+		return d, upstream
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		d, _ := newTestQueueProvider("test_fetch_queue_cached")
+
+		if err := d.blocks.Add(block, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+
+		// The block alone isn't the whole bundle in full mode.
+		if _, err := d.QueueBundleFetch(ctx, root); err != nil {
+			t.Fatalf("expected the fetch to be queued without the state, got %v", err)
+		}
+
+		data := []byte{0x01}
+		if err := d.states.Add(phase0.Root{0x01}, &data, time.Now().Add(time.Hour), 64); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := d.QueueBundleFetch(ctx, root); !errors.Is(err, ErrBundleAlreadyCached) {
+			t.Fatalf("expected %v, got %v", ErrBundleAlreadyCached, err)
+		}
+	})
+
+	t.Run("already downloading", func(t *testing.T) {
+		d := newTestDownloadProvider("test_fetch_queue_in_flight")
+
+		release := make(chan struct{})
+		defer close(release)
+
+		go func() {
+			_, _ = d.bundleDownloads.Do(ctx, root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+				<-release
+
+				return nil, errors.New("released")
+			})
+		}()
+
+		for !d.bundleDownloads.InFlight(root) {
+			time.Sleep(time.Millisecond)
+		}
+
+		if _, err := d.QueueBundleFetch(ctx, root); !errors.Is(err, ErrBundleAlreadyQueued) {
+			t.Fatalf("expected %v, got %v", ErrBundleAlreadyQueued, err)
+		}
+	})
+
+	t.Run("already queued", func(t *testing.T) {
+		d := newTestDownloadProvider("test_fetch_queue_queued")
+
+		d.fetchQueue[root] = time.Now()
+
+		if _, err := d.QueueBundleFetch(ctx, root); !errors.Is(err, ErrBundleAlreadyQueued) {
+			t.Fatalf("expected %v, got %v", ErrBundleAlreadyQueued, err)
+		}
+	})
+
+	t.Run("leaves the queue when finished", func(t *testing.T) {
+		d, upstream := newTestQueueProvider("test_fetch_queue_finished")
+
+		// Without a state the fetch fails once the state request returns.
+		upstream.stateDelay = 100 * time.Millisecond
+
+		queue, err := d.QueueBundleFetch(ctx, root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(queue.Queued) != 1 || queue.Queued[0].Root != eth.RootAsString(root) {
+			t.Fatalf("expected the root to be queued, got %+v", queue.Queued)
+		}
+
+		if size := gaugeValue(t, d.metrics.bundleFetchQueueSize); size != 1 {
+			t.Errorf("expected the queue size metric to be 1, got %v", size)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for len(d.bundleFetchQueue().Queued) != 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("expected the fetch to leave the queue once finished")
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		if size := gaugeValue(t, d.metrics.bundleFetchQueueSize); size != 0 {
+			t.Errorf("expected the queue size metric to be 0 once the fetch finished, got %v", size)
+		}
+	})
+
+	t.Run("not a finalized checkpoint", func(t *testing.T) {
+		later := newTestPhase0Block(phase0.Root{0x01})
+		later.Phase0.Message.Slot = 11 * 32
+
+		laterRoot, err := later.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		unaligned := newTestPhase0Block(phase0.Root{0x01})
+		unaligned.Phase0.Message.Slot = 65
+
+		unalignedRoot, err := unaligned.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name   string
+			root   phase0.Root
+			blocks map[string]*spec.VersionedSignedBeaconBlock
+		}{
+			{
+				name:   "unknown",
+				root:   root,
+				blocks: map[string]*spec.VersionedSignedBeaconBlock{eth.RootAsString(root): nil},
+			},
+			{
+				name:   "not canonical",
+				root:   root,
+				blocks: map[string]*spec.VersionedSignedBeaconBlock{"64": newTestPhase0Block(phase0.Root{0x03})},
+			},
+			{
+				name:   "after the finalized head",
+				root:   laterRoot,
+				blocks: map[string]*spec.VersionedSignedBeaconBlock{eth.RootAsString(laterRoot): later},
+			},
+			{
+				name: "followed by a block in its epoch",
+				root: unalignedRoot,
+				blocks: map[string]*spec.VersionedSignedBeaconBlock{
+					eth.RootAsString(unalignedRoot): unaligned,
+					"65":                            unaligned,
+				},
+			},
+		}
+
+		for i, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				d, upstream := newTestQueueProvider(fmt.Sprintf("test_fetch_queue_not_finalized_%d", i))
+				upstream.blocks = test.blocks
+
+				if _, err := d.QueueBundleFetch(ctx, test.root); !errors.Is(err, ErrNotFinalizedCheckpoint) {
+					t.Fatalf("expected %v, got %v", ErrNotFinalizedCheckpoint, err)
+				}
+
+				if queued := d.bundleFetchQueue().Queued; len(queued) != 0 {
+					t.Errorf("expected nothing to be queued, got %+v", queued)
+				}
+			})
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		d, _ := newTestQueueProvider("test_fetch_queue_expired")
+		// Slot 64 was more than the hour of block retention ago.
+		d.genesis.GenesisTime = time.Now().Add(-2 * time.Hour)
+
+		if _, err := d.QueueBundleFetch(ctx, root); !errors.Is(err, ErrBlockExpired) {
+			t.Fatalf("expected %v, got %v", ErrBlockExpired, err)
+		}
+	})
+}
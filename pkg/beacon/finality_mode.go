@@ -0,0 +1,10 @@
+package beacon
+
+type FinalityMode string
+
+const (
+	// FinalityModeMajority takes finality from the majority of ready upstreams.
+	FinalityModeMajority FinalityMode = "majority"
+	// FinalityModeSingleTrusted takes finality from a single designated upstream.
+	FinalityModeSingleTrusted FinalityMode = "single-trusted"
+)
@@ -2,45 +2,100 @@ package beacon
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 )
 
+var (
+	// ErrFinalityNotFound is returned when no finalized checkpoint is known for the requested epoch.
+	ErrFinalityNotFound = errors.New("finality not found")
+	// ErrFinalityStalled is returned when the upstreams have not agreed on finality for too long to keep serving.
+	ErrFinalityStalled = errors.New("finality stalled")
+	// ErrNotFinalizedCheckpoint is returned when a block root isn't a finalized checkpoint on the canonical chain.
+	ErrNotFinalizedCheckpoint = errors.New("not a finalized checkpoint")
+	// ErrBlockExpired is returned when a downloaded block or state isn't stored because it has already expired.
+	ErrBlockExpired = errors.New("block has already expired")
+	// ErrFinalityTooOld is returned when an upstream's finality is more than max_finality_age epochs behind the wall clock.
+	ErrFinalityTooOld = errors.New("finality is too far behind the wall clock")
+	// ErrSpecNotAvailable is returned when no upstream has provided the chain spec yet.
+	ErrSpecNotAvailable = errors.New("config spec not yet available")
+	// ErrGenesisNotAvailable is returned when no upstream has provided the chain genesis yet.
+	ErrGenesisNotAvailable = errors.New("genesis not yet available")
+	// ErrJustifiedNotAvailable is returned when the justified checkpoint's bundle hasn't been downloaded yet.
+	ErrJustifiedNotAvailable = errors.New("justified checkpoint not yet available")
+	// ErrNoUpstreams is returned when reloading the upstreams with an empty list.
+	ErrNoUpstreams = errors.New("at least one upstream is required")
+	// ErrBundleAlreadyCached is returned when queueing a fetch of a bundle that is already cached.
+	ErrBundleAlreadyCached = errors.New("bundle is already cached")
+	// ErrBundleAlreadyQueued is returned when queueing a fetch of a bundle that is already being downloaded.
+	ErrBundleAlreadyQueued = errors.New("bundle is already queued for download")
+)
+
 // FinalityProvider is a provider of finality information.
 type FinalityProvider interface {
 	// Start starts the provider.
 	Start(ctx context.Context) error
 	// StartAsync starts the provider in a goroutine.
 	StartAsync(ctx context.Context)
+	// Stop stops the provider, waiting for in-flight downloads and persisting its caches if enabled.
+	Stop(ctx context.Context) error
 	// Healthy returns true if the provider is healthy.
 	Healthy(ctx context.Context) (bool, error)
+	// Ready returns true once the serving checkpoint bundle is cached and can be served.
+	Ready(ctx context.Context) (bool, error)
 	// Peers returns the peers the provider is connected to).
 	Peers(ctx context.Context) (types.Peers, error)
 	// PeerCount returns the amount of peers the provider is connected to (the amount of healthy upstreams).
 	PeerCount(ctx context.Context) (uint64, error)
 	// Syncing returns the sync state of the provider.
 	Syncing(ctx context.Context) (*v1.SyncState, error)
+	// LastFinalityQuorum returns when the upstreams last agreed on finality. Zero if they never have.
+	LastFinalityQuorum(ctx context.Context) time.Time
 	// Head returns the head finality.
 	Head(ctx context.Context) (*v1.Finality, error)
+	// FinalityStalled returns ErrFinalityStalled if the upstreams have not agreed on finality for too long.
+	FinalityStalled(ctx context.Context) error
+	// CacheContents lists the blocks and states currently held in the stores.
+	CacheContents(ctx context.Context) *CacheContents
+	// Checkpoints returns the finalized checkpoints whose block and state are both cached, newest first.
+	Checkpoints(ctx context.Context) []Checkpoint
+	// ServingSince returns when the bundle being served was first served. Zero if nothing has been served.
+	ServingSince(ctx context.Context) time.Time
+	// ServedBundles returns the most recently served finalized bundles, newest first.
+	ServedBundles(ctx context.Context) []*v1.Finality
+	// Justified returns the justified checkpoint whose bundle is being served.
+	Justified(ctx context.Context) (*phase0.Checkpoint, error)
 	// Finalized returns the finalized finality.
 	Finalized(ctx context.Context) (*v1.Finality, error)
 	// Genesis returns the chain genesis.
 	Genesis(ctx context.Context) (*v1.Genesis, error)
 	// Spec returns the chain spec.
 	Spec(ctx context.Context) (*state.Spec, error)
+	// ForkAtSlot returns the name of the fork active at the given slot, derived from the spec's fork schedule.
+	ForkAtSlot(ctx context.Context, slot phase0.Slot) (string, error)
 	// UpstreamsStatus returns the status of all the upstreams.
 	UpstreamsStatus(ctx context.Context) (map[string]*UpstreamStatus, error)
 	// GetBlockBySlot returns the block at the given slot.
 	GetBlockBySlot(ctx context.Context, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error)
 	// GetBlockByRoot returns the block with the given root.
 	GetBlockByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error)
+	// GetBlockSSZ returns the SSZ encoding of the block with the given root, or store.ErrBlockNotFound if the block
+	// isn't stored.
+	GetBlockSSZ(ctx context.Context, root phase0.Root) ([]byte, error)
 	// GetBlockByStateRoot returns the block with the given root.
 	GetBlockByStateRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error)
+	// GetBlockByParentRoot returns the child of the block with the given root, so the cached chain can be walked.
+	GetBlockByParentRoot(ctx context.Context, parentRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error)
+	// GetFinalizedBlock returns the block of the finalized checkpoint being served.
+	GetFinalizedBlock(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error)
 	// GetBeaconStateBySlot returns the beacon sate with the given slot.
 	GetBeaconStateBySlot(ctx context.Context, slot phase0.Slot) (*[]byte, error)
 	// GetBeaconStateByStateRoot returns the beacon sate with the given state root.
@@ -51,10 +106,31 @@ type FinalityProvider interface {
 	ListFinalizedSlots(ctx context.Context) ([]phase0.Slot, error)
 	// GetEpochBySlot returns the epoch for the given slot.
 	GetEpochBySlot(ctx context.Context, slot phase0.Slot) (phase0.Epoch, error)
+	// GetFinalityByEpoch returns the finalized checkpoint at the given epoch if its block is cached, including when
+	// the epoch's first slot was missed.
+	GetFinalityByEpoch(ctx context.Context, epoch phase0.Epoch) (*v1.Finality, error)
+	// RecheckFinality checks the upstreams for finality immediately, returning the resulting head.
+	RecheckFinality(ctx context.Context) (*v1.Finality, error)
+	// QueueBundleFetch starts downloading the bundle for the given finalized block root in the background, returning
+	// the queued fetches. Returns ErrNotFinalizedCheckpoint if an upstream doesn't confirm the root is a finalized
+	// checkpoint.
+	QueueBundleFetch(ctx context.Context, root phase0.Root) (*BundleFetchQueue, error)
+	// Pin serves the finalized checkpoint with the given block root instead of following the head.
+	Pin(ctx context.Context, root phase0.Root) error
+	// Unpin resumes serving the finalized head.
+	Unpin(ctx context.Context)
+	// Pinned returns the pinned checkpoint, or nil if nothing is pinned.
+	Pinned(ctx context.Context) *v1.Finality
 	// OperatingMode returns the mode of operation for the instance.
 	OperatingMode() OperatingMode
 	// GetSlotTime returns the wall clock for the given slot.
 	GetSlotTime(ctx context.Context, slot phase0.Slot) (eth.SlotTime, error)
 	// GetDepositSnapshot returns the deposit snapshot at the given epoch.
 	GetDepositSnapshot(ctx context.Context, epoch phase0.Epoch) (*types.DepositSnapshot, error)
+	// ReloadUpstreams replaces the upstreams, keeping those whose config is unchanged running.
+	ReloadUpstreams(ctx context.Context, configs []node.Config) error
+	// GetHeadBlock returns the unfinalized head block, if head caching is enabled.
+	GetHeadBlock(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error)
+	// GetHeadBeaconState returns the unfinalized head state, if head caching is enabled.
+	GetHeadBeaconState(ctx context.Context) (*[]byte, error)
 }
@@ -0,0 +1,105 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/checkpoints/majority"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// HasFinalized returns true if the finality contains a real finalized checkpoint. Before a network first
+// finalizes, nodes report a finalized checkpoint at epoch 0 with a zero root.
+func HasFinalized(finality *v1.Finality) bool {
+	if finality == nil || finality.Finalized == nil {
+		return false
+	}
+
+	return finality.Finalized.Epoch != 0 && finality.Finalized.Root != phase0.Root{}
+}
+
+// verifyFinalizedCheckpoint checks with an upstream that root is the checkpoint block of an epoch on the canonical
+// chain, at or before the finalized head the upstreams agreed on, returning its slot and the epoch it is the
+// checkpoint of. The checkpoint block is the block at the epoch's first slot or, if that slot was missed, the latest
+// block before it.
+func (d *Default) verifyFinalizedCheckpoint(ctx context.Context, root phase0.Root, upstreams Nodes) (phase0.Slot, phase0.Epoch, error) {
+	head := d.finalityHead()
+	if !HasFinalized(head) {
+		return 0, 0, fmt.Errorf("%w: the finalized head isn't known yet", ErrNotFinalizedCheckpoint)
+	}
+
+	upstream, err := upstreams.RandomNode(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	block, err := upstream.FetchBlock(ctx, eth.RootAsString(root))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch block from upstream %s: %w", upstream.Config.Name, err)
+	}
+
+	if block == nil {
+		return 0, 0, fmt.Errorf("%w: upstream %s doesn't know the block", ErrNotFinalizedCheckpoint, upstream.Config.Name)
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	slotsPerEpoch := d.slotsPerEpoch(ctx)
+
+	epoch := phase0.Epoch((slot + slotsPerEpoch - 1) / slotsPerEpoch)
+	if epoch > head.Finalized.Epoch {
+		return 0, 0, fmt.Errorf("%w: epoch %d is after the finalized epoch %d", ErrNotFinalizedCheckpoint, epoch, head.Finalized.Epoch)
+	}
+
+	canonical, err := upstream.FetchBlock(ctx, eth.SlotAsString(slot))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to fetch block from upstream %s: %w", upstream.Config.Name, err)
+	}
+
+	if canonical == nil {
+		return 0, 0, fmt.Errorf("%w: upstream %s has no canonical block at slot %d", ErrNotFinalizedCheckpoint, upstream.Config.Name, slot)
+	}
+
+	canonicalRoot, err := canonical.Root()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if canonicalRoot != root {
+		return 0, 0, fmt.Errorf("%w: the canonical block at slot %d is %s", ErrNotFinalizedCheckpoint, slot, eth.RootAsString(canonicalRoot))
+	}
+
+	// A block before the epoch's first slot is only its checkpoint if every slot up to the first was missed.
+	for later := slot + 1; later <= phase0.Slot(epoch)*slotsPerEpoch; later++ {
+		next, err := upstream.FetchBlock(ctx, eth.SlotAsString(later))
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to fetch block from upstream %s: %w", upstream.Config.Name, err)
+		}
+
+		if next != nil {
+			return 0, 0, fmt.Errorf("%w: slot %d is not an epoch boundary and is followed by a block at slot %d", ErrNotFinalizedCheckpoint, slot, later)
+		}
+	}
+
+	return slot, epoch, nil
+}
+
+// DistinctFinalizedRoots returns how many different finalized roots the votes are for.
+func DistinctFinalizedRoots(votes []majority.Vote) int {
+	roots := make(map[phase0.Root]struct{})
+
+	for _, vote := range votes {
+		if vote.Finality == nil || vote.Finality.Finalized == nil {
+			continue
+		}
+
+		roots[vote.Finality.Finalized.Root] = struct{}{}
+	}
+
+	return len(roots)
+}
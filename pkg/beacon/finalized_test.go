@@ -0,0 +1,102 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/checkpoints/majority"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+)
+
+func TestHasFinalized(t *testing.T) {
+	tests := []struct {
+		name     string
+		finality *v1.Finality
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"no finalized checkpoint", &v1.Finality{}, false},
+		{"zero root at epoch 0", unfinalized(), false},
+		{"zero root", &v1.Finality{Finalized: &phase0.Checkpoint{Epoch: 10}}, false},
+		{"epoch 0", &v1.Finality{Finalized: &phase0.Checkpoint{Root: phase0.Root{0x01}}}, false},
+		{"finalized", finalizedAt(10, 0x01), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := HasFinalized(test.finality); got != test.expected {
+				t.Errorf("HasFinalized() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnfinalizedVotesCountTowardsQuorum(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		votes     []*v1.Finality
+		epoch     phase0.Epoch
+	}{
+		{"all unfinalized", 0.5, []*v1.Finality{unfinalized(), unfinalized(), unfinalized()}, 0},
+		{"unfinalized majority", 0.5, []*v1.Finality{unfinalized(), unfinalized(), finalizedAt(10, 0x01)}, 0},
+		{"finalized majority", 0.5, []*v1.Finality{unfinalized(), finalizedAt(10, 0x01), finalizedAt(10, 0x01)}, 10},
+		{"finalized short of the threshold", 0.7, []*v1.Finality{unfinalized(), finalizedAt(10, 0x01), finalizedAt(10, 0x01)}, 0},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := newTestDownloadProvider(fmt.Sprintf("test_unfinalized_votes_%d", i))
+			d.config.MinFinalityAgreement = test.threshold
+
+			for j, finality := range test.votes {
+				upstream := newHealthyTestNode(fmt.Sprintf("%d", j), finality)
+				upstream.FinalityBackoff = node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax)
+				upstream.FinalityLogSampler = node.NewSampler(node.DefaultSampleInterval)
+
+				d.nodes = append(d.nodes, upstream)
+			}
+
+			if err := d.checkFinality(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.epoch == 0 {
+				if d.head != nil {
+					t.Errorf("expected no head, got %v", d.head)
+				}
+
+				return
+			}
+
+			if d.head == nil || d.head.Finalized.Epoch != test.epoch {
+				t.Errorf("expected the head at epoch %d, got %v", test.epoch, d.head)
+			}
+		})
+	}
+}
+
+func TestDistinctFinalizedRoots(t *testing.T) {
+	tests := []struct {
+		name     string
+		votes    []majority.Vote
+		expected int
+	}{
+		{"no votes", nil, 0},
+		{"agreement", []majority.Vote{{Finality: finalizedAt(10, 0x01)}, {Finality: finalizedAt(10, 0x01)}}, 1},
+		{"split", []majority.Vote{{Finality: finalizedAt(10, 0x01)}, {Finality: finalizedAt(10, 0x02)}, {Finality: finalizedAt(10, 0x01)}}, 2},
+		{"different epochs", []majority.Vote{{Finality: finalizedAt(10, 0x01)}, {Finality: finalizedAt(11, 0x02)}, {Finality: finalizedAt(12, 0x03)}}, 3},
+		{"missing finality", []majority.Vote{{Finality: nil}, {Finality: finalizedAt(10, 0x01)}}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DistinctFinalizedRoots(test.votes); got != test.expected {
+				t.Errorf("DistinctFinalizedRoots() = %v, want %v", got, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+)
+
+// forkScheduleKey describes the spec's fork schedule in a form that can be compared between upstreams.
+func forkScheduleKey(sp *state.Spec) string {
+	forks := make([]string, 0, len(sp.ForkEpochs))
+
+	for _, fork := range sp.ForkEpochs {
+		forks = append(forks, fmt.Sprintf("%s@%d(%s)", fork.Name, fork.Epoch, fork.Version))
+	}
+
+	sort.Strings(forks)
+
+	return strings.Join(forks, ",")
+}
+
+// checkForkSchedules compares the fork schedule of each ready upstream with the schedule most of them report, and
+// records the upstreams that differ, e.g. because they haven't been upgraded for an upcoming fork. Nothing is
+// recorded if no schedule is reported by more upstreams than any other.
+func (d *Default) checkForkSchedules(ctx context.Context) {
+	schedules := make(map[string]string)
+	counts := make(map[string]int)
+
+	for _, upstream := range d.readyNodes(ctx) {
+		sp, err := upstream.Beacon.Spec()
+		if err != nil || sp == nil {
+			continue
+		}
+
+		key := forkScheduleKey(sp)
+
+		schedules[upstream.Config.Name] = key
+		counts[key]++
+	}
+
+	majority, best, tied := "", 0, false
+
+	for key, count := range counts {
+		switch {
+		case count > best:
+			majority, best, tied = key, count, false
+		case count == best:
+			tied = true
+		}
+	}
+
+	mismatches := make(map[string]error)
+
+	if !tied {
+		for name, key := range schedules {
+			if key != majority {
+				mismatches[name] = fmt.Errorf("upstream's fork schedule %s differs from the majority's %s", key, majority)
+			}
+		}
+	}
+
+	d.forkMismatchesMu.Lock()
+	previous := d.forkMismatches
+	d.forkMismatches = mismatches
+	d.forkMismatchesMu.Unlock()
+
+	for name, err := range mismatches {
+		if _, ok := previous[name]; !ok {
+			d.log.WithError(err).WithField("upstream", name).Warn("Upstream has a different fork schedule, not using it as a data provider")
+		}
+	}
+
+	for name := range previous {
+		if _, ok := mismatches[name]; !ok {
+			d.log.WithField("upstream", name).Info("Upstream's fork schedule matches the majority again")
+		}
+	}
+}
+
+// forkMismatchError returns an error if the node's fork schedule differs from the majority's.
+func (d *Default) forkMismatchError(node *Node) error {
+	d.forkMismatchesMu.RLock()
+	defer d.forkMismatchesMu.RUnlock()
+
+	return d.forkMismatches[node.Config.Name]
+}
+
+// dataProviders returns the ready data provider upstreams, excluding any whose fork schedule differs from the
+// majority's so data encoded for the wrong fork is never served.
+func (d *Default) dataProviders(ctx context.Context) Nodes {
+	return d.readyNodes(ctx).DataProviders(ctx).Filter(ctx, func(node *Node) bool {
+		return d.forkMismatchError(node) == nil
+	})
+}
@@ -0,0 +1,85 @@
+package beacon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+)
+
+func newTestForkSpec(capellaEpoch phase0.Epoch) *state.Spec {
+	return &state.Spec{
+		SlotsPerEpoch: 32,
+		ForkEpochs: state.ForkEpochs{
+			{Name: "bellatrix", Epoch: 100, Version: "0x02000000"},
+			{Name: "capella", Epoch: capellaEpoch, Version: "0x03000000"},
+		},
+	}
+}
+
+func TestForkScheduleMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_fork_schedule")
+
+	a := newHealthyTestNode("a", finalizedAt(100, 0x01))
+	b := newHealthyTestNode("b", finalizedAt(100, 0x01))
+	outdated := newHealthyTestNode("outdated", finalizedAt(100, 0x01))
+
+	a.Beacon.(*fakeUpstream).spec = newTestForkSpec(200)
+	b.Beacon.(*fakeUpstream).spec = newTestForkSpec(200)
+	outdated.Beacon.(*fakeUpstream).spec = newTestForkSpec(^phase0.Epoch(0))
+
+	d.nodes = Nodes{a, b, outdated}
+
+	d.checkForkSchedules(ctx)
+
+	if err := d.forkMismatchError(outdated); err == nil {
+		t.Error("expected the upstream with a different fork schedule to be flagged")
+	}
+
+	for _, upstream := range []*Node{a, b} {
+		if err := d.forkMismatchError(upstream); err != nil {
+			t.Errorf("expected %s to not be flagged, got %v", upstream.Config.Name, err)
+		}
+	}
+
+	if names := nodeNames(d.dataProviders(ctx)); len(names) != 2 {
+		t.Errorf("expected the flagged upstream to not be a data provider, got %v", names)
+	}
+
+	if names := nodeNames(d.readyNodes(ctx)); len(names) != 3 {
+		t.Errorf("expected the flagged upstream to still be ready, got %v", names)
+	}
+
+	outdated.Beacon.(*fakeUpstream).spec = newTestForkSpec(200)
+
+	d.checkForkSchedules(ctx)
+
+	if err := d.forkMismatchError(outdated); err != nil {
+		t.Errorf("expected the upstream to not be flagged once upgraded, got %v", err)
+	}
+}
+
+func TestForkScheduleWithoutMajority(t *testing.T) {
+	ctx := context.Background()
+
+	d := newTestDownloadProvider("test_fork_schedule_tie")
+
+	a := newHealthyTestNode("a", finalizedAt(100, 0x01))
+	b := newHealthyTestNode("b", finalizedAt(100, 0x01))
+
+	a.Beacon.(*fakeUpstream).spec = newTestForkSpec(200)
+	b.Beacon.(*fakeUpstream).spec = newTestForkSpec(300)
+
+	d.nodes = Nodes{a, b}
+
+	d.checkForkSchedules(ctx)
+
+	for _, upstream := range d.nodes {
+		if err := d.forkMismatchError(upstream); err != nil {
+			t.Errorf("expected no upstream to be flagged without a majority, got %v for %s", err, upstream.Config.Name)
+		}
+	}
+}
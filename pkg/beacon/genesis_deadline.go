@@ -0,0 +1,45 @@
+package beacon
+
+import (
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+)
+
+// ErrGenesisBundleUnavailable is returned for the genesis block and state once they couldn't be fetched within the
+// genesis_fetch_deadline, e.g. because the upstreams have pruned the genesis state. It matches store.ErrBlockNotFound
+// and store.ErrStateNotFound so callers treat it as not found.
+var ErrGenesisBundleUnavailable error = genesisBundleUnavailableError{}
+
+type genesisBundleUnavailableError struct{}
+
+func (genesisBundleUnavailableError) Error() string {
+	return "genesis state not available from upstreams"
+}
+
+func (genesisBundleUnavailableError) Is(target error) bool {
+	return target == store.ErrBlockNotFound || target == store.ErrStateNotFound
+}
+
+// genesisUnavailable reports whether fetching the genesis bundle has been given up on.
+func (d *Default) genesisUnavailable() bool {
+	d.genesisUnavailableMu.RLock()
+	defer d.genesisUnavailableMu.RUnlock()
+
+	return d.genesisGaveUp
+}
+
+// giveUpOnGenesis stops the genesis bundle being fetched, so the genesis block and state ids are reported as
+// unavailable rather than the fetch failing forever.
+func (d *Default) giveUpOnGenesis() {
+	d.genesisUnavailableMu.Lock()
+	defer d.genesisUnavailableMu.Unlock()
+
+	if d.genesisGaveUp {
+		return
+	}
+
+	d.genesisGaveUp = true
+
+	d.log.
+		WithField("deadline", d.config.GenesisFetchDeadline.String()).
+		Warn("Giving up on fetching the genesis bundle, the upstreams may have pruned the genesis state. Everything except the genesis block and state will be served")
+}
@@ -0,0 +1,139 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// headExpirySlots is how many slots a cached head is served for without being refreshed. The head moves every slot
+// so a stale head is worse than none.
+const headExpirySlots = 5
+
+// cachedHead is the most recently fetched head block and, in full mode, its state. It is kept outside of the block
+// and state stores so the unfinalized head never displaces finalized data or is served by slot.
+type cachedHead struct {
+	block     *spec.VersionedSignedBeaconBlock
+	state     *[]byte
+	fetchedAt time.Time
+}
+
+// startHeadLoop fetches the head once a slot.
+func (d *Default) startHeadLoop(ctx context.Context) error {
+	for {
+		if err := d.checkHead(ctx); err != nil {
+			d.log.WithError(err).Warn("Failed to cache head")
+		}
+
+		select {
+		case <-time.After(d.secondsPerSlot(ctx)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkHead fetches the head block and state from an upstream if it has changed since it was last fetched.
+func (d *Default) checkHead(ctx context.Context) error {
+	upstream, err := d.dataProviders(ctx).RandomNode(ctx)
+	if err != nil {
+		return errors.New("no data provider node available")
+	}
+
+	block, err := upstream.FetchBlock(ctx, "head")
+	if err != nil {
+		return fmt.Errorf("failed to fetch head block: %w", err)
+	}
+
+	if block == nil {
+		return errors.New("head block is nil")
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	d.cachedHeadMu.Lock()
+	if d.cachedHead != nil {
+		if cachedRoot, err := d.cachedHead.block.Root(); err == nil && cachedRoot == root {
+			d.cachedHead.fetchedAt = now
+			d.cachedHeadMu.Unlock()
+
+			return nil
+		}
+	}
+	d.cachedHeadMu.Unlock()
+
+	head := &cachedHead{block: block, fetchedAt: now}
+
+	if d.shouldDownloadStates() {
+		stateRoot, err := block.StateRoot()
+		if err != nil {
+			return err
+		}
+
+		beaconState, err := upstream.FetchRawBeaconState(ctx, eth.RootAsString(stateRoot), "application/octet-stream")
+		if err != nil {
+			return fmt.Errorf("failed to fetch head state: %w", err)
+		}
+
+		if err := VerifyStateRoot(block, beaconState); err != nil {
+			return err
+		}
+
+		head.state = &beaconState
+	}
+
+	d.cachedHeadMu.Lock()
+	d.cachedHead = head
+	d.cachedHeadMu.Unlock()
+
+	d.log.
+		WithField("root", eth.RootAsString(root)).
+		WithField("upstream", upstream.Config.Name).
+		Debug("Cached head")
+
+	return nil
+}
+
+// currentHead returns the cached head if it hasn't expired.
+func (d *Default) currentHead(ctx context.Context) *cachedHead {
+	expiry := d.secondsPerSlot(ctx) * headExpirySlots
+
+	d.cachedHeadMu.RLock()
+	defer d.cachedHeadMu.RUnlock()
+
+	if d.cachedHead == nil || time.Since(d.cachedHead.fetchedAt) > expiry {
+		return nil
+	}
+
+	return d.cachedHead
+}
+
+// GetHeadBlock returns the cached head block. CacheHead must be enabled.
+func (d *Default) GetHeadBlock(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+	head := d.currentHead(ctx)
+	if head == nil {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return head.block, nil
+}
+
+// GetHeadBeaconState returns the cached head state. CacheHead must be enabled and the instance in full mode.
+func (d *Default) GetHeadBeaconState(ctx context.Context) (*[]byte, error) {
+	head := d.currentHead(ctx)
+	if head == nil || head.state == nil {
+		return nil, store.ErrStateNotFound
+	}
+
+	return head.state, nil
+}
@@ -0,0 +1,84 @@
+package beacon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+)
+
+func TestCheckHead(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	d := newTestDownloadProvider("test_head")
+
+	if _, err := d.GetHeadBlock(ctx); !errors.Is(err, store.ErrBlockNotFound) {
+		t.Fatalf("expected %v before the head is cached, got %v", store.ErrBlockNotFound, err)
+	}
+
+	healthy := newHealthyStatus()
+
+	upstream := &fakeUpstream{block: block, state: data, status: healthy}
+
+	d.nodes = Nodes{
+		newTestNode("a", upstream),
+	}
+
+	if err := d.checkHead(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := d.GetHeadBlock(ctx); err != nil {
+		t.Errorf("expected the head block to be served, got %v", err)
+	}
+
+	headState, err := d.GetHeadBeaconState(ctx)
+	if err != nil {
+		t.Fatalf("expected the head state to be served, got %v", err)
+	}
+
+	if !bytes.Equal(*headState, data) {
+		t.Error("unexpected head state")
+	}
+
+	// The head is kept out of the stores so it is never served as finalized data.
+	if _, err := d.GetBlockBySlot(ctx, 64); err == nil {
+		t.Error("expected the head block to not be stored by slot")
+	}
+
+	// An unchanged head doesn't refetch the state.
+	if err := d.checkHead(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fetches := atomic.LoadInt32(&upstream.stateFetches); fetches != 1 {
+		t.Errorf("expected the head state to be fetched once, got %d", fetches)
+	}
+
+	// The head expires after a number of slots, so it expires sooner on a chain with shorter slots.
+	d.cachedHead.fetchedAt = time.Now().Add(-10 * time.Second)
+
+	if _, err := d.GetHeadBeaconState(ctx); err != nil {
+		t.Errorf("expected a head fetched within %d slots to be served, got %v", headExpirySlots, err)
+	}
+
+	d.spec.SecondsPerSlot = state.StringerDuration(time.Second)
+
+	if _, err := d.GetHeadBeaconState(ctx); !errors.Is(err, store.ErrStateNotFound) {
+		t.Errorf("expected a stale head to not be served, got %v", err)
+	}
+}
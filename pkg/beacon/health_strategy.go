@@ -0,0 +1,68 @@
+package beacon
+
+import (
+	"context"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+type HealthStrategyName string
+
+const (
+	// HealthStrategyLoose treats every healthy, non-syncing upstream as ready.
+	HealthStrategyLoose HealthStrategyName = "loose"
+	// HealthStrategyStrict also requires an upstream's finality to be close to the finalized head.
+	HealthStrategyStrict HealthStrategyName = "strict"
+)
+
+// StrictMaxFinalityLag is how many epochs an upstream's finality can be behind the head under the strict strategy.
+const StrictMaxFinalityLag = phase0.Epoch(2)
+
+// HealthStrategy decides which upstreams are ready to be used.
+type HealthStrategy interface {
+	// Ready returns the nodes that are ready, given the finalized head agreed on so far.
+	Ready(ctx context.Context, nodes Nodes, head *v1.Finality) Nodes
+}
+
+// NewHealthStrategy returns the built-in strategy with the given name, falling back to the loose strategy.
+func NewHealthStrategy(name HealthStrategyName) HealthStrategy {
+	switch name {
+	case HealthStrategyStrict:
+		return &StrictHealthStrategy{MaxFinalityLag: StrictMaxFinalityLag}
+	default:
+		return &LooseHealthStrategy{}
+	}
+}
+
+// LooseHealthStrategy treats every healthy, non-syncing upstream as ready.
+type LooseHealthStrategy struct{}
+
+func (s *LooseHealthStrategy) Ready(ctx context.Context, nodes Nodes, head *v1.Finality) Nodes {
+	return nodes.Ready(ctx)
+}
+
+// StrictHealthStrategy treats healthy, non-syncing upstreams as ready only if their finality is at most
+// MaxFinalityLag epochs behind the head. Until there is a head it behaves like the loose strategy.
+type StrictHealthStrategy struct {
+	MaxFinalityLag phase0.Epoch
+}
+
+func (s *StrictHealthStrategy) Ready(ctx context.Context, nodes Nodes, head *v1.Finality) Nodes {
+	nodes = nodes.Ready(ctx)
+
+	if head == nil || head.Finalized == nil {
+		return nodes
+	}
+
+	return nodes.Filter(ctx, func(node *Node) bool {
+		finality, err := node.Beacon.Finality()
+		if err != nil {
+			return false
+		}
+
+		lag, ok := FinalityLag(head, finality)
+
+		return ok && lag <= s.MaxFinalityLag
+	})
+}
@@ -0,0 +1,44 @@
+package beacon
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+)
+
+func TestHealthStrategies(t *testing.T) {
+	unhealthy := newHealthyTestNode("unhealthy", finalizedAt(100, 0x01))
+	unhealthy.Beacon.Status().Health().RecordFail(nil)
+
+	nodes := Nodes{
+		newHealthyTestNode("current", finalizedAt(100, 0x01)),
+		newHealthyTestNode("lagging", finalizedAt(98, 0x02)),
+		newHealthyTestNode("far behind", finalizedAt(97, 0x03)),
+		newHealthyTestNode("no finality", nil),
+		unhealthy,
+	}
+
+	tests := []struct {
+		name     string
+		strategy HealthStrategyName
+		head     *v1.Finality
+		expected []string
+	}{
+		{"loose", HealthStrategyLoose, finalizedAt(100, 0x01), []string{"current", "lagging", "far behind", "no finality"}},
+		{"strict", HealthStrategyStrict, finalizedAt(100, 0x01), []string{"current", "lagging"}},
+		{"strict without a head", HealthStrategyStrict, &v1.Finality{}, []string{"current", "lagging", "far behind", "no finality"}},
+		{"unknown falls back to loose", HealthStrategyName("unknown"), finalizedAt(100, 0x01), []string{"current", "lagging", "far behind", "no finality"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ready := NewHealthStrategy(test.strategy).Ready(context.Background(), nodes, test.head)
+
+			if got := nodeNames(ready); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
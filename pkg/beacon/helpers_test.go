@@ -0,0 +1,298 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/chuckpreslar/emission"
+	sbeacon "github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeUpstream serves fixed blocks, state and finality, recording what it was asked for.
+type fakeUpstream struct {
+	sbeacon.Node
+
+	block *spec.VersionedSignedBeaconBlock
+	// blocks are served instead of block for the block IDs they are keyed by.
+	blocks   map[string]*spec.VersionedSignedBeaconBlock
+	state    []byte
+	status   *sbeacon.Status
+	finality *v1.Finality
+	// hang makes state requests block until they are cancelled, like an upstream that stopped responding.
+	hang bool
+	// stateDelay makes state requests take at least this long, unless they are cancelled first.
+	stateDelay time.Duration
+	// noSnapshot makes deposit snapshot requests fail, like an upstream that doesn't support EIP-4881.
+	noSnapshot bool
+	// blockErr makes block requests fail with it.
+	blockErr error
+	// spec is the chain spec the upstream reports.
+	spec *state.Spec
+	// blockDelay makes block requests take at least this long, unless they are cancelled first.
+	blockDelay time.Duration
+	// version is the node version the upstream reports.
+	version string
+	// genesis is the genesis the upstream reports.
+	genesis *v1.Genesis
+
+	blockFetches    int32
+	stateFetches    int32
+	snapshotFetches int32
+	finalityFetches int32
+
+	// blockRequestsInFlight and maxBlockRequestsInFlight track how many block requests are made at once.
+	blockRequestsInFlight    int32
+	maxBlockRequestsInFlight int32
+}
+
+func (f *fakeUpstream) FetchBlock(ctx context.Context, blockID string) (*spec.VersionedSignedBeaconBlock, error) {
+	atomic.AddInt32(&f.blockFetches, 1)
+
+	inFlight := atomic.AddInt32(&f.blockRequestsInFlight, 1)
+	defer atomic.AddInt32(&f.blockRequestsInFlight, -1)
+
+	for {
+		highest := atomic.LoadInt32(&f.maxBlockRequestsInFlight)
+		if inFlight <= highest || atomic.CompareAndSwapInt32(&f.maxBlockRequestsInFlight, highest, inFlight) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(f.blockDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if f.blockErr != nil {
+		return nil, f.blockErr
+	}
+
+	if block, ok := f.blocks[blockID]; ok {
+		return block, nil
+	}
+
+	return f.block, nil
+}
+
+func (f *fakeUpstream) FetchRawBeaconState(ctx context.Context, stateID, contentType string) ([]byte, error) {
+	atomic.AddInt32(&f.stateFetches, 1)
+
+	if f.hang {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	select {
+	case <-time.After(f.stateDelay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return f.state, nil
+}
+
+func (f *fakeUpstream) Status() *sbeacon.Status {
+	return f.status
+}
+
+func (f *fakeUpstream) Finality() (*v1.Finality, error) {
+	if f.finality == nil {
+		return nil, errors.New("upstream is down")
+	}
+
+	return f.finality, nil
+}
+
+func (f *fakeUpstream) FetchFinality(ctx context.Context, stateID string) (*v1.Finality, error) {
+	atomic.AddInt32(&f.finalityFetches, 1)
+
+	return f.Finality()
+}
+
+func (f *fakeUpstream) Spec() (*state.Spec, error) {
+	if f.spec == nil {
+		return nil, errors.New("spec unknown")
+	}
+
+	return f.spec, nil
+}
+
+func (f *fakeUpstream) Genesis() (*v1.Genesis, error) {
+	if f.genesis == nil {
+		return nil, errors.New("genesis unknown")
+	}
+
+	return f.genesis, nil
+}
+
+func (f *fakeUpstream) NodeVersion() (string, error) {
+	return f.version, nil
+}
+
+func (f *fakeUpstream) FetchDepositSnapshot(ctx context.Context) (*types.DepositSnapshot, error) {
+	atomic.AddInt32(&f.snapshotFetches, 1)
+
+	if f.noSnapshot {
+		return nil, errors.New("not supported")
+	}
+
+	return &types.DepositSnapshot{}, nil
+}
+
+func newTestDownloadProvider(namespace string) *Default {
+	log := logrus.New()
+
+	return &Default{
+		log: log,
+		config: &Config{
+			Mode:                      OperatingModeFull,
+			MinFinalityAgreement:      0.5,
+			BundleDownloadMaxAttempts: 2,
+			BlockRetention:            time.Hour,
+		},
+		spec: &state.Spec{
+			SlotsPerEpoch:  32,
+			SecondsPerSlot: state.StringerDuration(12 * time.Second),
+		},
+		genesis: &v1.Genesis{
+			GenesisTime: time.Now().Add(-10 * time.Minute),
+		},
+		broker:                 emission.NewEmitter(),
+		healthStrategy:         NewHealthStrategy(HealthStrategyLoose),
+		historicalSlotFailures: make(map[phase0.Slot]int),
+		upstreamNames:          make(map[string]struct{}),
+		bundleDownloads:        newBundleDownloads(0, 0, log),
+		fetchQueue:             make(map[phase0.Root]time.Time),
+		blocks:                 store.NewBlock(log, store.Config{MaxItems: 10}, namespace),
+		states:                 store.NewBeaconState(log, store.Config{MaxItems: 10}, namespace),
+		depositSnapshots:       store.NewDepositSnapshot(log, store.Config{MaxItems: 10}, namespace),
+		metrics:                NewMetrics(namespace + "_beacon"),
+	}
+}
+
+// newTestNode returns an upstream node called name.
+func newTestNode(name string, upstream *fakeUpstream) *Node {
+	return &Node{
+		Config: node.Config{Name: name},
+		Beacon: upstream,
+	}
+}
+
+func newHealthyStatus() *sbeacon.Status {
+	status := sbeacon.NewStatus(1, 1)
+	status.Health().RecordSuccess()
+
+	return status
+}
+
+func newHealthyTestNode(name string, finality *v1.Finality) *Node {
+	return newTestNode(name, &fakeUpstream{status: newHealthyStatus(), finality: finality})
+}
+
+func nodeNames(nodes Nodes) []string {
+	names := []string{}
+
+	for _, n := range nodes {
+		names = append(names, n.Config.Name)
+	}
+
+	return names
+}
+
+func gaugeValue(t *testing.T, gauge prometheus.Gauge) float64 {
+	t.Helper()
+
+	metric := &dto.Metric{}
+	if err := gauge.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+
+	return metric.GetGauge().GetValue()
+}
+
+func unfinalized() *v1.Finality {
+	return &v1.Finality{
+		Finalized:         &phase0.Checkpoint{},
+		Justified:         &phase0.Checkpoint{},
+		PreviousJustified: &phase0.Checkpoint{},
+	}
+}
+
+func finalizedAt(epoch phase0.Epoch, root byte) *v1.Finality {
+	return &v1.Finality{
+		Finalized:         &phase0.Checkpoint{Epoch: epoch, Root: phase0.Root{root}},
+		Justified:         &phase0.Checkpoint{Epoch: epoch + 1, Root: phase0.Root{root}},
+		PreviousJustified: &phase0.Checkpoint{Epoch: epoch, Root: phase0.Root{root}},
+	}
+}
+
+func newTestPhase0State(t *testing.T) (*phase0.BeaconState, []byte) {
+	t.Helper()
+
+	state := beacontest.Phase0State(64)
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	return state, data
+}
+
+func newTestPhase0Block(stateRoot phase0.Root) *spec.VersionedSignedBeaconBlock {
+	return beacontest.Phase0Block(64, stateRoot)
+}
+
+// newTestAltairState returns a minimal altair state at slot 64.
+func newTestAltairState() *altair.BeaconState {
+	return beacontest.AltairState(64)
+}
+
+// newTestAltairStateSSZ returns a minimal altair state at slot 64 along with its SSZ encoding.
+func newTestAltairStateSSZ(t *testing.T) (*altair.BeaconState, []byte) {
+	t.Helper()
+
+	state := newTestAltairState()
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	return state, data
+}
+
+func newTestAltairBlock(stateRoot phase0.Root) *spec.VersionedSignedBeaconBlock {
+	return beacontest.AltairBlock(64, stateRoot)
+}
+
+// newTestPhase0BlockSSZ returns a block along with its SSZ encoding.
+func newTestPhase0BlockSSZ(t *testing.T, stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, []byte) {
+	t.Helper()
+
+	block := newTestPhase0Block(stateRoot)
+
+	data, err := block.Phase0.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return block, data
+}
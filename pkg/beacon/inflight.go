@@ -0,0 +1,253 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrBundleDownloadCancelled is returned when a bundle download is abandoned because its context was cancelled.
+var ErrBundleDownloadCancelled = errors.New("bundle download cancelled")
+
+// bundleDownload is a bundle download that is in progress.
+type bundleDownload struct {
+	// startedAt is when the download got a slot and started running. Zero while it waits for a slot.
+	startedAt time.Time
+	// cancel cancels the context the download runs with.
+	cancel context.CancelFunc
+
+	done  chan struct{}
+	block *spec.VersionedSignedBeaconBlock
+	err   error
+}
+
+// bundleDownloads collapses concurrent downloads of the same bundle into a single download, and bounds how
+// many different bundles are downloaded at once.
+type bundleDownloads struct {
+	mu       sync.Mutex
+	inFlight map[phase0.Root]*bundleDownload
+	wg       sync.WaitGroup
+	// slots limits how many downloads run at once. Nil if unlimited.
+	slots chan struct{}
+	// staleAfter is how long a download can be in flight before it is abandoned, so a download that never
+	// returns doesn't block downloads of its root forever. 0 never abandons downloads.
+	staleAfter time.Duration
+	// closed is set by Cancel to stop new downloads from starting.
+	closed bool
+
+	log logrus.FieldLogger
+}
+
+// newBundleDownloads returns a bundleDownloads that runs at most concurrency downloads at once. A concurrency
+// of 0 is unlimited.
+func newBundleDownloads(concurrency int, staleAfter time.Duration, log logrus.FieldLogger) *bundleDownloads {
+	b := &bundleDownloads{
+		inFlight:   make(map[phase0.Root]*bundleDownload),
+		staleAfter: staleAfter,
+		log:        log.WithField("component", "beacon/bundle_downloads"),
+	}
+
+	if concurrency > 0 {
+		b.slots = make(chan struct{}, concurrency)
+	}
+
+	return b
+}
+
+// Do runs fn to download the bundle for root, unless a download of that root is already in flight, in which
+// case it waits for and returns the result of the existing download. fn is not run until a download slot is free.
+// fn must stop promptly once its ctx is cancelled; Do then returns ErrBundleDownloadCancelled. A download that is
+// still running staleAfter after it got a slot is cancelled and abandoned, and the next caller starts a new one.
+func (b *bundleDownloads) Do(ctx context.Context, root phase0.Root, fn func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error)) (*spec.VersionedSignedBeaconBlock, error) {
+	for {
+		b.mu.Lock()
+
+		download, exists := b.inFlight[root]
+		if exists && b.stale(download) {
+			b.reap(root, download)
+
+			exists = false
+		}
+
+		if !exists {
+			break
+		}
+
+		staleTimer := b.staleTimer(download)
+
+		b.mu.Unlock()
+
+		select {
+		case <-download.done:
+		case <-ctx.Done():
+			return nil, cancelled(ctx.Err())
+		case <-staleTimer:
+			// Loop around to abandon it and start again.
+			continue
+		}
+
+		// The caller running the download gave up on it, so run it ourselves rather than sharing its cancellation.
+		if errors.Is(download.err, ErrBundleDownloadCancelled) {
+			continue
+		}
+
+		return download.block, download.err
+	}
+
+	if b.closed {
+		b.mu.Unlock()
+
+		return nil, cancelled(context.Canceled)
+	}
+
+	downloadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	download := &bundleDownload{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	b.inFlight[root] = download
+
+	b.wg.Add(1)
+	defer b.wg.Done()
+
+	b.mu.Unlock()
+
+	download.block, download.err = b.run(downloadCtx, download, fn)
+	if download.err != nil && downloadCtx.Err() != nil && !errors.Is(download.err, ErrBundleDownloadCancelled) {
+		download.err = cancelled(download.err)
+	}
+
+	b.mu.Lock()
+	// The download may have been abandoned and replaced while it was running.
+	if b.inFlight[root] == download {
+		delete(b.inFlight, root)
+	}
+	b.mu.Unlock()
+
+	close(download.done)
+
+	return download.block, download.err
+}
+
+// InFlight returns true if a download of root is in flight and hasn't been in flight for so long that the next
+// caller would abandon it.
+func (b *bundleDownloads) InFlight(root phase0.Root) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	download, exists := b.inFlight[root]
+
+	return exists && !b.stale(download)
+}
+
+// Len returns how many downloads are in flight.
+func (b *bundleDownloads) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.inFlight)
+}
+
+// stale returns true if the download has been running for longer than staleAfter. A download waiting for a slot is
+// never stale. b.mu must be held.
+func (b *bundleDownloads) stale(download *bundleDownload) bool {
+	return b.staleAfter > 0 && !download.startedAt.IsZero() && time.Since(download.startedAt) > b.staleAfter
+}
+
+// staleTimer returns a channel that fires once the download may have become stale, or nil if downloads never become
+// stale. b.mu must be held.
+func (b *bundleDownloads) staleTimer(download *bundleDownload) <-chan time.Time {
+	if b.staleAfter == 0 {
+		return nil
+	}
+
+	// It can't become stale until staleAfter after it gets a slot, so check again then.
+	if download.startedAt.IsZero() {
+		return time.After(b.staleAfter)
+	}
+
+	return time.After(time.Until(download.startedAt.Add(b.staleAfter)) + time.Millisecond)
+}
+
+// reap cancels and abandons a stale download so its slot is freed and its root can be downloaded again. b.mu must
+// be held.
+func (b *bundleDownloads) reap(root phase0.Root, download *bundleDownload) {
+	delete(b.inFlight, root)
+
+	download.cancel()
+
+	b.log.
+		WithField("root", eth.RootAsString(root)).
+		WithField("started_at", download.startedAt).
+		Warn("Abandoning bundle download that has been in flight for too long")
+}
+
+// run runs fn once a download slot is free, recording when it started.
+func (b *bundleDownloads) run(ctx context.Context, download *bundleDownload, fn func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error)) (*spec.VersionedSignedBeaconBlock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, cancelled(err)
+	}
+
+	if b.slots != nil {
+		select {
+		case b.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, cancelled(ctx.Err())
+		}
+
+		defer func() { <-b.slots }()
+	}
+
+	b.mu.Lock()
+	download.startedAt = time.Now()
+	b.mu.Unlock()
+
+	return fn(ctx)
+}
+
+func cancelled(err error) error {
+	return fmt.Errorf("%w: %s", ErrBundleDownloadCancelled, err)
+}
+
+// Wait blocks until all in-flight downloads have finished or the context is done.
+func (b *bundleDownloads) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cancel cancels every in-flight download and stops new ones from starting, returning once the in-flight downloads
+// have stopped.
+func (b *bundleDownloads) Cancel() {
+	b.mu.Lock()
+
+	b.closed = true
+
+	for _, download := range b.inFlight {
+		download.cancel()
+	}
+
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
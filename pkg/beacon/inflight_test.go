@@ -0,0 +1,514 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBundleDownloadsCollapsesConcurrentDownloads(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	root := phase0.Root{0x01}
+	expected := &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionPhase0}
+
+	var fetches int32
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			block, err := downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+				atomic.AddInt32(&fetches, 1)
+
+				time.Sleep(100 * time.Millisecond)
+
+				return expected, nil
+			})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if block != expected {
+				t.Errorf("expected the shared download result")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Fatalf("expected exactly 1 upstream fetch, got %d", got)
+	}
+}
+
+func TestBundleDownloadsDifferentRoots(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	var fetches int32
+
+	for _, root := range []phase0.Root{{0x01}, {0x02}} {
+		if _, err := downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			atomic.AddInt32(&fetches, 1)
+
+			return nil, nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Fatalf("expected 2 upstream fetches, got %d", got)
+	}
+}
+
+func TestBundleDownloadsWait(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_, _ = downloads.Do(context.Background(), phase0.Root{0x01}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(started)
+			<-release
+
+			return nil, nil
+		})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := downloads.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to give up while a download is in flight")
+	}
+
+	close(release)
+
+	if err := downloads.Wait(context.Background()); err != nil {
+		t.Fatalf("expected Wait to return once downloads finished, got %v", err)
+	}
+}
+
+func TestBundleDownloadsCancel(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	started := make(chan struct{})
+	result := make(chan error)
+
+	go func() {
+		// The download hangs until its context is cancelled.
+		_, err := downloads.Do(context.Background(), phase0.Root{0x01}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(started)
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		})
+
+		result <- err
+	}()
+
+	<-started
+
+	downloads.Cancel()
+
+	if err := <-result; !errors.Is(err, ErrBundleDownloadCancelled) {
+		t.Fatalf("expected %v, got %v", ErrBundleDownloadCancelled, err)
+	}
+
+	if downloads.Len() != 0 {
+		t.Errorf("expected no downloads to be left in flight, got %d", downloads.Len())
+	}
+
+	_, err := downloads.Do(context.Background(), phase0.Root{0x02}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+		t.Error("expected no download to start once cancelled")
+
+		return nil, nil
+	})
+	if !errors.Is(err, ErrBundleDownloadCancelled) {
+		t.Errorf("expected %v, got %v", ErrBundleDownloadCancelled, err)
+	}
+}
+
+func TestBundleDownloadsConcurrencyLimit(t *testing.T) {
+	downloads := newBundleDownloads(2, 0, logrus.New())
+
+	var (
+		running int32
+		peak    int32
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func(root phase0.Root) {
+			defer wg.Done()
+
+			_, _ = downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+
+				for {
+					previous := atomic.LoadInt32(&peak)
+					if current <= previous || atomic.CompareAndSwapInt32(&peak, previous, current) {
+						break
+					}
+				}
+
+				time.Sleep(20 * time.Millisecond)
+
+				return nil, nil
+			})
+		}(phase0.Root{byte(i)})
+	}
+
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got != 2 {
+		t.Fatalf("expected at most 2 concurrent downloads, got %d", got)
+	}
+}
+
+func TestBundleDownloadsGivesUpWaitingForSlot(t *testing.T) {
+	downloads := newBundleDownloads(1, 0, logrus.New())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_, _ = downloads.Do(context.Background(), phase0.Root{0x01}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(started)
+			<-release
+
+			return nil, nil
+		})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := downloads.Do(ctx, phase0.Root{0x02}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+		t.Error("download should not have started")
+
+		return nil, nil
+	}); err == nil {
+		t.Error("expected an error when the context is done before a slot is free")
+	}
+
+	close(release)
+}
+
+func TestBundleDownloadsCancelledMidDownload(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	start := time.Now()
+
+	// The download hangs until its context is cancelled, like a request to an upstream that stopped responding.
+	_, err := downloads.Do(ctx, phase0.Root{0x01}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+		close(started)
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, ErrBundleDownloadCancelled) {
+		t.Fatalf("expected %v, got %v", ErrBundleDownloadCancelled, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the download to return promptly after cancellation, took %s", elapsed)
+	}
+
+	if err := downloads.Wait(context.Background()); err != nil {
+		t.Errorf("expected no downloads to be left in flight: %v", err)
+	}
+}
+
+func TestBundleDownloadsWaiterGivesUp(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go func() {
+		_, _ = downloads.Do(context.Background(), phase0.Root{0x01}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(started)
+			<-release
+
+			return nil, nil
+		})
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := downloads.Do(ctx, phase0.Root{0x01}, nil); !errors.Is(err, ErrBundleDownloadCancelled) {
+		t.Errorf("expected a caller waiting on a hung download to give up with %v, got %v", ErrBundleDownloadCancelled, err)
+	}
+
+	close(release)
+}
+
+func TestBundleDownloadsWaiterTakesOverCancelledDownload(t *testing.T) {
+	downloads := newBundleDownloads(0, 0, logrus.New())
+
+	root := phase0.Root{0x01}
+	expected := &spec.VersionedSignedBeaconBlock{Version: spec.DataVersionPhase0}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, _ = downloads.Do(ctx, root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(started)
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		})
+	}()
+
+	<-started
+
+	result := make(chan error, 1)
+
+	go func() {
+		block, err := downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			return expected, nil
+		})
+		if err == nil && block != expected {
+			err = errors.New("expected the waiter's own download result")
+		}
+
+		result <- err
+	}()
+
+	cancel()
+	<-done
+
+	if err := <-result; err != nil {
+		t.Errorf("expected the waiter to run the download itself, got %v", err)
+	}
+}
+
+func TestBundleDownloadsAbandonsStaleDownloads(t *testing.T) {
+	downloads := newBundleDownloads(0, 50*time.Millisecond, logrus.New())
+
+	root := phase0.Root{0x01}
+	hung := make(chan struct{})
+	release := make(chan struct{})
+
+	// A download that never returns on its own, like an upstream that stopped responding without timing out.
+	go func() {
+		//nolint:errcheck // The result of the abandoned download doesn't matter.
+		downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(hung)
+			<-release
+
+			return nil, errors.New("hung")
+		})
+	}()
+
+	<-hung
+
+	started := time.Now()
+	restarted := make(chan struct{})
+
+	done := make(chan error)
+
+	go func() {
+		_, err := downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(restarted)
+
+			// The abandoned download finishing must not remove the new download from the in-flight set.
+			close(release)
+			time.Sleep(20 * time.Millisecond)
+
+			downloads.mu.Lock()
+			_, exists := downloads.inFlight[root]
+			downloads.mu.Unlock()
+
+			if !exists {
+				return nil, errors.New("new download was removed from the in-flight set")
+			}
+
+			return &spec.VersionedSignedBeaconBlock{}, nil
+		})
+
+		done <- err
+	}()
+
+	select {
+	case <-restarted:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stale download to be abandoned and the root downloaded again")
+	}
+
+	if waited := time.Since(started); waited < 40*time.Millisecond {
+		t.Errorf("expected the download to only be abandoned once stale, abandoned after %s", waited)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBundleDownloadsCancelsAbandonedDownloads(t *testing.T) {
+	downloads := newBundleDownloads(1, 50*time.Millisecond, logrus.New())
+
+	root := phase0.Root{0x01}
+	hung := make(chan struct{})
+	abandoned := make(chan error, 1)
+
+	// A download that only returns once it's cancelled, holding the only slot until then.
+	go func() {
+		abandoned <- func() error {
+			_, err := downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+				close(hung)
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			})
+
+			return err
+		}()
+	}()
+
+	<-hung
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := downloads.Do(ctx, root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+		return &spec.VersionedSignedBeaconBlock{}, nil
+	}); err != nil {
+		t.Fatalf("expected the abandoned download to free its slot for the new one, got %v", err)
+	}
+
+	if err := <-abandoned; !errors.Is(err, ErrBundleDownloadCancelled) {
+		t.Errorf("expected the abandoned download to be cancelled, got %v", err)
+	}
+}
+
+func TestBundleDownloadsNotStaleWhileWaitingForSlot(t *testing.T) {
+	downloads := newBundleDownloads(1, 50*time.Millisecond, logrus.New())
+
+	busy := make(chan struct{})
+	release := make(chan struct{})
+
+	// Hold the only slot for longer than downloads take to become stale.
+	go func() {
+		_, _ = downloads.Do(context.Background(), phase0.Root{0x01}, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+			close(busy)
+			<-release
+
+			return nil, errors.New("released")
+		})
+	}()
+
+	<-busy
+
+	root := phase0.Root{0x02}
+
+	var runs int32
+
+	download := func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+		atomic.AddInt32(&runs, 1)
+
+		return &spec.VersionedSignedBeaconBlock{}, nil
+	}
+
+	queued := make(chan error)
+
+	go func() {
+		_, err := downloads.Do(context.Background(), root, download)
+		queued <- err
+	}()
+
+	for downloads.Len() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Let the queued download wait for a slot for longer than staleAfter.
+	time.Sleep(80 * time.Millisecond)
+
+	joined := make(chan error)
+
+	go func() {
+		_, err := downloads.Do(context.Background(), root, download)
+		joined <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	for _, result := range []chan error{queued, joined} {
+		if err := <-result; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if runs := atomic.LoadInt32(&runs); runs != 1 {
+		t.Errorf("expected the queued download to be shared rather than abandoned, ran %d times", runs)
+	}
+}
+
+func benchmarkBundleDownloads(b *testing.B, concurrency int) {
+	downloads := newBundleDownloads(concurrency, 0, logrus.New())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < b.N; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			root := phase0.Root{byte(i), byte(i >> 8), byte(i >> 16), byte(i >> 24)}
+
+			_, _ = downloads.Do(context.Background(), root, func(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+				// Stand in for the time spent waiting on an upstream.
+				time.Sleep(time.Millisecond)
+
+				return nil, nil
+			})
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkBundleDownloadsConcurrency1(b *testing.B) { benchmarkBundleDownloads(b, 1) }
+
+func BenchmarkBundleDownloadsConcurrency4(b *testing.B) { benchmarkBundleDownloads(b, 4) }
+
+func BenchmarkBundleDownloadsConcurrency16(b *testing.B) { benchmarkBundleDownloads(b, 16) }
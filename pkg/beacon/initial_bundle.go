@@ -0,0 +1,144 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+const (
+	// initialBundleExpiry is how long the bundle imported from local files is kept for, which is effectively forever.
+	initialBundleExpiry = 100 * 365 * 24 * time.Hour
+	// initialBundlePollInterval is how often the spec is checked for before the imported bundle can be served.
+	initialBundlePollInterval = 5 * time.Second
+)
+
+// LoadBundleFiles reads an SSZ encoded block and state of the named fork version, e.g. "capella", and checks
+// that the state is the one committed to by the block.
+func LoadBundleFiles(version, blockFile, stateFile string) (*spec.VersionedSignedBeaconBlock, []byte, error) {
+	blockData, err := os.ReadFile(blockFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read block file: %w", err)
+	}
+
+	block, err := store.UnmarshalBlockSSZ(version, blockData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+
+	state, err := os.ReadFile(stateFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := VerifyStateRoot(block, state); err != nil {
+		return nil, nil, err
+	}
+
+	return block, state, nil
+}
+
+// importInitialBundle stores the bundle from the configured local files, without an expiry, so it can be served
+// without downloading it from an upstream.
+func (d *Default) importInitialBundle() (*spec.VersionedSignedBeaconBlock, error) {
+	block, state, err := LoadBundleFiles(d.config.InitialBundleVersion, d.config.InitialBlockFile, d.config.InitialStateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(initialBundleExpiry)
+
+	if err := d.blocks.Add(block, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to store block: %w", err)
+	}
+
+	if d.shouldDownloadStates() {
+		if err := d.states.Add(stateRoot, &state, expiresAt, slot); err != nil {
+			return nil, fmt.Errorf("failed to store state: %w", err)
+		}
+
+		d.metrics.ObserveStateSize(len(state))
+	}
+
+	d.log.
+		WithField("slot", eth.SlotAsString(slot)).
+		WithField("root", eth.RootAsString(root)).
+		Info("Imported initial checkpoint bundle from local files")
+
+	return block, nil
+}
+
+// serveInitialBundle serves the imported bundle once the spec is known, as it's needed to check that the bundle
+// is on an epoch boundary. A newer bundle that is already being served is left alone.
+func (d *Default) serveInitialBundle(ctx context.Context, block *spec.VersionedSignedBeaconBlock) error {
+	for {
+		if _, err := d.Spec(ctx); err == nil {
+			break
+		}
+
+		select {
+		case <-time.After(initialBundlePollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return err
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return err
+	}
+
+	slotsPerEpoch := d.slotsPerEpoch(ctx)
+	if slot%slotsPerEpoch != 0 {
+		return fmt.Errorf("block slot is not aligned from an epoch boundary: %d", slot)
+	}
+
+	epoch := phase0.Epoch(slot / slotsPerEpoch)
+
+	if serving := d.serving(); serving != nil && serving.Finalized != nil && serving.Finalized.Epoch >= epoch {
+		return nil
+	}
+
+	if !d.serveBundle(&v1.Finality{
+		Finalized: &phase0.Checkpoint{
+			Epoch: epoch,
+			Root:  root,
+		},
+	}) {
+		return nil
+	}
+
+	d.log.
+		WithField("epoch", epoch).
+		WithField("root", eth.RootAsString(root)).
+		Info("Serving the initial checkpoint bundle")
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func writeTestBundleFiles(t *testing.T, block, state []byte) (string, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	blockFile := filepath.Join(dir, "block.ssz")
+	if err := os.WriteFile(blockFile, block, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stateFile := filepath.Join(dir, "state.ssz")
+	if err := os.WriteFile(stateFile, state, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return blockFile, stateFile
+}
+
+func TestImportInitialBundle(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, blockData := newTestPhase0BlockSSZ(t, stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_initial_bundle")
+	d.config.ServedBundleHistory = 1
+	d.config.InitialBundleVersion = spec.DataVersionPhase0.String()
+	d.config.InitialBlockFile, d.config.InitialStateFile = writeTestBundleFiles(t, blockData, data)
+
+	imported, err := d.importInitialBundle()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := d.GetBlockByRoot(ctx, root); err != nil {
+		t.Errorf("expected the imported block to be stored: %v", err)
+	}
+
+	if _, err := d.GetBeaconStateByStateRoot(ctx, stateRoot); err != nil {
+		t.Errorf("expected the imported state to be stored: %v", err)
+	}
+
+	if err := d.serveInitialBundle(ctx, imported); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d.servingBundle == nil || d.servingBundle.Finalized.Root != root || d.servingBundle.Finalized.Epoch != 2 {
+		t.Errorf("expected the imported bundle to be served at epoch 2, got %v", d.servingBundle)
+	}
+}
+
+func TestLoadBundleFilesRejectsMismatchedState(t *testing.T) {
+	_, data := newTestAltairStateSSZ(t)
+
+	blockData, err := newTestAltairBlock(phase0.Root{0x01}).Altair.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockFile, stateFile := writeTestBundleFiles(t, blockData, data)
+
+	if _, _, err := LoadBundleFiles(spec.DataVersionAltair.String(), blockFile, stateFile); !errors.Is(err, ErrStateRootMismatch) {
+		t.Errorf("expected %v, got %v", ErrStateRootMismatch, err)
+	}
+}
+
+func TestLoadBundleFilesRejectsUnknownVersion(t *testing.T) {
+	blockFile, stateFile := writeTestBundleFiles(t, []byte{0x01}, []byte{0x01})
+
+	if _, _, err := LoadBundleFiles("unknown", blockFile, stateFile); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
@@ -1,7 +1,12 @@
 package beacon
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -9,8 +14,36 @@ type Metrics struct {
 	servingEpoch  prometheus.Gauge
 	headEpoch     prometheus.Gauge
 	operatingMode prometheus.GaugeVec
+
+	servingCheckpointUpdatedAt prometheus.Gauge
+	servingSince               prometheus.Gauge
+	upstreamFinalityLag        prometheus.GaugeVec
+
+	bundleDownloadsInFlight prometheus.Gauge
+	bundleDownloads         prometheus.CounterVec
+	bundleFetchQueueSize    prometheus.Gauge
+
+	finalityDisagreements prometheus.CounterVec
+	crossCheckFailures    prometheus.Counter
+
+	upstreamRequestDuration prometheus.HistogramVec
+	stateSize               prometheus.Histogram
+
+	storeLookups prometheus.CounterVec
 }
 
+// Stores and lookup types of the block and state lookups recorded by ObserveStoreLookup.
+const (
+	storeBlock = "block"
+	storeState = "state"
+
+	lookupSlot       = "slot"
+	lookupRoot       = "root"
+	lookupStateRoot  = "state_root"
+	lookupParentRoot = "parent_root"
+	lookupSSZ        = "ssz"
+)
+
 func NewMetrics(namespace string) *Metrics {
 	m := &Metrics{
 		servingEpoch: prometheus.NewGauge(prometheus.GaugeOpts{
@@ -29,11 +62,84 @@ func NewMetrics(namespace string) *Metrics {
 				Name:      "operating_mode",
 				Help:      "The current operating mode",
 			}, []string{"mode"}),
+		servingCheckpointUpdatedAt: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "serving_checkpoint_updated_at",
+			Help:      "The unix timestamp of when the serving checkpoint last changed",
+		}),
+		servingSince: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "serving_since",
+			Help:      "The unix timestamp of when the checkpoint being served was first served",
+		}),
+		upstreamFinalityLag: *prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "upstream_finality_lag_epochs",
+				Help:      "How many epochs an upstream's finalized checkpoint is behind the head",
+			}, []string{"upstream"}),
+		bundleDownloadsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "bundle_downloads_in_flight",
+			Help:      "The number of bundle downloads currently in progress",
+		}),
+		bundleFetchQueueSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "bundle_fetch_queue_size",
+			Help:      "The number of bundle fetches queued through the admin fetch endpoint that haven't finished",
+		}),
+		bundleDownloads: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "bundle_downloads_total",
+				Help:      "The total number of bundle downloads",
+			}, []string{"result"}),
+		finalityDisagreements: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "finality_disagreements_total",
+				Help:      "The total number of finality checks where the ready upstreams reported more than one finalized root",
+			}, []string{"roots"}),
+		crossCheckFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cross_check_failures_total",
+			Help:      "The total number of finalized checkpoints not served because upstreams didn't agree on the finalized block",
+		}),
+		upstreamRequestDuration: *prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "upstream_request_duration_seconds",
+				Help:      "How long requests to upstreams take",
+				Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+			}, []string{"upstream", "operation"}),
+		stateSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "state_size_bytes",
+			Help:      "The size of each beacon state stored",
+			Buckets:   prometheus.ExponentialBuckets(16*1024*1024, 2, 8),
+		}),
+		storeLookups: *prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "store_lookups_total",
+				Help:      "The total number of block and state lookups made for API requests, by whether the item was cached",
+			}, []string{"store", "lookup", "result"}),
 	}
 
 	prometheus.MustRegister(m.servingEpoch)
 	prometheus.MustRegister(m.headEpoch)
 	prometheus.MustRegister(m.operatingMode)
+	prometheus.MustRegister(m.servingCheckpointUpdatedAt)
+	prometheus.MustRegister(m.servingSince)
+	prometheus.MustRegister(m.upstreamFinalityLag)
+	prometheus.MustRegister(m.bundleDownloadsInFlight)
+	prometheus.MustRegister(m.bundleDownloads)
+	prometheus.MustRegister(m.bundleFetchQueueSize)
+	prometheus.MustRegister(m.finalityDisagreements)
+	prometheus.MustRegister(m.crossCheckFailures)
+	prometheus.MustRegister(m.upstreamRequestDuration)
+	prometheus.MustRegister(m.stateSize)
+	prometheus.MustRegister(m.storeLookups)
 
 	return m
 }
@@ -42,6 +148,18 @@ func (m *Metrics) ObserveServingEpoch(epoch phase0.Epoch) {
 	m.servingEpoch.Set(float64(uint64(epoch)))
 }
 
+func (m *Metrics) ObserveServingCheckpointUpdated(at time.Time) {
+	m.servingCheckpointUpdatedAt.Set(float64(at.Unix()))
+}
+
+func (m *Metrics) ObserveServingSince(at time.Time) {
+	m.servingSince.Set(float64(at.Unix()))
+}
+
+func (m *Metrics) ObserveUpstreamFinalityLag(upstream string, lag phase0.Epoch) {
+	m.upstreamFinalityLag.WithLabelValues(upstream).Set(float64(uint64(lag)))
+}
+
 func (m *Metrics) ObserveHeadEpoch(epoch phase0.Epoch) {
 	m.headEpoch.Set(float64(uint64(epoch)))
 }
@@ -50,3 +168,54 @@ func (m *Metrics) ObserveOperatingMode(mode OperatingMode) {
 	m.operatingMode.Reset()
 	m.operatingMode.WithLabelValues(string(mode)).Set(1)
 }
+
+func (m *Metrics) ObserveBundleDownloadStarted() {
+	m.bundleDownloadsInFlight.Inc()
+}
+
+func (m *Metrics) ObserveBundleDownloadFinished(err error) {
+	m.bundleDownloadsInFlight.Dec()
+
+	result := "success"
+	if err != nil {
+		result = "failed"
+	}
+
+	m.bundleDownloads.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) ObserveBundleFetchQueueSize(size int) {
+	m.bundleFetchQueueSize.Set(float64(size))
+}
+
+func (m *Metrics) ObserveFinalityDisagreement(roots int) {
+	m.finalityDisagreements.WithLabelValues(fmt.Sprintf("%d", roots)).Inc()
+}
+
+func (m *Metrics) ObserveCrossCheckFailure() {
+	m.crossCheckFailures.Inc()
+}
+
+func (m *Metrics) ObserveUpstreamRequest(upstream, operation string, duration time.Duration) {
+	m.upstreamRequestDuration.WithLabelValues(upstream, operation).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveStateSize(bytes int) {
+	m.stateSize.Observe(float64(bytes))
+}
+
+// ObserveStoreLookup records whether a block or state lookup found the item in the store.
+func (m *Metrics) ObserveStoreLookup(storeName, lookup string, err error) {
+	m.storeLookups.WithLabelValues(storeName, lookup, storeLookupResult(err)).Inc()
+}
+
+func storeLookupResult(err error) string {
+	switch {
+	case err == nil:
+		return "hit"
+	case errors.Is(err, store.ErrBlockNotFound), errors.Is(err, store.ErrStateNotFound):
+		return "miss"
+	default:
+		return "error"
+	}
+}
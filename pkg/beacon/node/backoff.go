@@ -0,0 +1,95 @@
+package node
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBackoffBase is the interval used after the first failure.
+	DefaultBackoffBase = 5 * time.Second
+	// DefaultBackoffMax is the longest interval a node will be backed off for.
+	DefaultBackoffMax = 2 * time.Minute
+)
+
+// Backoff tracks consecutive failures for a node and determines when it should next be polled.
+type Backoff struct {
+	mu sync.Mutex
+
+	base time.Duration
+	max  time.Duration
+
+	failures    int
+	nextAttempt time.Time
+}
+
+// NewBackoff creates a new exponential backoff.
+func NewBackoff(base, maxInterval time.Duration) *Backoff {
+	return &Backoff{
+		base: base,
+		max:  maxInterval,
+	}
+}
+
+// Ready returns true if the node should be polled at the given time.
+func (b *Backoff) Ready(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !now.Before(b.nextAttempt)
+}
+
+// Failure records a failed poll and schedules the next attempt.
+func (b *Backoff) Failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+
+	interval := b.interval()
+
+	//nolint:gosec // jitter doesn't need to be cryptographically secure.
+	jitter := time.Duration(rand.Int63n(int64(interval/2) + 1))
+
+	b.nextAttempt = now.Add(interval + jitter)
+}
+
+// Success resets the backoff so the node is polled at the normal cadence.
+func (b *Backoff) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.nextAttempt = time.Time{}
+}
+
+// Interval returns the current backoff interval, excluding jitter. Returns 0 if the node is not backed off.
+func (b *Backoff) Interval() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.interval()
+}
+
+func (b *Backoff) interval() time.Duration {
+	if b.failures == 0 {
+		return 0
+	}
+
+	interval := b.base
+
+	for i := 1; i < b.failures; i++ {
+		interval *= 2
+
+		if interval >= b.max {
+			return b.max
+		}
+	}
+
+	if interval > b.max {
+		return b.max
+	}
+
+	return interval
+}
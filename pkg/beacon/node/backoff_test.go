@@ -0,0 +1,65 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffInterval(t *testing.T) {
+	b := NewBackoff(5*time.Second, 2*time.Minute)
+
+	tests := []time.Duration{
+		5 * time.Second,
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		80 * time.Second,
+		2 * time.Minute,
+		2 * time.Minute,
+	}
+
+	if b.Interval() != 0 {
+		t.Fatalf("expected no interval before any failures, got %v", b.Interval())
+	}
+
+	now := time.Now()
+
+	for i, want := range tests {
+		b.Failure(now)
+
+		if got := b.Interval(); got != want {
+			t.Errorf("failure %d: expected interval %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestBackoffReady(t *testing.T) {
+	b := NewBackoff(5*time.Second, 2*time.Minute)
+
+	now := time.Now()
+
+	if !b.Ready(now) {
+		t.Fatal("expected a fresh backoff to be ready")
+	}
+
+	b.Failure(now)
+
+	if b.Ready(now) {
+		t.Error("expected backoff to not be ready immediately after a failure")
+	}
+
+	// Interval plus the maximum jitter.
+	if !b.Ready(now.Add(5*time.Second + 5*time.Second/2)) {
+		t.Error("expected backoff to be ready after the interval and jitter have elapsed")
+	}
+
+	b.Success()
+
+	if !b.Ready(now) {
+		t.Error("expected backoff to be ready after a success")
+	}
+
+	if b.Interval() != 0 {
+		t.Errorf("expected interval to be reset after a success, got %v", b.Interval())
+	}
+}
@@ -0,0 +1,44 @@
+package node
+
+import "strings"
+
+// ClientType is the beacon client software a node runs, used to work around known quirks in its Beacon API.
+type ClientType string
+
+const (
+	// ClientAuto detects the client from the version the node reports.
+	ClientAuto       ClientType = "auto"
+	ClientLighthouse ClientType = "lighthouse"
+	ClientLodestar   ClientType = "lodestar"
+	ClientNimbus     ClientType = "nimbus"
+	ClientPrysm      ClientType = "prysm"
+	ClientTeku       ClientType = "teku"
+	// ClientUnknown is a client that couldn't be detected. No quirks are applied to it.
+	ClientUnknown ClientType = "unknown"
+)
+
+// KnownClientTypes are the clients that can be configured or detected.
+var KnownClientTypes = []ClientType{ClientLighthouse, ClientLodestar, ClientNimbus, ClientPrysm, ClientTeku}
+
+// DetectClientType returns the client named in a node version such as "Lighthouse/v4.0.1-693886b/x86_64-linux".
+func DetectClientType(version string) ClientType {
+	name := strings.ToLower(strings.SplitN(version, "/", 2)[0])
+
+	for _, client := range KnownClientTypes {
+		if strings.HasPrefix(name, string(client)) {
+			return client
+		}
+	}
+
+	return ClientUnknown
+}
+
+// SupportsDepositSnapshot reports whether the client serves /eth/v1/beacon/deposit_snapshot (EIP-4881).
+func (c ClientType) SupportsDepositSnapshot() bool {
+	switch c {
+	case ClientLodestar, ClientPrysm:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,26 @@
+package node
+
+import "testing"
+
+func TestDetectClientType(t *testing.T) {
+	tests := []struct {
+		version string
+		expect  ClientType
+	}{
+		{"Lighthouse/v4.0.1-693886b/x86_64-linux", ClientLighthouse},
+		{"teku/v23.4.0/linux-x86_64/-eclipseadoptium-openjdk64bitservervm-java-17", ClientTeku},
+		{"Prysm/v4.0.3/3f61d6cce7a5aee1a4e9e4c8e9b6d7f4c5b3b8c1. Built at: 2023-04-20", ClientPrysm},
+		{"Nimbus/v23.4.0-e8f1f0-stateofus", ClientNimbus},
+		{"Lodestar/v1.8.0/47d5f6b", ClientLodestar},
+		{"Grandine/0.2.0", ClientUnknown},
+		{"", ClientUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			if client := DetectClientType(test.version); client != test.expect {
+				t.Errorf("expected %s, got %s", test.expect, client)
+			}
+		})
+	}
+}
@@ -1,8 +1,202 @@
 package node
 
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the request timeout used when a node does not configure one.
+const DefaultTimeout = 30 * time.Second
+
+// DefaultStateTimeout is the beacon state request timeout used when a node does not configure one. States are
+// hundreds of megabytes on mainnet, so they get far longer than other requests.
+const DefaultStateTimeout = 10 * time.Minute
+
+// DefaultPrimaryRetryInterval is how long a node stays failed over to a fallback address, when it does not configure
+// it, before the primary address is tried again.
+const DefaultPrimaryRetryInterval = 5 * time.Minute
+
+// Role is what a node is used for.
+type Role string
+
+const (
+	// RoleFinalityOnly nodes vote on finality but are never used to download blocks or states.
+	RoleFinalityOnly Role = "finality-only"
+	// RoleDataProvider nodes vote on finality and are also used to download blocks and states.
+	RoleDataProvider Role = "data-provider"
+)
+
 type Config struct {
-	Name         string            `yaml:"name"`
-	Address      string            `yaml:"address"`
-	DataProvider bool              `yaml:"dataProvider"`
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	// FallbackAddresses are tried in order when the node can't be reached at Address. They are the same logical
+	// node, so they don't add finality votes.
+	FallbackAddresses []string `yaml:"fallbackAddresses"`
+	// PrimaryRetryInterval is how long the node stays failed over before Address is tried again. Defaults to 5m.
+	PrimaryRetryInterval time.Duration `yaml:"primaryRetryInterval"`
+	// Role is what the node is used for. Defaults to data-provider.
+	Role Role `yaml:"role"`
+	// ClientType is the beacon client the node runs, so its known quirks can be worked around. Defaults to auto,
+	// which detects it from the node's reported version.
+	ClientType ClientType `yaml:"clientType"`
+	// DataProvider is the legacy way of setting the role. Only used if Role is not set.
+	DataProvider *bool             `yaml:"dataProvider"`
 	Headers      map[string]string `yaml:"headers"`
+	// Weight is how many votes this node's finality counts for. Defaults to 1.
+	Weight int `yaml:"weight"`
+	// Timeout is the deadline for each request made to the node, other than for beacon states. Defaults to 30s.
+	Timeout time.Duration `yaml:"timeout"`
+	// StateTimeout is the deadline for each beacon state request made to the node. Defaults to 10m.
+	StateTimeout time.Duration `yaml:"stateTimeout"`
+	// RateLimit is the maximum requests per second made to the node. Defaults to 0 (unlimited).
+	RateLimit float64 `yaml:"rateLimit"`
+	// BasicAuthUser and BasicAuthPassword are sent as HTTP basic auth with every request.
+	BasicAuthUser     string `yaml:"basicAuthUser"`
+	BasicAuthPassword string `yaml:"basicAuthPassword"`
+	// BearerToken is sent as a bearer token with every request.
+	BearerToken string `yaml:"bearerToken"`
+	// BearerTokenFile is a file containing the bearer token to send with every request.
+	BearerTokenFile string `yaml:"bearerTokenFile"`
+}
+
+// Validate checks that at most one way of authenticating with the node is configured.
+func (c *Config) Validate() error {
+	methods := 0
+
+	if c.BasicAuthUser != "" || c.BasicAuthPassword != "" {
+		if c.BasicAuthUser == "" {
+			return errors.New("basicAuthUser is required when basicAuthPassword is set")
+		}
+
+		methods++
+	}
+
+	if c.BearerToken != "" {
+		methods++
+	}
+
+	if c.BearerTokenFile != "" {
+		methods++
+	}
+
+	if methods > 1 {
+		return errors.New("only one of basic auth, bearerToken or bearerTokenFile can be set")
+	}
+
+	if methods == 1 {
+		for header := range c.Headers {
+			if strings.EqualFold(header, "Authorization") {
+				return errors.New("an Authorization header cannot be set as well as basic auth or a bearer token")
+			}
+		}
+	}
+
+	return nil
+}
+
+// RequestHeaders returns the headers to send with every request to the node, including any Authorization header.
+// The bearer token file is read each time this is called.
+func (c *Config) RequestHeaders() (map[string]string, error) {
+	headers := make(map[string]string, len(c.Headers)+1)
+
+	for header, value := range c.Headers {
+		headers[header] = value
+	}
+
+	switch {
+	case c.BasicAuthUser != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.BasicAuthUser + ":" + c.BasicAuthPassword))
+
+		headers["Authorization"] = "Basic " + credentials
+	case c.BearerToken != "":
+		headers["Authorization"] = "Bearer " + c.BearerToken
+	case c.BearerTokenFile != "":
+		token, err := os.ReadFile(c.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bearer token file: %w", err)
+		}
+
+		headers["Authorization"] = "Bearer " + strings.TrimSpace(string(token))
+	}
+
+	return headers, nil
+}
+
+// RedactedAddress returns the node's address with any password removed so it is safe to log.
+func (c *Config) RedactedAddress() string {
+	u, err := url.Parse(c.Address)
+	if err != nil {
+		return "[unparseable address]"
+	}
+
+	return u.Redacted()
+}
+
+// NodeRole returns what the node is used for.
+func (c *Config) NodeRole() Role {
+	if c.Role != "" {
+		return c.Role
+	}
+
+	if c.DataProvider != nil && !*c.DataProvider {
+		return RoleFinalityOnly
+	}
+
+	return RoleDataProvider
+}
+
+// ConfiguredClientType returns the client type the node is configured as, or ClientAuto if it should be detected.
+func (c *Config) ConfiguredClientType() ClientType {
+	if c.ClientType == "" {
+		return ClientAuto
+	}
+
+	return c.ClientType
+}
+
+// VoteWeight returns the weight of the node's finality vote.
+func (c *Config) VoteWeight() int {
+	if c.Weight < 1 {
+		return 1
+	}
+
+	return c.Weight
+}
+
+// Addresses returns the node's address followed by its fallback addresses.
+func (c *Config) Addresses() []string {
+	return append([]string{c.Address}, c.FallbackAddresses...)
+}
+
+// PrimaryRetry returns how long the node stays failed over to a fallback address before its primary address is
+// tried again.
+func (c *Config) PrimaryRetry() time.Duration {
+	if c.PrimaryRetryInterval <= 0 {
+		return DefaultPrimaryRetryInterval
+	}
+
+	return c.PrimaryRetryInterval
+}
+
+// RequestTimeout returns the deadline for each request made to the node, other than for beacon states.
+func (c *Config) RequestTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultTimeout
+	}
+
+	return c.Timeout
+}
+
+// StateRequestTimeout returns the deadline for each beacon state request made to the node.
+func (c *Config) StateRequestTimeout() time.Duration {
+	if c.StateTimeout <= 0 {
+		return DefaultStateTimeout
+	}
+
+	return c.StateTimeout
 }
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that limits how many requests per second are made to a node.
+// A nil RateLimiter, or one with a rate of 0, doesn't limit requests.
+type RateLimiter struct {
+	mu sync.Mutex
+
+	rate  float64
+	burst float64
+
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a new rate limiter allowing rate requests per second.
+// Bursts of up to one second's worth of requests are allowed.
+func NewRateLimiter(rate float64) *RateLimiter {
+	burst := rate
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+	}
+}
+
+// Rate returns the requests per second allowed. 0 means unlimited.
+func (r *RateLimiter) Rate() float64 {
+	if r == nil {
+		return 0
+	}
+
+	return r.rate
+}
+
+// Wait blocks until a request may be made, or returns an error if the context is done first.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	if r == nil || r.rate <= 0 {
+		return nil
+	}
+
+	for {
+		delay := r.reserve(time.Now())
+		if delay == 0 {
+			return nil
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token if one is available at the given time, returning 0. Otherwise returns how long until
+// the next token is available.
+func (r *RateLimiter) reserve(now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.last.IsZero() && now.After(r.last) {
+		r.tokens += now.Sub(r.last).Seconds() * r.rate
+
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+	}
+
+	if r.last.IsZero() || now.After(r.last) {
+		r.last = now
+	}
+
+	if r.tokens >= 1 {
+		r.tokens--
+
+		return 0
+	}
+
+	return time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+}
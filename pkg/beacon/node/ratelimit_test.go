@@ -0,0 +1,68 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	r := NewRateLimiter(2)
+	now := time.Now()
+
+	// The bucket starts full so a burst of two is allowed.
+	for i := 0; i < 2; i++ {
+		if delay := r.reserve(now); delay != 0 {
+			t.Fatalf("request %d: expected no delay, got %s", i, delay)
+		}
+	}
+
+	if delay := r.reserve(now); delay != 500*time.Millisecond {
+		t.Fatalf("expected a 500ms delay once the bucket is empty, got %s", delay)
+	}
+
+	// Half a second refills one token.
+	if delay := r.reserve(now.Add(500 * time.Millisecond)); delay != 0 {
+		t.Fatalf("expected no delay after refilling, got %s", delay)
+	}
+
+	// Tokens never exceed the burst, no matter how long we wait.
+	later := now.Add(time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if delay := r.reserve(later); delay != 0 {
+			t.Fatalf("request %d: expected no delay, got %s", i, delay)
+		}
+	}
+
+	if delay := r.reserve(later); delay == 0 {
+		t.Fatal("expected a delay once the burst is used up")
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	var r *RateLimiter
+
+	if err := r.Wait(context.Background()); err != nil {
+		t.Fatalf("expected a nil limiter not to limit, got %v", err)
+	}
+
+	if err := NewRateLimiter(0).Wait(context.Background()); err != nil {
+		t.Fatalf("expected a zero rate not to limit, got %v", err)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	r := NewRateLimiter(0.001)
+
+	if err := r.Wait(context.Background()); err != nil {
+		t.Fatalf("expected the first request to be allowed, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := r.Wait(ctx); err == nil {
+		t.Fatal("expected an error once the context is cancelled")
+	}
+}
@@ -0,0 +1,59 @@
+package node
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSampleInterval is how often a repeated event is let through by default.
+const DefaultSampleInterval = time.Minute
+
+// Sampler lets a repeated event through at most once per interval, counting the repeats it suppresses in between.
+// An event with a different key to the last one is always let through.
+type Sampler struct {
+	mu sync.Mutex
+
+	interval time.Duration
+
+	key        string
+	last       time.Time
+	suppressed int
+}
+
+// NewSampler creates a new sampler. An interval of 0 lets every event through.
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{
+		interval: interval,
+	}
+}
+
+// Allow returns true if the event with the given key should be let through at the given time, along with
+// how many repeats were suppressed since the last event that was.
+func (s *Sampler) Allow(now time.Time, key string) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key == s.key && !s.last.IsZero() && now.Sub(s.last) < s.interval {
+		s.suppressed++
+
+		return false, 0
+	}
+
+	suppressed := s.suppressed
+
+	s.key = key
+	s.last = now
+	s.suppressed = 0
+
+	return true, suppressed
+}
+
+// Reset forgets the last event, so the next one is always let through.
+func (s *Sampler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.key = ""
+	s.last = time.Time{}
+	s.suppressed = 0
+}
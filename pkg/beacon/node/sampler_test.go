@@ -0,0 +1,70 @@
+package node
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplerSuppressesRepeats(t *testing.T) {
+	s := NewSampler(time.Minute)
+	now := time.Now()
+
+	if ok, _ := s.Allow(now, "connection refused"); !ok {
+		t.Fatal("expected the first event to be let through")
+	}
+
+	for i := 1; i <= 3; i++ {
+		if ok, _ := s.Allow(now.Add(time.Duration(i)*5*time.Second), "connection refused"); ok {
+			t.Fatalf("expected repeat %d to be suppressed", i)
+		}
+	}
+
+	ok, suppressed := s.Allow(now.Add(time.Minute), "connection refused")
+	if !ok {
+		t.Fatal("expected the event to be let through once the interval has passed")
+	}
+
+	if suppressed != 3 {
+		t.Errorf("expected 3 suppressed events, got %d", suppressed)
+	}
+}
+
+func TestSamplerLetsDifferentEventsThrough(t *testing.T) {
+	s := NewSampler(time.Minute)
+	now := time.Now()
+
+	s.Allow(now, "connection refused")
+	s.Allow(now.Add(time.Second), "connection refused")
+
+	ok, suppressed := s.Allow(now.Add(2*time.Second), "timeout")
+	if !ok {
+		t.Fatal("expected a different event to be let through")
+	}
+
+	if suppressed != 1 {
+		t.Errorf("expected 1 suppressed event, got %d", suppressed)
+	}
+}
+
+func TestSamplerReset(t *testing.T) {
+	s := NewSampler(time.Minute)
+	now := time.Now()
+
+	s.Allow(now, "connection refused")
+	s.Reset()
+
+	if ok, _ := s.Allow(now.Add(time.Second), "connection refused"); !ok {
+		t.Error("expected the event to be let through after a reset")
+	}
+}
+
+func TestSamplerZeroIntervalLetsEverythingThrough(t *testing.T) {
+	s := NewSampler(0)
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := s.Allow(now, "connection refused"); !ok {
+			t.Fatal("expected every event to be let through")
+		}
+	}
+}
@@ -3,11 +3,17 @@ package beacon
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/rand"
+	"reflect"
+	"strings"
 	"time"
 
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
 	sbeacon "github.com/ethpandaops/beacon/pkg/beacon"
+	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
 	"github.com/sirupsen/logrus"
 )
@@ -15,37 +21,242 @@ import (
 type Node struct {
 	Config node.Config
 	Beacon sbeacon.Node
+
+	// FinalityBackoff throttles finality polling of the node while it is failing.
+	FinalityBackoff *node.Backoff
+	// FinalityLogSampler limits how often repeated finality polling failures are logged.
+	FinalityLogSampler *node.Sampler
+	// RateLimiter queues requests made to the node so they don't exceed its configured rate.
+	RateLimiter *node.RateLimiter
+	// Metrics records how long requests to the node take. May be nil.
+	Metrics *Metrics
 }
 
+// Operations requests to upstreams are labelled with in metrics.
+const (
+	upstreamOperationFetchBlock           = "fetch_block"
+	upstreamOperationFetchState           = "fetch_state"
+	upstreamOperationFetchDepositSnapshot = "fetch_deposit_snapshot"
+	upstreamOperationFetchFinality        = "fetch_finality"
+)
+
 type Nodes []*Node
 
 func NewNodesFromConfig(log logrus.FieldLogger, configs []node.Config, namespace string) Nodes {
 	nodes := make(Nodes, len(configs))
 
 	for i, config := range configs {
-		sconfig := &sbeacon.Config{
-			Name:    config.Name,
-			Addr:    config.Address,
-			Headers: config.Headers,
+		nodes[i] = NewNodeFromConfig(log, config, namespace, true)
+	}
+
+	return nodes
+}
+
+// NewNodeFromConfig creates an upstream from its config. The beacon library registers prometheus metrics for each
+// upstream by name, so prometheusMetrics must be false if an upstream of the same name has been created before.
+func NewNodeFromConfig(log logrus.FieldLogger, config node.Config, namespace string, prometheusMetrics bool) *Node {
+	headers, err := config.RequestHeaders()
+	if err != nil {
+		log.WithError(err).WithField("upstream", config.Name).Error("Failed to build upstream request headers")
+
+		headers = config.Headers
+	}
+
+	var snode sbeacon.Node
+
+	addresses := config.Addresses()
+	if len(addresses) == 1 {
+		snode = newBeaconNode(log, config.Name, config.Address, headers, namespace, prometheusMetrics)
+	} else {
+		beacons := make([]sbeacon.Node, len(addresses))
+
+		for i, address := range addresses {
+			// Each address is registered under its own name so their metrics don't collide.
+			name := config.Name
+			if i > 0 {
+				name = fmt.Sprintf("%s-%s", config.Name, addressLabel(i))
+			}
+
+			beacons[i] = newBeaconNode(log, name, address, headers, namespace, prometheusMetrics)
 		}
 
-		opts := *sbeacon.DefaultOptions()
+		snode = newFailoverBeacon(log.WithField("upstream", config.Name), beacons, config.PrimaryRetry())
+	}
+
+	return &Node{
+		Config:             config,
+		Beacon:             snode,
+		FinalityBackoff:    node.NewBackoff(node.DefaultBackoffBase, node.DefaultBackoffMax),
+		FinalityLogSampler: node.NewSampler(node.DefaultSampleInterval),
+		RateLimiter:        node.NewRateLimiter(config.RateLimit),
+	}
+}
+
+// newBeaconNode creates a beacon node for one of an upstream's addresses.
+func newBeaconNode(log logrus.FieldLogger, name, address string, headers map[string]string, namespace string, prometheusMetrics bool) sbeacon.Node {
+	sconfig := &sbeacon.Config{
+		Name:    name,
+		Addr:    address,
+		Headers: headers,
+	}
+
+	opts := *sbeacon.DefaultOptions()
 
-		opts.HealthCheck.Interval.Duration = time.Second * 5
-		opts.HealthCheck.SuccessfulResponses = 2
+	opts.HealthCheck.Interval.Duration = time.Second * 5
+	opts.HealthCheck.SuccessfulResponses = 2
+	opts.PrometheusMetrics = prometheusMetrics
 
-		snode := sbeacon.NewNode(log.WithField("upstream", config.Name), sconfig, namespace, opts)
+	snode := sbeacon.NewNode(log.WithField("upstream", name), sconfig, namespace, opts)
 
-		// TODO(sam.calder-mason): Can we re-enable this if we're expecting to use a full beacon node for v1?
-		snode.Options().BeaconSubscription.Enabled = false
+	// TODO(sam.calder-mason): Can we re-enable this if we're expecting to use a full beacon node for v1?
+	snode.Options().BeaconSubscription.Enabled = false
 
-		nodes[i] = &Node{
-			Config: config,
-			Beacon: snode,
+	return snode
+}
+
+// ActiveAddress describes which of the node's addresses requests are going to. Empty if the node has no fallback
+// addresses.
+func (n *Node) ActiveAddress() string {
+	if failover, ok := n.Beacon.(*failoverBeacon); ok {
+		return failover.ActiveAddress()
+	}
+
+	return ""
+}
+
+// ClientType returns the beacon client the node runs, detecting it from the node's version unless it is configured.
+// Returns ClientUnknown until the version is known.
+func (n *Node) ClientType() node.ClientType {
+	if client := n.Config.ConfiguredClientType(); client != node.ClientAuto {
+		return client
+	}
+
+	version, err := n.Beacon.NodeVersion()
+	if err != nil || version == "" {
+		return node.ClientUnknown
+	}
+
+	return node.DetectClientType(version)
+}
+
+// DiffUpstreams compares the running upstreams with a new set of upstream configs. It returns the running upstreams
+// that can be kept as they are, the configs of upstreams that need to be started and the running upstreams that need
+// to be stopped. Upstreams are matched by name; an upstream whose config changed is stopped and started again.
+func DiffUpstreams(running Nodes, configs []node.Config) (kept Nodes, added []node.Config, removed Nodes) {
+	byName := make(map[string]*Node, len(running))
+	for _, upstream := range running {
+		byName[upstream.Config.Name] = upstream
+	}
+
+	for _, config := range configs {
+		upstream, ok := byName[config.Name]
+		if ok && reflect.DeepEqual(upstream.Config, config) {
+			kept = append(kept, upstream)
+
+			delete(byName, config.Name)
+
+			continue
 		}
+
+		added = append(added, config)
 	}
 
-	return nodes
+	for _, upstream := range running {
+		if _, ok := byName[upstream.Config.Name]; ok {
+			removed = append(removed, upstream)
+		}
+	}
+
+	return kept, added, removed
+}
+
+// FetchBlock fetches a block from the node, bounded by the node's request timeout and rate limit.
+func (n *Node) FetchBlock(ctx context.Context, blockID string) (*spec.VersionedSignedBeaconBlock, error) {
+	if err := n.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.Config.RequestTimeout())
+	defer cancel()
+
+	start := time.Now()
+
+	block, err := n.Beacon.FetchBlock(ctx, blockID)
+
+	n.observeRequest(upstreamOperationFetchBlock, start)
+
+	return block, n.annotateTimeout(err, n.Config.RequestTimeout())
+}
+
+// FetchRawBeaconState fetches a beacon state from the node, bounded by the node's state request timeout and rate limit.
+func (n *Node) FetchRawBeaconState(ctx context.Context, stateID, contentType string) ([]byte, error) {
+	if err := n.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.Config.StateRequestTimeout())
+	defer cancel()
+
+	start := time.Now()
+
+	state, err := n.Beacon.FetchRawBeaconState(ctx, stateID, contentType)
+
+	n.observeRequest(upstreamOperationFetchState, start)
+
+	return state, n.annotateTimeout(err, n.Config.StateRequestTimeout())
+}
+
+// FetchDepositSnapshot fetches the deposit snapshot from the node, bounded by the node's request timeout and rate limit.
+func (n *Node) FetchDepositSnapshot(ctx context.Context) (*types.DepositSnapshot, error) {
+	if err := n.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.Config.RequestTimeout())
+	defer cancel()
+
+	start := time.Now()
+
+	snapshot, err := n.Beacon.FetchDepositSnapshot(ctx)
+
+	n.observeRequest(upstreamOperationFetchDepositSnapshot, start)
+
+	return snapshot, n.annotateTimeout(err, n.Config.RequestTimeout())
+}
+
+// FetchFinality fetches the finality checkpoints of the node's head state, bounded by the node's request timeout and
+// rate limit.
+func (n *Node) FetchFinality(ctx context.Context) (*v1.Finality, error) {
+	if err := n.RateLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.Config.RequestTimeout())
+	defer cancel()
+
+	start := time.Now()
+
+	finality, err := n.Beacon.FetchFinality(ctx, "head")
+
+	n.observeRequest(upstreamOperationFetchFinality, start)
+
+	return finality, n.annotateTimeout(err, n.Config.RequestTimeout())
+}
+
+func (n *Node) observeRequest(operation string, start time.Time) {
+	if n.Metrics == nil {
+		return
+	}
+
+	n.Metrics.ObserveUpstreamRequest(n.Config.Name, operation, time.Since(start))
+}
+
+func (n *Node) annotateTimeout(err error, timeout time.Duration) error {
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request to upstream %s timed out after %s: %w", n.Config.Name, timeout, err)
+	}
+
+	return err
 }
 
 func (n Nodes) StartAll(ctx context.Context) error {
@@ -59,12 +270,12 @@ func (n Nodes) StartAll(ctx context.Context) error {
 func (n Nodes) DataProviders(ctx context.Context) Nodes {
 	nodes := []*Node{}
 
-	for _, node := range n {
-		if !node.Config.DataProvider {
+	for _, upstream := range n {
+		if upstream.Config.NodeRole() != node.RoleDataProvider {
 			continue
 		}
 
-		nodes = append(nodes, node)
+		nodes = append(nodes, upstream)
 	}
 
 	return nodes
@@ -129,6 +340,49 @@ func (n Nodes) RandomNode(ctx context.Context) (*Node, error) {
 	return nodes[rand.Intn(len(nodes))], nil
 }
 
+// Shuffled returns the nodes in a random order.
+func (n Nodes) Shuffled(ctx context.Context) Nodes {
+	nodes := make(Nodes, len(n))
+	copy(nodes, n)
+
+	//nolint:gosec // not critical to worry about/will probably be replaced.
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+
+	return nodes
+}
+
+// OnNetwork returns the nodes whose genesis validators root matches the given root.
+// Nodes that don't know their genesis yet are excluded.
+func (n Nodes) OnNetwork(ctx context.Context, genesisValidatorsRoot phase0.Root) Nodes {
+	return n.Filter(ctx, func(node *Node) bool {
+		genesis, err := node.Beacon.Genesis()
+		if err != nil || genesis == nil {
+			return false
+		}
+
+		return genesis.GenesisValidatorsRoot == genesisValidatorsRoot
+	})
+}
+
+// WithDepositContract returns the nodes whose deposit contract matches the given chain id and address.
+// A zero chain id or empty address matches any value. Nodes that don't know their spec yet are excluded.
+func (n Nodes) WithDepositContract(ctx context.Context, chainID uint64, address string) Nodes {
+	return n.Filter(ctx, func(node *Node) bool {
+		sp, err := node.Beacon.Spec()
+		if err != nil || sp == nil {
+			return false
+		}
+
+		if chainID != 0 && sp.DepositChainID != chainID {
+			return false
+		}
+
+		return address == "" || strings.EqualFold(sp.DepositContractAddress, address)
+	})
+}
+
 func (n Nodes) Filter(ctx context.Context, f func(*Node) bool) Nodes {
 	nodes := []*Node{}
 
@@ -0,0 +1,82 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestStateRequestsUseTheStateTimeout(t *testing.T) {
+	upstream := newTestNode("a", &fakeUpstream{state: []byte{0x01}, stateDelay: 50 * time.Millisecond})
+	upstream.Config.Timeout = 10 * time.Millisecond
+	upstream.Config.StateTimeout = time.Second
+
+	if _, err := upstream.FetchRawBeaconState(context.Background(), "head", "application/octet-stream"); err != nil {
+		t.Fatalf("expected the state request to outlast the request timeout, got %v", err)
+	}
+
+	upstream.Config.StateTimeout = 20 * time.Millisecond
+
+	_, err := upstream.FetchRawBeaconState(context.Background(), "head", "application/octet-stream")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if !strings.Contains(err.Error(), "timed out after 20ms") {
+		t.Errorf("expected the error to report the state timeout, got %v", err)
+	}
+}
+
+func TestBlockRequestsUseTheRequestTimeout(t *testing.T) {
+	upstream := newTestNode("a", &fakeUpstream{blockDelay: 50 * time.Millisecond})
+	upstream.Config.StateTimeout = time.Second
+
+	if _, err := upstream.FetchBlock(context.Background(), "head"); err != nil {
+		t.Fatalf("expected the block request to be bounded by the default timeout, got %v", err)
+	}
+
+	upstream.Config.Timeout = 10 * time.Millisecond
+
+	_, err := upstream.FetchBlock(context.Background(), "head")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if !strings.Contains(err.Error(), "request to upstream a timed out after 10ms") {
+		t.Errorf("expected the error to name the upstream and its timeout, got %v", err)
+	}
+}
+
+func TestUpstreamRequestsAreTimed(t *testing.T) {
+	metrics := NewMetrics("nodes_test_beacon")
+
+	upstream := newTestNode("a", &fakeUpstream{blockDelay: 50 * time.Millisecond})
+	upstream.Metrics = metrics
+
+	if _, err := upstream.FetchBlock(context.Background(), "head"); err != nil {
+		t.Fatal(err)
+	}
+
+	histogram, ok := metrics.upstreamRequestDuration.WithLabelValues("a", upstreamOperationFetchBlock).(prometheus.Histogram)
+	if !ok {
+		t.Fatal("expected the upstream request duration to be a histogram")
+	}
+
+	metric := &dto.Metric{}
+	if err := histogram.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := metric.GetHistogram().GetSampleCount(); count != 1 {
+		t.Fatalf("expected 1 request to be timed, got %d", count)
+	}
+
+	if sum := metric.GetHistogram().GetSampleSum(); sum < 0.05 {
+		t.Errorf("expected the request to take at least 50ms, got %fs", sum)
+	}
+}
@@ -0,0 +1,139 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+)
+
+func TestPin(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newTestPinProvider := func(namespace string) (*Default, *fakeUpstream) {
+		d := newTestDownloadProvider(namespace)
+		d.head = finalizedAt(10, 0x02)
+
+		upstream := &fakeUpstream{status: newHealthyStatus(), block: block, state: data}
+		d.nodes = Nodes{newTestNode("a", upstream)}
+
+		return d, upstream
+	}
+
+	t.Run("serves the pinned bundle until unpinned", func(t *testing.T) {
+		d, upstream := newTestPinProvider("test_pin")
+
+		if err := d.Pin(ctx, root); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if upstream.stateFetches != 1 {
+			t.Errorf("expected the pinned bundle to be downloaded, got %d state fetches", upstream.stateFetches)
+		}
+
+		pinned := d.Pinned(ctx)
+		if pinned == nil || pinned.Finalized.Root != root || pinned.Finalized.Epoch != 2 {
+			t.Fatalf("expected the checkpoint at epoch 2 to be pinned, got %v", pinned)
+		}
+
+		if serving := d.serving(); serving != pinned {
+			t.Fatalf("expected the pinned bundle to be served, got %v", serving)
+		}
+
+		// A finalized bundle downloaded while pinned doesn't replace it.
+		if d.serveBundle(finalizedAt(3, 0xbb)) {
+			t.Error("expected another bundle not to be served while pinned")
+		}
+
+		if serving := d.serving(); serving != pinned {
+			t.Errorf("expected the pinned bundle to still be served, got %v", serving)
+		}
+
+		d.Unpin(ctx)
+
+		if pinned := d.Pinned(ctx); pinned != nil {
+			t.Fatalf("expected nothing to be pinned, got %v", pinned)
+		}
+
+		if !d.serveBundle(finalizedAt(3, 0xbb)) {
+			t.Error("expected the finalized head to be served once unpinned")
+		}
+	})
+
+	t.Run("pinning a cached bundle doesn't download it", func(t *testing.T) {
+		d, upstream := newTestPinProvider("test_pin_cached")
+
+		if _, err := d.fetchBundleWithFallback(ctx, root, d.nodes); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := d.Pin(ctx, root); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if upstream.stateFetches != 1 {
+			t.Errorf("expected the cached bundle to be used, got %d state fetches", upstream.stateFetches)
+		}
+	})
+
+	t.Run("the first slot of the epoch was missed", func(t *testing.T) {
+		d, upstream := newTestPinProvider("test_pin_missed_slot")
+
+		// The last block of epoch 1 is the checkpoint of epoch 2 as the slots after it were missed.
+		earlier, earlierData, err := beacontest.Phase0Bundle(62)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		earlierRoot, err := earlier.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		upstream.block = earlier
+		upstream.state = earlierData
+		upstream.blocks = map[string]*spec.VersionedSignedBeaconBlock{"63": nil, "64": nil}
+
+		if err := d.Pin(ctx, earlierRoot); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		pinned := d.Pinned(ctx)
+		if pinned == nil || pinned.Finalized.Root != earlierRoot || pinned.Finalized.Epoch != 2 {
+			t.Fatalf("expected the checkpoint at epoch 2 to be pinned, got %v", pinned)
+		}
+	})
+
+	t.Run("not finalized", func(t *testing.T) {
+		d, upstream := newTestPinProvider("test_pin_not_finalized")
+		d.head = finalizedAt(1, 0x02)
+
+		if err := d.Pin(ctx, root); !errors.Is(err, ErrNotFinalizedCheckpoint) {
+			t.Fatalf("expected %v, got %v", ErrNotFinalizedCheckpoint, err)
+		}
+
+		if pinned := d.Pinned(ctx); pinned != nil {
+			t.Errorf("expected nothing to be pinned, got %v", pinned)
+		}
+
+		if upstream.stateFetches != 0 {
+			t.Errorf("expected nothing to be downloaded, got %d state fetches", upstream.stateFetches)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package beacon
+
+import (
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+)
+
+const (
+	maxSlotsPerEpochOverride  = 8192
+	maxSecondsPerSlotOverride = 3600
+	// maxGenesisTimeOverride is the year 5138 in seconds, or 1973 in milliseconds.
+	maxGenesisTimeOverride = 100_000_000_000
+)
+
+// applySpecOverrides returns a copy of the spec with the configured overrides applied. The spec itself is left alone
+// as it may be shared with the upstream it was fetched from.
+func applySpecOverrides(sp *state.Spec, overrides SpecOverridesConfig) *state.Spec {
+	if sp == nil || (overrides.SlotsPerEpoch == 0 && overrides.SecondsPerSlot == 0) {
+		return sp
+	}
+
+	overridden := *sp
+
+	if overrides.SlotsPerEpoch != 0 {
+		overridden.SlotsPerEpoch = phase0.Slot(overrides.SlotsPerEpoch)
+	}
+
+	if overrides.SecondsPerSlot != 0 {
+		overridden.SecondsPerSlot = state.StringerDuration(time.Duration(overrides.SecondsPerSlot) * time.Second)
+	}
+
+	return &overridden
+}
+
+// applyGenesisOverrides returns a copy of the genesis with the configured overrides applied.
+func applyGenesisOverrides(genesis *v1.Genesis, overrides SpecOverridesConfig) *v1.Genesis {
+	if genesis == nil || overrides.GenesisTime == 0 {
+		return genesis
+	}
+
+	overridden := *genesis
+	overridden.GenesisTime = time.Unix(int64(overrides.GenesisTime), 0)
+
+	return &overridden
+}
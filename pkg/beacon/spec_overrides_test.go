@@ -0,0 +1,91 @@
+package beacon
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+)
+
+func TestApplySpecOverrides(t *testing.T) {
+	fetched := &state.Spec{
+		SlotsPerEpoch:  32,
+		SecondsPerSlot: state.StringerDuration(12 * time.Second),
+	}
+
+	tests := []struct {
+		name           string
+		overrides      SpecOverridesConfig
+		slotsPerEpoch  phase0.Slot
+		secondsPerSlot time.Duration
+	}{
+		{"no overrides", SpecOverridesConfig{}, 32, 12 * time.Second},
+		{"slots per epoch", SpecOverridesConfig{SlotsPerEpoch: 8}, 8, 12 * time.Second},
+		{"seconds per slot", SpecOverridesConfig{SecondsPerSlot: 2}, 32, 2 * time.Second},
+		{"both", SpecOverridesConfig{SlotsPerEpoch: 4, SecondsPerSlot: 6}, 4, 6 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sp := applySpecOverrides(fetched, test.overrides)
+
+			if sp.SlotsPerEpoch != test.slotsPerEpoch {
+				t.Errorf("expected %d slots per epoch, got %d", test.slotsPerEpoch, sp.SlotsPerEpoch)
+			}
+
+			if sp.SecondsPerSlot.AsDuration() != test.secondsPerSlot {
+				t.Errorf("expected %s per slot, got %s", test.secondsPerSlot, sp.SecondsPerSlot.AsDuration())
+			}
+
+			if fetched.SlotsPerEpoch != 32 || fetched.SecondsPerSlot.AsDuration() != 12*time.Second {
+				t.Fatal("the fetched spec was modified")
+			}
+		})
+	}
+}
+
+func TestApplyGenesisOverrides(t *testing.T) {
+	fetched := &v1.Genesis{GenesisTime: time.Unix(1606824023, 0)}
+
+	if g := applyGenesisOverrides(fetched, SpecOverridesConfig{}); !g.GenesisTime.Equal(fetched.GenesisTime) {
+		t.Errorf("expected the fetched genesis time without an override, got %s", g.GenesisTime)
+	}
+
+	g := applyGenesisOverrides(fetched, SpecOverridesConfig{GenesisTime: 1700000000})
+	if !g.GenesisTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("expected the overridden genesis time, got %s", g.GenesisTime)
+	}
+
+	if !fetched.GenesisTime.Equal(time.Unix(1606824023, 0)) {
+		t.Fatal("the fetched genesis was modified")
+	}
+}
+
+func TestSpecOverridesValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides SpecOverridesConfig
+		valid     bool
+	}{
+		{"empty", SpecOverridesConfig{}, true},
+		{"devnet", SpecOverridesConfig{SlotsPerEpoch: 8, SecondsPerSlot: 2, GenesisTime: 1700000000}, true},
+		{"too many slots per epoch", SpecOverridesConfig{SlotsPerEpoch: maxSlotsPerEpochOverride + 1}, false},
+		{"slots too long", SpecOverridesConfig{SecondsPerSlot: maxSecondsPerSlotOverride + 1}, false},
+		{"genesis time in milliseconds", SpecOverridesConfig{GenesisTime: 1700000000000}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.overrides.Validate()
+			if test.valid && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !test.valid && err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package beacon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// checkServedState decodes the state of the bundle being served and checks it hashes to the state root of its
+// block, so a corrupt state is noticed before a client fails to sync from it. A corrupt state is dropped from the
+// store so it is downloaded again, and the provider is unhealthy until the next check.
+func (d *Default) checkServedState(ctx context.Context) error {
+	err := d.verifyServedState(ctx)
+
+	d.servedStateMu.Lock()
+	d.servedStateErr = err
+	d.servedStateMu.Unlock()
+
+	return err
+}
+
+// verifyServedState returns an error if the state of the bundle being served is corrupt. There is nothing to check,
+// and so no error, if no state is being served.
+func (d *Default) verifyServedState(ctx context.Context) error {
+	if !d.shouldDownloadStates() {
+		return nil
+	}
+
+	finality, err := d.Finalized(ctx)
+	if err != nil || finality == nil || finality.Finalized == nil {
+		return nil
+	}
+
+	block, err := d.blocks.GetByRoot(finality.Finalized.Root)
+	if err != nil {
+		// Nothing is cached to check yet.
+		return nil
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return err
+	}
+
+	state, err := d.states.GetByStateRoot(stateRoot)
+	if err != nil {
+		return nil
+	}
+
+	if err := VerifyStateRoot(block, *state); err != nil {
+		d.states.Delete(stateRoot)
+
+		return fmt.Errorf("served state %s for epoch %d is corrupt: %w", eth.RootAsString(stateRoot), finality.Finalized.Epoch, err)
+	}
+
+	return nil
+}
+
+// clearServedStateCorrupt forgets the result of the last check of the served state, e.g. because a different
+// bundle is now being served.
+func (d *Default) clearServedStateCorrupt() {
+	d.servedStateMu.Lock()
+	defer d.servedStateMu.Unlock()
+
+	d.servedStateErr = nil
+}
+
+// servedStateCorrupt returns the error from the last check of the served state, or nil if it was valid.
+func (d *Default) servedStateCorrupt() error {
+	d.servedStateMu.RLock()
+	defer d.servedStateMu.RUnlock()
+
+	return d.servedStateErr
+}
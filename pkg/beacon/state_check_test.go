@@ -0,0 +1,97 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+)
+
+func TestCheckServedState(t *testing.T) {
+	ctx := context.Background()
+
+	st, data := newTestAltairStateSSZ(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestAltairBlock(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDownloadProvider("test_served_state_check")
+	d.nodes = Nodes{newHealthyTestNode("a", finalizedAt(2, 0x01))}
+
+	bundle := finalizedAt(2, 0)
+	bundle.Finalized.Root = root
+	d.servingBundle = bundle
+
+	if err := d.blocks.Add(block, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The store holds a pointer to the state so it can be corrupted in place.
+	stored := data
+	if err := d.states.Add(stateRoot, &stored, time.Now().Add(time.Hour), 64); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.checkServedState(ctx); err != nil {
+		t.Fatalf("expected the served state to pass the check, got %v", err)
+	}
+
+	if healthy, _ := d.Healthy(ctx); !healthy {
+		t.Fatal("expected the provider to be healthy while the served state is valid")
+	}
+
+	stored = data[:len(data)/2]
+
+	if err := d.checkServedState(ctx); err == nil {
+		t.Fatal("expected a truncated served state to fail the check")
+	}
+
+	if healthy, _ := d.Healthy(ctx); healthy {
+		t.Error("expected the provider to be unhealthy while the served state is corrupt")
+	}
+
+	if _, err := d.states.GetByStateRoot(stateRoot); !errors.Is(err, store.ErrStateNotFound) {
+		t.Errorf("expected the corrupt state to be dropped from the store, got %v", err)
+	}
+
+	// With the corrupt state gone there is nothing to check until it is downloaded again.
+	if err := d.checkServedState(ctx); err != nil {
+		t.Fatalf("expected no error without a served state, got %v", err)
+	}
+
+	if healthy, _ := d.Healthy(ctx); !healthy {
+		t.Error("expected the failure to be cleared once the corrupt state was dropped")
+	}
+
+	restored := data
+	if err := d.states.Add(stateRoot, &restored, time.Now().Add(time.Hour), 64); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.checkServedState(ctx); err != nil {
+		t.Fatalf("expected the downloaded again state to pass the check, got %v", err)
+	}
+}
+
+func TestServingAnotherBundleClearsServedStateError(t *testing.T) {
+	d := newTestDownloadProvider("test_served_state_cleared")
+	d.servingBundle = finalizedAt(2, 0x01)
+	d.servedStateErr = errors.New("corrupt")
+
+	d.serveBundle(finalizedAt(3, 0x02))
+
+	if err := d.servedStateCorrupt(); err != nil {
+		t.Errorf("expected the failure of the previous bundle's state to be cleared, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package beacon
+
+import (
+	"errors"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// errUnknownStateVersion is returned by decodeState for a version it can't decode.
+var errUnknownStateVersion = errors.New("unknown state version")
+
+// decodedState holds the fields of a decoded beacon state that every fork shares.
+type decodedState struct {
+	Slot                        phase0.Slot
+	Validators                  []*phase0.Validator
+	Balances                    []phase0.Gwei
+	RANDAOMixes                 []phase0.Root
+	PreviousJustifiedCheckpoint *phase0.Checkpoint
+	CurrentJustifiedCheckpoint  *phase0.Checkpoint
+	FinalizedCheckpoint         *phase0.Checkpoint
+
+	hashTreeRoot func() ([32]byte, error)
+}
+
+// HashTreeRoot returns the root of the whole state, including the fields decodedState doesn't hold.
+func (s *decodedState) HashTreeRoot() (phase0.Root, error) {
+	root, err := s.hashTreeRoot()
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	return phase0.Root(root), nil
+}
+
+// decodeState decodes an SSZ encoded beacon state of the given version. Phase0 states are not decoded as the pinned
+// go-eth2-client leaves eth1_deposit_index out of them, so every field after it would be misread.
+func decodeState(version spec.DataVersion, data []byte) (*decodedState, error) {
+	switch version {
+	case spec.DataVersionAltair:
+		state := &altair.BeaconState{}
+		if err := state.UnmarshalSSZ(data); err != nil {
+			return nil, err
+		}
+
+		return &decodedState{
+			Slot:                        state.Slot,
+			Validators:                  state.Validators,
+			Balances:                    state.Balances,
+			RANDAOMixes:                 state.RANDAOMixes,
+			PreviousJustifiedCheckpoint: state.PreviousJustifiedCheckpoint,
+			CurrentJustifiedCheckpoint:  state.CurrentJustifiedCheckpoint,
+			FinalizedCheckpoint:         state.FinalizedCheckpoint,
+			hashTreeRoot:                state.HashTreeRoot,
+		}, nil
+	case spec.DataVersionBellatrix:
+		state := &bellatrix.BeaconState{}
+		if err := state.UnmarshalSSZ(data); err != nil {
+			return nil, err
+		}
+
+		return &decodedState{
+			Slot:                        state.Slot,
+			Validators:                  state.Validators,
+			Balances:                    state.Balances,
+			RANDAOMixes:                 state.RANDAOMixes,
+			PreviousJustifiedCheckpoint: state.PreviousJustifiedCheckpoint,
+			CurrentJustifiedCheckpoint:  state.CurrentJustifiedCheckpoint,
+			FinalizedCheckpoint:         state.FinalizedCheckpoint,
+			hashTreeRoot:                state.HashTreeRoot,
+		}, nil
+	case spec.DataVersionCapella:
+		state := &capella.BeaconState{}
+		if err := state.UnmarshalSSZ(data); err != nil {
+			return nil, err
+		}
+
+		return &decodedState{
+			Slot:                        state.Slot,
+			Validators:                  state.Validators,
+			Balances:                    state.Balances,
+			RANDAOMixes:                 state.RANDAOMixes,
+			PreviousJustifiedCheckpoint: state.PreviousJustifiedCheckpoint,
+			CurrentJustifiedCheckpoint:  state.CurrentJustifiedCheckpoint,
+			FinalizedCheckpoint:         state.FinalizedCheckpoint,
+			hashTreeRoot:                state.HashTreeRoot,
+		}, nil
+	default:
+		return nil, errUnknownStateVersion
+	}
+}
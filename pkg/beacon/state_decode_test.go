@@ -0,0 +1,66 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestDecodeState(t *testing.T) {
+	altairState := newTestAltairState()
+	altairState.RANDAOMixes[1] = phase0.Root{0x01}
+	altairState.FinalizedCheckpoint = &phase0.Checkpoint{Epoch: 1, Root: phase0.Root{0x01}}
+
+	altairData, err := altairState.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	altairRoot, err := altairState.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		version spec.DataVersion
+		data    []byte
+		root    phase0.Root
+	}{
+		{name: "altair", version: spec.DataVersionAltair, data: altairData, root: altairRoot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := decodeState(tt.version, tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if state.Slot != 64 || state.RANDAOMixes[1] != (phase0.Root{0x01}) || state.FinalizedCheckpoint.Epoch != 1 {
+				t.Errorf("unexpected decoded state: slot %d, finalized %v", state.Slot, state.FinalizedCheckpoint)
+			}
+
+			root, err := state.HashTreeRoot()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if root != tt.root {
+				t.Errorf("expected root %#x, got %#x", tt.root, root)
+			}
+		})
+	}
+}
+
+func TestDecodeStateUnknownVersion(t *testing.T) {
+	_, data := newTestPhase0State(t)
+
+	for _, version := range []spec.DataVersion{spec.DataVersionPhase0, spec.DataVersion(99)} {
+		if _, err := decodeState(version, data); !errors.Is(err, errUnknownStateVersion) {
+			t.Errorf("expected %v for %s, got %v", errUnknownStateVersion, version, err)
+		}
+	}
+}
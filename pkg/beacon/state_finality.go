@@ -0,0 +1,32 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+)
+
+var (
+	// ErrStateFinalityUnsupported is returned when finality can't be derived from a state of the given version.
+	ErrStateFinalityUnsupported = errors.New("deriving finality from this state version is not supported")
+)
+
+// StateFinality returns the finality checkpoints recorded in the SSZ encoded beacon state.
+func StateFinality(version spec.DataVersion, data []byte) (*v1.Finality, error) {
+	state, err := decodeState(version, data)
+	if errors.Is(err, errUnknownStateVersion) {
+		return nil, fmt.Errorf("%w: %s", ErrStateFinalityUnsupported, version.String())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.Finality{
+		PreviousJustified: state.PreviousJustifiedCheckpoint,
+		Justified:         state.CurrentJustifiedCheckpoint,
+		Finalized:         state.FinalizedCheckpoint,
+	}, nil
+}
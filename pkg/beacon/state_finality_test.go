@@ -0,0 +1,51 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestStateFinality(t *testing.T) {
+	state := newTestAltairState()
+
+	state.PreviousJustifiedCheckpoint = &phase0.Checkpoint{Epoch: 8, Root: phase0.Root{0x08}}
+	state.CurrentJustifiedCheckpoint = &phase0.Checkpoint{Epoch: 9, Root: phase0.Root{0x09}}
+	state.FinalizedCheckpoint = &phase0.Checkpoint{Epoch: 7, Root: phase0.Root{0x07}}
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	finality, err := StateFinality(spec.DataVersionAltair, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *finality.PreviousJustified != *state.PreviousJustifiedCheckpoint {
+		t.Errorf("expected previous justified %v, got %v", state.PreviousJustifiedCheckpoint, finality.PreviousJustified)
+	}
+
+	if *finality.Justified != *state.CurrentJustifiedCheckpoint {
+		t.Errorf("expected justified %v, got %v", state.CurrentJustifiedCheckpoint, finality.Justified)
+	}
+
+	if *finality.Finalized != *state.FinalizedCheckpoint {
+		t.Errorf("expected finalized %v, got %v", state.FinalizedCheckpoint, finality.Finalized)
+	}
+}
+
+func TestStateFinalityInvalidState(t *testing.T) {
+	if _, err := StateFinality(spec.DataVersionAltair, []byte{0x01}); err == nil {
+		t.Fatal("expected an error for an invalid state")
+	}
+}
+
+func TestStateFinalityUnsupportedVersion(t *testing.T) {
+	if _, err := StateFinality(spec.DataVersion(99), nil); !errors.Is(err, ErrStateFinalityUnsupported) {
+		t.Fatalf("expected %v, got %v", ErrStateFinalityUnsupported, err)
+	}
+}
@@ -0,0 +1,58 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+var (
+	// ErrStateRandaoUnsupported is returned when the RANDAO mix can't be read from a state of the given version.
+	ErrStateRandaoUnsupported = errors.New("reading the randao mix from this state version is not supported")
+	// ErrRandaoEpochOutOfRange is returned when the state doesn't hold the RANDAO mix of the requested epoch.
+	ErrRandaoEpochOutOfRange = errors.New("state does not hold the randao mix for the epoch")
+)
+
+// RandaoMixes is the RANDAO mixes vector of a beacon state along with the state's slot, which says which epochs the
+// vector still holds.
+type RandaoMixes struct {
+	Slot  phase0.Slot
+	Mixes []phase0.Root
+}
+
+// StateRandaoMixes returns the RANDAO mixes recorded in the SSZ encoded beacon state.
+func StateRandaoMixes(version spec.DataVersion, data []byte) (*RandaoMixes, error) {
+	state, err := decodeState(version, data)
+	if errors.Is(err, errUnknownStateVersion) {
+		return nil, fmt.Errorf("%w: %s", ErrStateRandaoUnsupported, version.String())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(state.RANDAOMixes) == 0 {
+		return nil, errors.New("invalid state")
+	}
+
+	return &RandaoMixes{Slot: state.Slot, Mixes: state.RANDAOMixes}, nil
+}
+
+// Mix returns the RANDAO mix of the epoch. The state only holds the mixes of its own epoch and the epochs within
+// EPOCHS_PER_HISTORICAL_VECTOR before it.
+func (r *RandaoMixes) Mix(slotsPerEpoch phase0.Slot, epoch phase0.Epoch) (phase0.Root, error) {
+	if slotsPerEpoch == 0 {
+		return phase0.Root{}, errors.New("invalid slots per epoch")
+	}
+
+	stateEpoch := phase0.Epoch(r.Slot / slotsPerEpoch)
+	vector := phase0.Epoch(len(r.Mixes))
+
+	if epoch > stateEpoch || epoch+vector <= stateEpoch {
+		return phase0.Root{}, fmt.Errorf("%w: epoch %d, state epoch %d", ErrRandaoEpochOutOfRange, epoch, stateEpoch)
+	}
+
+	return r.Mixes[epoch%vector], nil
+}
@@ -0,0 +1,56 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestStateRandaoMixes(t *testing.T) {
+	state := newTestAltairState()
+
+	state.RANDAOMixes[1] = phase0.Root{0x01}
+	state.RANDAOMixes[2] = phase0.Root{0x02}
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	mixes, err := StateRandaoMixes(spec.DataVersionAltair, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		epoch    phase0.Epoch
+		expected phase0.Root
+		err      error
+	}{
+		{name: "state epoch", epoch: 2, expected: phase0.Root{0x02}},
+		{name: "previous epoch", epoch: 1, expected: phase0.Root{0x01}},
+		{name: "future epoch", epoch: 3, err: ErrRandaoEpochOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mix, err := mixes.Mix(32, tt.epoch)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+
+			if mix != tt.expected {
+				t.Errorf("expected mix %#x, got %#x", tt.expected, mix)
+			}
+		})
+	}
+}
+
+func TestStateRandaoMixesUnsupportedVersion(t *testing.T) {
+	if _, err := StateRandaoMixes(spec.DataVersion(99), nil); !errors.Is(err, ErrStateRandaoUnsupported) {
+		t.Fatalf("expected %v, got %v", ErrStateRandaoUnsupported, err)
+	}
+}
@@ -0,0 +1,60 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// ErrStateValidatorsUnsupported is returned when the validators can't be read from a state of the given version.
+var ErrStateValidatorsUnsupported = errors.New("reading the validators from this state version is not supported")
+
+// farFutureEpoch is FAR_FUTURE_EPOCH, the epoch of events that haven't been scheduled.
+const farFutureEpoch = phase0.Epoch(^uint64(0))
+
+// StateValidators returns every validator in the SSZ encoded beacon state, with its balance and its status as of
+// the state's epoch.
+func StateValidators(version spec.DataVersion, data []byte, slotsPerEpoch phase0.Slot) ([]*v1.Validator, error) {
+	state, err := decodeState(version, data)
+	if errors.Is(err, errUnknownStateVersion) {
+		return nil, fmt.Errorf("%w: %s", ErrStateValidatorsUnsupported, version.String())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	slot, validators, balances := state.Slot, state.Validators, state.Balances
+
+	if slotsPerEpoch == 0 {
+		return nil, errors.New("invalid slots per epoch")
+	}
+
+	if len(balances) != len(validators) {
+		return nil, fmt.Errorf("state has %d validators but %d balances", len(validators), len(balances))
+	}
+
+	epoch := phase0.Epoch(slot / slotsPerEpoch)
+
+	result := make([]*v1.Validator, len(validators))
+
+	for i, validator := range validators {
+		status := v1.ValidatorToState(validator, epoch, farFutureEpoch)
+		if status == v1.ValidatorStateWithdrawalPossible && balances[i] == 0 {
+			// ValidatorToState doesn't know the balance, which tells the two withdrawal states apart.
+			status = v1.ValidatorStateWithdrawalDone
+		}
+
+		result[i] = &v1.Validator{
+			Index:     phase0.ValidatorIndex(i),
+			Balance:   balances[i],
+			Status:    status,
+			Validator: validator,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package beacon
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestStateValidators(t *testing.T) {
+	state := newTestAltairState()
+
+	validators := []*phase0.Validator{
+		// Active.
+		{ActivationEpoch: 0, ExitEpoch: farFutureEpoch, WithdrawableEpoch: farFutureEpoch},
+		// Not yet activated.
+		{ActivationEligibilityEpoch: 1, ActivationEpoch: farFutureEpoch, ExitEpoch: farFutureEpoch, WithdrawableEpoch: farFutureEpoch},
+		// Withdrawable with a balance left.
+		{ActivationEpoch: 0, ExitEpoch: 1, WithdrawableEpoch: 2},
+		// Withdrawable and withdrawn.
+		{ActivationEpoch: 0, ExitEpoch: 1, WithdrawableEpoch: 2},
+	}
+
+	for _, validator := range validators {
+		validator.PublicKey = phase0.BLSPubKey{byte(len(state.Validators) + 1)}
+		validator.WithdrawalCredentials = make([]byte, 32)
+
+		state.Validators = append(state.Validators, validator)
+		state.PreviousEpochParticipation = append(state.PreviousEpochParticipation, altair.ParticipationFlags(0))
+		state.CurrentEpochParticipation = append(state.CurrentEpochParticipation, altair.ParticipationFlags(0))
+		state.InactivityScores = append(state.InactivityScores, 0)
+	}
+
+	state.Balances = []phase0.Gwei{32000000000, 0, 1000, 0}
+
+	data, err := state.MarshalSSZ()
+	if err != nil {
+		t.Fatalf("failed to marshal state: %v", err)
+	}
+
+	got, err := StateValidators(spec.DataVersionAltair, data, 32)
+	if err != nil {
+		t.Fatalf("failed to read validators: %v", err)
+	}
+
+	expected := []v1.ValidatorState{
+		v1.ValidatorStateActiveOngoing,
+		v1.ValidatorStatePendingQueued,
+		v1.ValidatorStateWithdrawalPossible,
+		v1.ValidatorStateWithdrawalDone,
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d validators, got %d", len(expected), len(got))
+	}
+
+	for i, validator := range got {
+		if validator.Index != phase0.ValidatorIndex(i) {
+			t.Errorf("validator %d: expected index %d, got %d", i, i, validator.Index)
+		}
+
+		if validator.Balance != state.Balances[i] {
+			t.Errorf("validator %d: expected balance %d, got %d", i, state.Balances[i], validator.Balance)
+		}
+
+		if validator.Status != expected[i] {
+			t.Errorf("validator %d: expected status %s, got %s", i, expected[i], validator.Status)
+		}
+
+		if validator.Validator.PublicKey != state.Validators[i].PublicKey {
+			t.Errorf("validator %d: expected public key %#x, got %#x", i, state.Validators[i].PublicKey, validator.Validator.PublicKey)
+		}
+	}
+}
+
+func TestStateValidatorsUnsupportedVersion(t *testing.T) {
+	if _, err := StateValidators(spec.DataVersion(99), nil, 32); !errors.Is(err, ErrStateValidatorsUnsupported) {
+		t.Fatalf("expected %v, got %v", ErrStateValidatorsUnsupported, err)
+	}
+}
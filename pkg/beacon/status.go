@@ -1,7 +1,11 @@
 package beacon
 
 import (
+	"sort"
+
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
 )
 
 type UpstreamStatus struct {
@@ -9,4 +13,81 @@ type UpstreamStatus struct {
 	Healthy     bool         `json:"healthy"`
 	Finality    *v1.Finality `json:"finality"`
 	NetworkName string       `json:"network_name,omitempty"`
+	// FinalityBackoff is the interval the upstream's finality polling is currently backed off for.
+	FinalityBackoff string `json:"finality_backoff,omitempty"`
+	// FinalityLagEpochs is how many epochs the upstream's finalized checkpoint is behind the head.
+	FinalityLagEpochs *phase0.Epoch `json:"finality_lag_epochs,omitempty"`
+	// ActiveAddress is which of the upstream's addresses is in use, if it has fallback addresses.
+	ActiveAddress string `json:"active_address,omitempty"`
+	// ClientType is the beacon client the upstream runs, as configured or detected.
+	ClientType string `json:"client_type,omitempty"`
+	// RateLimit is the requests per second allowed to the upstream. Omitted if unlimited.
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	// Error describes why the upstream is excluded from use.
+	Error string `json:"error,omitempty"`
+}
+
+// CacheContents lists what is currently held in the block and state stores.
+type CacheContents struct {
+	Blocks []store.BlockEntry `json:"blocks"`
+	States []store.StateEntry `json:"states"`
+}
+
+// Checkpoint is a finalized checkpoint whose block and state are both cached, so it can be served.
+type Checkpoint struct {
+	Epoch     phase0.Epoch `json:"epoch"`
+	Slot      phase0.Slot  `json:"slot"`
+	BlockRoot string       `json:"block_root"`
+	StateRoot string       `json:"state_root"`
+}
+
+// DownloadableCheckpoints returns the epoch aligned blocks that also have their state cached, newest first.
+func DownloadableCheckpoints(blocks []store.BlockEntry, states []store.StateEntry, slotsPerEpoch phase0.Slot) []Checkpoint {
+	checkpoints := []Checkpoint{}
+
+	if slotsPerEpoch == 0 {
+		return checkpoints
+	}
+
+	stateRoots := make(map[string]struct{}, len(states))
+	for _, state := range states {
+		stateRoots[state.StateRoot] = struct{}{}
+	}
+
+	for _, block := range blocks {
+		if block.Slot%slotsPerEpoch != 0 {
+			continue
+		}
+
+		if _, exists := stateRoots[block.StateRoot]; !exists {
+			continue
+		}
+
+		checkpoints = append(checkpoints, Checkpoint{
+			Epoch:     phase0.Epoch(block.Slot / slotsPerEpoch),
+			Slot:      block.Slot,
+			BlockRoot: block.Root,
+			StateRoot: block.StateRoot,
+		})
+	}
+
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Slot > checkpoints[j].Slot
+	})
+
+	return checkpoints
+}
+
+// FinalityLag returns how many epochs the upstream's finalized checkpoint is behind the head, clamped at 0.
+// Returns false if either finality is unknown.
+func FinalityLag(head, upstream *v1.Finality) (phase0.Epoch, bool) {
+	if head == nil || head.Finalized == nil || upstream == nil || upstream.Finalized == nil {
+		return 0, false
+	}
+
+	if upstream.Finalized.Epoch >= head.Finalized.Epoch {
+		return 0, true
+	}
+
+	return head.Finalized.Epoch - upstream.Finalized.Epoch, true
 }
@@ -0,0 +1,85 @@
+package beacon
+
+import (
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+)
+
+func finalityAt(epoch phase0.Epoch) *v1.Finality {
+	return &v1.Finality{
+		Finalized: &phase0.Checkpoint{Epoch: epoch},
+	}
+}
+
+func TestFinalityLag(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     *v1.Finality
+		upstream *v1.Finality
+		lag      phase0.Epoch
+		ok       bool
+	}{
+		{"behind", finalityAt(100), finalityAt(97), 3, true},
+		{"level", finalityAt(100), finalityAt(100), 0, true},
+		{"ahead is clamped", finalityAt(100), finalityAt(101), 0, true},
+		{"unknown head", nil, finalityAt(100), 0, false},
+		{"unknown upstream", finalityAt(100), &v1.Finality{}, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lag, ok := FinalityLag(test.head, test.upstream)
+
+			if lag != test.lag || ok != test.ok {
+				t.Errorf("FinalityLag() = (%d, %v), want (%d, %v)", lag, ok, test.lag, test.ok)
+			}
+		})
+	}
+}
+
+func TestDownloadableCheckpoints(t *testing.T) {
+	blocks := []store.BlockEntry{
+		{Slot: 0, Root: "0x00", StateRoot: "0xa0"},
+		{Slot: 64, Root: "0x64", StateRoot: "0xa64"},
+		{Slot: 65, Root: "0x65", StateRoot: "0xa65"},
+		{Slot: 96, Root: "0x96", StateRoot: "0xa96"},
+		{Slot: 128, Root: "0x128", StateRoot: "0xa128"},
+	}
+
+	// The state for slot 96 isn't cached, and slot 65 isn't an epoch boundary.
+	states := []store.StateEntry{
+		{Slot: 0, StateRoot: "0xa0"},
+		{Slot: 128, StateRoot: "0xa128"},
+		{Slot: 65, StateRoot: "0xa65"},
+		{Slot: 64, StateRoot: "0xa64"},
+	}
+
+	checkpoints := DownloadableCheckpoints(blocks, states, 32)
+
+	expected := []Checkpoint{
+		{Epoch: 4, Slot: 128, BlockRoot: "0x128", StateRoot: "0xa128"},
+		{Epoch: 2, Slot: 64, BlockRoot: "0x64", StateRoot: "0xa64"},
+		{Epoch: 0, Slot: 0, BlockRoot: "0x00", StateRoot: "0xa0"},
+	}
+
+	if len(checkpoints) != len(expected) {
+		t.Fatalf("expected %d checkpoints, got %d: %v", len(expected), len(checkpoints), checkpoints)
+	}
+
+	for i := range expected {
+		if checkpoints[i] != expected[i] {
+			t.Errorf("checkpoint %d: expected %v, got %v", i, expected[i], checkpoints[i])
+		}
+	}
+}
+
+func TestDownloadableCheckpointsWithoutStates(t *testing.T) {
+	blocks := []store.BlockEntry{{Slot: 64, Root: "0x64", StateRoot: "0xa64"}}
+
+	if checkpoints := DownloadableCheckpoints(blocks, nil, 32); len(checkpoints) != 0 {
+		t.Errorf("expected no checkpoints, got %v", checkpoints)
+	}
+}
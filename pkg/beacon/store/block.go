@@ -2,6 +2,8 @@ package store
 
 import (
 	"errors"
+	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -16,8 +18,16 @@ type Block struct {
 	log   logrus.FieldLogger
 	store *cache.TTLMap
 
-	slotToBlockRoot      sync.Map
-	stateRootToBlockRoot sync.Map
+	// slotToRoot and stateRootToRoot index the blocks in the store by slot and state root.
+	slotToRoot      map[phase0.Slot]phase0.Root
+	stateRootToRoot map[phase0.Root]phase0.Root
+	// parentRootToRoots indexes the blocks in the store by parent root. A parent has more than one child if
+	// blocks from either side of a reorg were stored.
+	parentRootToRoots map[phase0.Root][]phase0.Root
+	// encoded holds the SSZ encoding of stored blocks by root. Blocks are encoded the first time their encoding is
+	// needed, and the encoding is removed with the block.
+	encoded map[phase0.Root][]byte
+	indexMu sync.RWMutex
 }
 
 func NewBlock(log logrus.FieldLogger, config Config, namespace string) *Block {
@@ -25,8 +35,10 @@ func NewBlock(log logrus.FieldLogger, config Config, namespace string) *Block {
 		log:   log.WithField("component", "beacon/store/block"),
 		store: cache.NewTTLMap(config.MaxItems, "block", namespace),
 
-		slotToBlockRoot:      sync.Map{},
-		stateRootToBlockRoot: sync.Map{},
+		slotToRoot:        make(map[phase0.Slot]phase0.Root),
+		stateRootToRoot:   make(map[phase0.Root]phase0.Root),
+		parentRootToRoots: make(map[phase0.Root][]phase0.Root),
+		encoded:           make(map[phase0.Root][]byte),
 	}
 
 	c.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
@@ -49,6 +61,28 @@ func NewBlock(log logrus.FieldLogger, config Config, namespace string) *Block {
 	return c
 }
 
+// OnEvicted registers a callback for blocks evicted from the store to make room for new blocks.
+func (c *Block) OnEvicted(f func(root phase0.Root, slot phase0.Slot)) {
+	c.store.OnItemEvicted(func(key string, value interface{}, expiresAt time.Time) {
+		block, err := c.parseBlock(value)
+		if err != nil {
+			return
+		}
+
+		root, err := block.Root()
+		if err != nil {
+			return
+		}
+
+		slot, err := block.Slot()
+		if err != nil {
+			return
+		}
+
+		go f(root, slot)
+	})
+}
+
 func (c *Block) Add(block *spec.VersionedSignedBeaconBlock, expiresAt time.Time) error {
 	root, err := block.Root()
 	if err != nil {
@@ -65,16 +99,28 @@ func (c *Block) Add(block *spec.VersionedSignedBeaconBlock, expiresAt time.Time)
 		return err
 	}
 
+	parentRoot, err := block.ParentRoot()
+	if err != nil {
+		return err
+	}
+
 	invincible := false
 	if slot == 0 {
 		// Store the genesis block forever.
 		invincible = true
 	}
 
+	c.indexMu.Lock()
+
 	c.store.Add(eth.RootAsString(root), block, expiresAt, invincible)
 
-	c.slotToBlockRoot.Store(slot, root)
-	c.stateRootToBlockRoot.Store(stateRoot, root)
+	c.slotToRoot[slot] = root
+	c.stateRootToRoot[stateRoot] = root
+
+	if !containsRoot(c.parentRootToRoots[parentRoot], root) {
+		c.parentRootToRoots[parentRoot] = append(c.parentRootToRoots[parentRoot], root)
+	}
+	c.indexMu.Unlock()
 
 	c.log.WithFields(
 		logrus.Fields{
@@ -89,6 +135,11 @@ func (c *Block) Add(block *spec.VersionedSignedBeaconBlock, expiresAt time.Time)
 }
 
 func (c *Block) cleanupBlock(block *spec.VersionedSignedBeaconBlock) error {
+	root, err := block.Root()
+	if err != nil {
+		return err
+	}
+
 	slot, err := block.Slot()
 	if err != nil {
 		return err
@@ -99,63 +150,344 @@ func (c *Block) cleanupBlock(block *spec.VersionedSignedBeaconBlock) error {
 		return err
 	}
 
-	c.slotToBlockRoot.Delete(eth.SlotAsString(slot))
-	c.stateRootToBlockRoot.Delete(eth.RootAsString(stateRoot))
+	parentRoot, err := block.ParentRoot()
+	if err != nil {
+		return err
+	}
+
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+
+	// Deletions are handled asynchronously so the block may have been added again since. Only remove the
+	// index entries if they still point at this block.
+	if c.store.Has(eth.RootAsString(root)) {
+		return nil
+	}
+
+	delete(c.encoded, root)
+
+	if c.slotToRoot[slot] == root {
+		delete(c.slotToRoot, slot)
+	}
+
+	if c.stateRootToRoot[stateRoot] == root {
+		delete(c.stateRootToRoot, stateRoot)
+	}
+
+	children := c.parentRootToRoots[parentRoot]
+	for i, child := range children {
+		if child == root {
+			children = append(children[:i:i], children[i+1:]...)
+
+			break
+		}
+	}
+
+	if len(children) == 0 {
+		delete(c.parentRootToRoots, parentRoot)
+	} else {
+		c.parentRootToRoots[parentRoot] = children
+	}
 
 	return nil
 }
 
+func containsRoot(roots []phase0.Root, root phase0.Root) bool {
+	for _, r := range roots {
+		if r == root {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *Block) GetByRoot(root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
 	data, _, err := c.store.Get(eth.RootAsString(root))
 	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, ErrBlockNotFound
+		}
+
 		return nil, err
 	}
 
 	return c.parseBlock(data)
 }
 
-func (c *Block) GetByStateRoot(stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
-	data, ok := c.stateRootToBlockRoot.Load(stateRoot)
-	if !ok {
-		return nil, errors.New("block not found")
+// GetSSZ returns the SSZ encoding of the block with the given root, encoding it the first time it's asked for.
+func (c *Block) GetSSZ(root phase0.Root) ([]byte, error) {
+	block, err := c.GetByRoot(root)
+	if err != nil {
+		return nil, err
 	}
 
-	root, err := c.parseRoot(data)
+	return c.encode(root, block)
+}
+
+// encode returns the SSZ encoding of a stored block, encoding it and keeping the encoding if it isn't known yet.
+func (c *Block) encode(root phase0.Root, block *spec.VersionedSignedBeaconBlock) ([]byte, error) {
+	c.indexMu.RLock()
+	encoded, ok := c.encoded[root]
+	c.indexMu.RUnlock()
+
+	if ok {
+		return encoded, nil
+	}
+
+	encoded, err := MarshalBlockSSZ(block)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to encode block %s: %w", eth.RootAsString(root), err)
+	}
+
+	c.indexMu.Lock()
+	defer c.indexMu.Unlock()
+
+	// The block may have been removed while it was encoded, in which case its cleanup has already run.
+	if c.store.Has(eth.RootAsString(root)) {
+		c.encoded[root] = encoded
+	}
+
+	return encoded, nil
+}
+
+func (c *Block) GetByStateRoot(stateRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	c.indexMu.RLock()
+	root, ok := c.stateRootToRoot[stateRoot]
+	c.indexMu.RUnlock()
+
+	if !ok {
+		return nil, ErrBlockNotFound
 	}
 
 	return c.GetByRoot(root)
 }
 
 func (c *Block) GetBySlot(slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, error) {
-	data, ok := c.slotToBlockRoot.Load(slot)
+	c.indexMu.RLock()
+	root, ok := c.slotToRoot[slot]
+	c.indexMu.RUnlock()
+
 	if !ok {
-		return nil, errors.New("block not found")
+		return nil, ErrBlockNotFound
+	}
+
+	return c.GetByRoot(root)
+}
+
+// GetByParentRoot returns the child of the block with the given root. If more than one child is stored, the one
+// indexed by its slot is returned as it is the canonical one, or ErrMultipleChildren if that is unknown.
+func (c *Block) GetByParentRoot(parentRoot phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	c.indexMu.RLock()
+	defer c.indexMu.RUnlock()
+
+	children := c.parentRootToRoots[parentRoot]
+
+	switch len(children) {
+	case 0:
+		return nil, ErrBlockNotFound
+	case 1:
+		return c.GetByRoot(children[0])
+	}
+
+	var canonical *spec.VersionedSignedBeaconBlock
+
+	for _, root := range children {
+		block, err := c.GetByRoot(root)
+		if err != nil {
+			continue
+		}
+
+		slot, err := block.Slot()
+		if err != nil {
+			return nil, err
+		}
+
+		if c.slotToRoot[slot] != root {
+			continue
+		}
+
+		if canonical != nil {
+			return nil, ErrMultipleChildren
+		}
+
+		canonical = block
+	}
+
+	if canonical == nil {
+		return nil, ErrMultipleChildren
+	}
+
+	return canonical, nil
+}
+
+// BlockEntry describes a block held in the store.
+type BlockEntry struct {
+	Slot      phase0.Slot `json:"slot"`
+	Root      string      `json:"root"`
+	StateRoot string      `json:"state_root"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// List returns the blocks currently held in the store.
+func (c *Block) List() []BlockEntry {
+	entries := []BlockEntry{}
+
+	for _, key := range c.store.Keys() {
+		data, expiresAt, err := c.store.Get(key)
+		if err != nil {
+			// The block was evicted while we were listing.
+			continue
+		}
+
+		block, err := c.parseBlock(data)
+		if err != nil {
+			continue
+		}
+
+		slot, err := block.Slot()
+		if err != nil {
+			continue
+		}
+
+		stateRoot, err := block.StateRoot()
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, BlockEntry{
+			Slot:      slot,
+			Root:      key,
+			StateRoot: eth.RootAsString(stateRoot),
+			ExpiresAt: expiresAt,
+		})
 	}
 
-	root, err := c.parseRoot(data)
+	return entries
+}
+
+// Persist writes all cached blocks to the given directory so they can be loaded after a restart.
+func (c *Block) Persist(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	items := []persistedItem{}
+
+	for _, key := range c.store.Keys() {
+		data, expiresAt, err := c.store.Get(key)
+		if err != nil {
+			// The block was evicted while we were persisting.
+			continue
+		}
+
+		block, err := c.parseBlock(data)
+		if err != nil {
+			return err
+		}
+
+		slot, err := block.Slot()
+		if err != nil {
+			return err
+		}
+
+		root, err := parsePersistedRoot(key)
+		if err != nil {
+			return err
+		}
+
+		encoded, err := c.encode(root, block)
+		if err != nil {
+			return err
+		}
+
+		if err := writePersistedItem(dir, key, encoded); err != nil {
+			return err
+		}
+
+		items = append(items, persistedItem{
+			Key:       key,
+			Slot:      slot,
+			Version:   block.Version.String(),
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	if err := writePersistedIndex(dir, items); err != nil {
+		return err
+	}
+
+	c.log.WithField("count", len(items)).WithField("dir", dir).Info("Persisted blocks to disk")
+
+	return nil
+}
+
+// Load adds all blocks previously persisted to the given directory to the store. Blocks that have
+// expired since they were persisted are dropped, as are blocks that can't be read or don't hash to the root they
+// were persisted under.
+func (c *Block) Load(dir string) error {
+	items, err := readPersistedIndex(dir)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return c.GetByRoot(root)
+	loaded := 0
+
+	for _, item := range items {
+		if item.expired(time.Now()) {
+			c.log.WithField("block_root", item.Key).Debug("Skipping loading expired block")
+
+			continue
+		}
+
+		block, err := c.loadBlock(dir, item)
+		if err != nil {
+			c.log.WithError(err).WithField("block_root", item.Key).Warn("Skipping loading persisted block")
+
+			continue
+		}
+
+		if err := c.Add(block, item.ExpiresAt); err != nil {
+			return err
+		}
+
+		loaded++
+	}
+
+	c.log.WithField("count", loaded).WithField("dir", dir).Info("Loaded blocks from disk")
+
+	return nil
 }
 
-func (c *Block) parseBlock(data interface{}) (*spec.VersionedSignedBeaconBlock, error) {
-	block, ok := data.(*spec.VersionedSignedBeaconBlock)
-	if !ok {
-		return nil, errors.New("invalid block type")
+// loadBlock reads a persisted block and checks it's the block it was persisted as.
+func (c *Block) loadBlock(dir string, item persistedItem) (*spec.VersionedSignedBeaconBlock, error) {
+	data, err := readPersistedItem(dir, item.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := UnmarshalBlockSSZ(item.Version, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root from block: %w", err)
+	}
+
+	if eth.RootAsString(root) != item.Key {
+		return nil, fmt.Errorf("block has root %s", eth.RootAsString(root))
 	}
 
 	return block, nil
 }
 
-func (c *Block) parseRoot(data interface{}) (phase0.Root, error) {
-	root, ok := data.(phase0.Root)
+func (c *Block) parseBlock(data interface{}) (*spec.VersionedSignedBeaconBlock, error) {
+	block, ok := data.(*spec.VersionedSignedBeaconBlock)
 	if !ok {
-		return phase0.Root{}, errors.New("invalid root")
+		return nil, errors.New("invalid block type")
 	}
 
-	return root, nil
+	return block, nil
 }
@@ -0,0 +1,354 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/sirupsen/logrus"
+)
+
+var testNamespaces int32
+
+// testNamespace returns a unique metrics namespace so stores can be created repeatedly.
+func testNamespace(prefix string) string {
+	return fmt.Sprintf("%s_%d", prefix, atomic.AddInt32(&testNamespaces, 1))
+}
+
+func newTestBlock(slot phase0.Slot) *spec.VersionedSignedBeaconBlock {
+	return &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionPhase0,
+		Phase0: &phase0.SignedBeaconBlock{
+			Message: &phase0.BeaconBlock{
+				Slot:      slot,
+				StateRoot: phase0.Root{byte(slot), byte(slot >> 8)},
+				Body: &phase0.BeaconBlockBody{
+					ETH1Data: &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+				},
+			},
+		},
+	}
+}
+
+func newTestBlockStore(prefix string, blocks int) *Block {
+	store := NewBlock(logrus.New(), Config{MaxItems: blocks}, testNamespace(prefix))
+
+	for i := 1; i <= blocks; i++ {
+		if err := store.Add(newTestBlock(phase0.Slot(i)), time.Now().Add(time.Hour)); err != nil {
+			panic(err)
+		}
+	}
+
+	return store
+}
+
+func TestBlockIndexesAreRemovedWithTheBlock(t *testing.T) {
+	store := NewBlock(logrus.New(), Config{MaxItems: 2}, testNamespace("test_block_index"))
+
+	now := time.Now()
+
+	for i := 1; i <= 3; i++ {
+		if err := store.Add(newTestBlock(phase0.Slot(i)), now.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Slot 1 expires first so is evicted to make room for slot 3. Deletions are cleaned up asynchronously.
+	deadline := time.Now().Add(time.Second)
+
+	for {
+		store.indexMu.RLock()
+		slots, stateRoots := len(store.slotToRoot), len(store.stateRootToRoot)
+		// The test blocks all share the zero parent root.
+		children := len(store.parentRootToRoots[phase0.Root{}])
+		store.indexMu.RUnlock()
+
+		if slots == 2 && stateRoots == 2 && children == 2 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the evicted block to be removed from the indexes, got %d slots, %d state roots and %d children", slots, stateRoots, children)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := store.GetBySlot(1); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("expected %v, got %v", ErrBlockNotFound, err)
+	}
+
+	if _, err := store.GetByStateRoot(phase0.Root{1}); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("expected %v, got %v", ErrBlockNotFound, err)
+	}
+
+	for _, slot := range []phase0.Slot{2, 3} {
+		block, err := store.GetBySlot(slot)
+		if err != nil {
+			t.Fatalf("expected slot %d to be found, got %v", slot, err)
+		}
+
+		if got, _ := block.Slot(); got != slot {
+			t.Errorf("expected slot %d, got %d", slot, got)
+		}
+
+		if _, err := store.GetByStateRoot(phase0.Root{byte(slot)}); err != nil {
+			t.Errorf("expected state root for slot %d to be found, got %v", slot, err)
+		}
+	}
+}
+
+func TestBlockGetByParentRoot(t *testing.T) {
+	store := NewBlock(logrus.New(), Config{MaxItems: 10}, testNamespace("test_block_parent"))
+
+	withParent := func(block *spec.VersionedSignedBeaconBlock, parent *spec.VersionedSignedBeaconBlock) *spec.VersionedSignedBeaconBlock {
+		root, err := parent.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		block.Phase0.Message.ParentRoot = root
+
+		return block
+	}
+
+	parent := newTestBlock(1)
+	child := withParent(newTestBlock(2), parent)
+
+	for _, block := range []*spec.VersionedSignedBeaconBlock{parent, child} {
+		if err := store.Add(block, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parentRoot, err := parent.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetByParentRoot(parentRoot)
+	if err != nil {
+		t.Fatalf("expected the child to be found, got %v", err)
+	}
+
+	if slot, _ := got.Slot(); slot != 2 {
+		t.Errorf("expected the child at slot 2, got %d", slot)
+	}
+
+	// A sibling at the same slot replaces the child as the canonical block at that slot.
+	sibling := withParent(newTestBlock(2), parent)
+	sibling.Phase0.Message.ProposerIndex = 1
+
+	if err := store.Add(sibling, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	siblingRoot, err := sibling.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = store.GetByParentRoot(parentRoot)
+	if err != nil {
+		t.Fatalf("expected the canonical child to be found, got %v", err)
+	}
+
+	if root, _ := got.Root(); root != siblingRoot {
+		t.Error("expected the child indexed by its slot to be returned")
+	}
+
+	// A sibling at another slot is also indexed by its slot, so which child is canonical is unknown.
+	if err := store.Add(withParent(newTestBlock(3), parent), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetByParentRoot(parentRoot); !errors.Is(err, ErrMultipleChildren) {
+		t.Errorf("expected %v, got %v", ErrMultipleChildren, err)
+	}
+
+	if _, err := store.GetByParentRoot(siblingRoot); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("expected %v for a block without children, got %v", ErrBlockNotFound, err)
+	}
+}
+
+func BenchmarkBlockGetBySlot(b *testing.B) {
+	store := newTestBlockStore("bench_block_slot", 200)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := store.GetBySlot(phase0.Slot(i%200 + 1)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBlockGetByStateRoot(b *testing.B) {
+	store := newTestBlockStore("bench_block_state_root", 200)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		slot := i%200 + 1
+
+		if _, err := store.GetByStateRoot(phase0.Root{byte(slot), byte(slot >> 8)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBlockGetSSZ(t *testing.T) {
+	store := NewBlock(logrus.New(), Config{MaxItems: 1}, testNamespace("test_block_ssz"))
+
+	block := newTestBlock(1)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetSSZ(root); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("expected %v, got %v", ErrBlockNotFound, err)
+	}
+
+	if err := store.Add(block, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if encoded := encodedBlocks(store); encoded != 0 {
+		t.Errorf("expected blocks to not be encoded until their encoding is needed, got %d encodings", encoded)
+	}
+
+	expected, err := block.Phase0.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		encoded, err := store.GetSSZ(root)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !bytes.Equal(encoded, expected) {
+			t.Error("expected the encoding to match the block's SSZ encoding")
+		}
+	}
+
+	if encoded := encodedBlocks(store); encoded != 1 {
+		t.Errorf("expected the encoding to be kept, got %d encodings", encoded)
+	}
+
+	// Adding a copy of the block keeps the stored block and its encoding.
+	if err := store.Add(newTestBlock(1), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetSSZ(root); err != nil {
+		t.Errorf("expected the encoding to still be served, got %v", err)
+	}
+
+	// Adding another block evicts the first, which must take its encoding with it.
+	if err := store.Add(newTestBlock(2), time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+
+	for encodedBlocks(store) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the encoding to be removed with the block")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := store.GetSSZ(root); !errors.Is(err, ErrBlockNotFound) {
+		t.Errorf("expected %v for an evicted block, got %v", ErrBlockNotFound, err)
+	}
+}
+
+func TestBlockPersistReusesTheEncoding(t *testing.T) {
+	store := newTestBlockStore("test_block_persist_ssz", 2)
+
+	if err := store.Persist(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	if encoded := encodedBlocks(store); encoded != 2 {
+		t.Errorf("expected persisting to keep the encodings for serving, got %d encodings", encoded)
+	}
+}
+
+func encodedBlocks(store *Block) int {
+	store.indexMu.RLock()
+	defer store.indexMu.RUnlock()
+
+	return len(store.encoded)
+}
+
+func BenchmarkBlockMarshalSSZ(b *testing.B) {
+	block := newTestBlock(1)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := MarshalBlockSSZ(block); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkBlockGetSSZ measures serving a stored block as SSZ once it has been encoded.
+func BenchmarkBlockGetSSZ(b *testing.B) {
+	store := newTestBlockStore("bench_block_ssz", 1)
+
+	root, err := newTestBlock(1).Root()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := store.GetSSZ(root); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBlockList(t *testing.T) {
+	blocks := newTestBlockStore("test_block_list", 3)
+
+	entries := blocks.List()
+	if len(entries) != 3 {
+		t.Fatalf("expected %d blocks to be listed, got %d", 3, len(entries))
+	}
+
+	for _, entry := range entries {
+		block := newTestBlock(entry.Slot)
+
+		root, err := block.Root()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if entry.Root != eth.RootAsString(root) {
+			t.Errorf("expected the block at slot %d to be listed with root %s, got %s", entry.Slot, eth.RootAsString(root), entry.Root)
+		}
+
+		if expected := eth.RootAsString(block.Phase0.Message.StateRoot); entry.StateRoot != expected {
+			t.Errorf("expected the block at slot %d to be listed with state root %s, got %s", entry.Slot, expected, entry.StateRoot)
+		}
+
+		if entry.ExpiresAt.IsZero() {
+			t.Errorf("expected the block at slot %d to be listed with its expiry", entry.Slot)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import "errors"
 
 type Config struct {
 	MaxItems int `yaml:"max_items"`
+	// MaxBytes is the total size of the items the store can hold. 0 is unlimited. Only supported by the state store.
+	MaxBytes int64 `yaml:"max_bytes"`
 }
 
 func (c *Config) Validate() error {
@@ -11,5 +13,9 @@ func (c *Config) Validate() error {
 		return errors.New("max_items must be at least 1")
 	}
 
+	if c.MaxBytes < 0 {
+		return errors.New("max_bytes cannot be negative")
+	}
+
 	return nil
 }
@@ -47,6 +47,10 @@ func (d *DepositSnapshot) Add(epoch phase0.Epoch, snapshot *types.DepositSnapsho
 func (d *DepositSnapshot) GetByEpoch(epoch phase0.Epoch) (*types.DepositSnapshot, error) {
 	data, _, err := d.store.Get(eth.EpochAsString(epoch))
 	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, ErrDepositSnapshotNotFound
+		}
+
 		return nil, err
 	}
 
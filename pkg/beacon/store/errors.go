@@ -0,0 +1,20 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethpandaops/checkpointz/pkg/cache"
+)
+
+var (
+	// ErrBlockNotFound is returned when a block is not in the store.
+	ErrBlockNotFound = fmt.Errorf("block %w", cache.ErrNotFound)
+	// ErrStateNotFound is returned when a beacon state is not in the store.
+	ErrStateNotFound = fmt.Errorf("state %w", cache.ErrNotFound)
+	// ErrDepositSnapshotNotFound is returned when a deposit snapshot is not in the store.
+	ErrDepositSnapshotNotFound = fmt.Errorf("deposit snapshot %w", cache.ErrNotFound)
+	// ErrMultipleChildren is returned when a block has more than one child in the store and which is canonical
+	// is unknown.
+	ErrMultipleChildren = errors.New("block has multiple children and the canonical one is unknown")
+)
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+const (
+	persistIndexFile = "index.json"
+	persistDataExt   = ".ssz"
+	persistTempExt   = ".tmp"
+)
+
+// persistedItem describes a cached item that has been written to disk.
+type persistedItem struct {
+	Key       string      `json:"key"`
+	Slot      phase0.Slot `json:"slot"`
+	Version   string      `json:"version,omitempty"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// expired returns true if the item expired before now. The genesis block and state are stored forever so never
+// expire, whatever their expiry says.
+func (i persistedItem) expired(now time.Time) bool {
+	return i.Slot != 0 && i.ExpiresAt.Before(now)
+}
+
+func writePersistedItem(dir, key string, data []byte) error {
+	return writeFileAtomically(filepath.Join(dir, key+persistDataExt), data)
+}
+
+// writeFileAtomically writes data to a temporary file next to path and renames it over path, so a crash part way
+// through leaves either the old file or the new one and never a truncated one.
+func writeFileAtomically(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*"+persistTempExt)
+	if err != nil {
+		return err
+	}
+
+	if err := writeAndClose(f, data); err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	return nil
+}
+
+// writeAndClose writes data to f and flushes it to disk before closing it.
+func writeAndClose(f *os.File, data []byte) error {
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+func readPersistedItem(dir, key string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, key+persistDataExt))
+}
+
+// writePersistedIndex writes the index of persisted items and removes any data files that are no longer referenced,
+// along with temporary files left behind by an earlier write that was interrupted.
+func writePersistedIndex(dir string, items []persistedItem) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFileAtomically(filepath.Join(dir, persistIndexFile), data); err != nil {
+		return err
+	}
+
+	keep := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		keep[item.Key+persistDataExt] = struct{}{}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if !strings.HasSuffix(entry.Name(), persistDataExt) && !strings.HasSuffix(entry.Name(), persistTempExt) {
+			continue
+		}
+
+		if _, exists := keep[entry.Name()]; exists {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readPersistedIndex reads the index of persisted items. A missing index is not an error.
+func readPersistedIndex(dir string) ([]persistedItem, error) {
+	items := []persistedItem{}
+
+	data, err := os.ReadFile(filepath.Join(dir, persistIndexFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return items, nil
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// MarshalBlockSSZ SSZ encodes a signed block of any supported fork version.
+func MarshalBlockSSZ(block *spec.VersionedSignedBeaconBlock) ([]byte, error) {
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		return block.Phase0.MarshalSSZ()
+	case spec.DataVersionAltair:
+		return block.Altair.MarshalSSZ()
+	case spec.DataVersionBellatrix:
+		return block.Bellatrix.MarshalSSZ()
+	case spec.DataVersionCapella:
+		return block.Capella.MarshalSSZ()
+	default:
+		return nil, fmt.Errorf("unknown block version: %s", block.Version.String())
+	}
+}
+
+// UnmarshalBlockSSZ decodes an SSZ encoded signed block of the named fork version, e.g. "capella".
+func UnmarshalBlockSSZ(version string, data []byte) (*spec.VersionedSignedBeaconBlock, error) {
+	block := &spec.VersionedSignedBeaconBlock{}
+
+	switch version {
+	case spec.DataVersionPhase0.String():
+		block.Version = spec.DataVersionPhase0
+		block.Phase0 = &phase0.SignedBeaconBlock{}
+
+		return block, block.Phase0.UnmarshalSSZ(data)
+	case spec.DataVersionAltair.String():
+		block.Version = spec.DataVersionAltair
+		block.Altair = &altair.SignedBeaconBlock{}
+
+		return block, block.Altair.UnmarshalSSZ(data)
+	case spec.DataVersionBellatrix.String():
+		block.Version = spec.DataVersionBellatrix
+		block.Bellatrix = &bellatrix.SignedBeaconBlock{}
+
+		return block, block.Bellatrix.UnmarshalSSZ(data)
+	case spec.DataVersionCapella.String():
+		block.Version = spec.DataVersionCapella
+		block.Capella = &capella.SignedBeaconBlock{}
+
+		return block, block.Capella.UnmarshalSSZ(data)
+	default:
+		return nil, fmt.Errorf("unknown block version: %s", version)
+	}
+}
+
+func parsePersistedRoot(key string) (phase0.Root, error) {
+	root := phase0.Root{}
+
+	b, err := hex.DecodeString(strings.TrimPrefix(key, "0x"))
+	if err != nil {
+		return root, fmt.Errorf("invalid persisted root %s: %w", key, err)
+	}
+
+	if len(b) != len(root) {
+		return root, fmt.Errorf("incorrect length %d for persisted root %s", len(b), key)
+	}
+
+	copy(root[:], b)
+
+	return root, nil
+}
@@ -0,0 +1,251 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBlockPersistRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	blocks := NewBlock(logrus.New(), Config{MaxItems: 10}, testNamespace("test_block_persist"))
+
+	// The genesis block is stored forever, whatever its expiry says.
+	genesis := newTestBlock(0)
+	live := newTestBlock(1)
+
+	if err := blocks.Add(genesis, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := blocks.Add(live, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := blocks.Persist(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewBlock(logrus.New(), Config{MaxItems: 10}, testNamespace("test_block_persist"))
+
+	if err := loaded.Load(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, slot := range []phase0.Slot{0, 1} {
+		if _, err := loaded.GetBySlot(slot); err != nil {
+			t.Errorf("expected the block at slot %d to be loaded, got %v", slot, err)
+		}
+	}
+
+	assertNoTemporaryFiles(t, dir)
+}
+
+func TestBlockLoadSkipsCorruptBlocks(t *testing.T) {
+	dir := t.TempDir()
+
+	blocks := newTestBlockStore("test_block_corrupt", 3)
+
+	if err := blocks.Persist(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := persistedKeysBySlot(t, dir)
+
+	// Slot 1 is truncated and slot 2 is swapped for slot 3's block, so it doesn't hash to the root it's stored under.
+	truncate(t, dir, keys[1])
+
+	swapped, err := os.ReadFile(filepath.Join(dir, keys[3]+persistDataExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, keys[2]+persistDataExt), swapped, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewBlock(logrus.New(), Config{MaxItems: 10}, testNamespace("test_block_corrupt"))
+
+	if err := loaded.Load(dir); err != nil {
+		t.Fatalf("expected corrupt blocks to be skipped, got %v", err)
+	}
+
+	for slot, expected := range map[phase0.Slot]bool{1: false, 2: false, 3: true} {
+		if _, err := loaded.GetBySlot(slot); (err == nil) != expected {
+			t.Errorf("expected the block at slot %d to be loaded to be %v, got %v", slot, expected, err)
+		}
+	}
+}
+
+func TestBeaconStatePersistRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	states := NewBeaconState(logrus.New(), Config{MaxItems: 10}, testNamespace("test_state_persist"))
+
+	data := map[phase0.Root][]byte{
+		{0x00}: []byte("genesis"),
+		{0x01}: []byte("live"),
+		{0x02}: []byte("expired"),
+	}
+
+	add := func(stateRoot phase0.Root, slot phase0.Slot, expiresAt time.Time) {
+		state := data[stateRoot]
+
+		if err := states.Add(stateRoot, &state, expiresAt, slot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add(phase0.Root{0x00}, 0, time.Now().Add(-time.Hour))
+	add(phase0.Root{0x01}, 1, time.Now().Add(time.Hour))
+	// Persisting takes long enough for an item to expire before the next start.
+	add(phase0.Root{0x02}, 2, time.Now().Add(100*time.Millisecond))
+
+	if err := states.Persist(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	verified := map[phase0.Root]bool{}
+
+	loaded := NewBeaconState(logrus.New(), Config{MaxItems: 10}, testNamespace("test_state_persist"))
+
+	err := loaded.Load(dir, func(stateRoot phase0.Root, state []byte) error {
+		verified[stateRoot] = true
+
+		if !bytes.Equal(state, data[stateRoot]) {
+			return errors.New("unexpected state")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for stateRoot, expected := range map[phase0.Root]bool{{0x00}: true, {0x01}: true, {0x02}: false} {
+		if _, err := loaded.GetByStateRoot(stateRoot); (err == nil) != expected {
+			t.Errorf("expected state %s to be loaded to be %v, got %v", eth.RootAsString(stateRoot), expected, err)
+		}
+
+		if verified[stateRoot] != expected {
+			t.Errorf("expected state %s to be verified to be %v", eth.RootAsString(stateRoot), expected)
+		}
+	}
+
+	assertNoTemporaryFiles(t, dir)
+}
+
+func TestBeaconStateLoadSkipsStatesThatFailVerification(t *testing.T) {
+	dir := t.TempDir()
+
+	states := NewBeaconState(logrus.New(), Config{MaxItems: 10}, testNamespace("test_state_corrupt"))
+
+	for i := byte(1); i <= 2; i++ {
+		state := []byte{i}
+
+		if err := states.Add(phase0.Root{i}, &state, time.Now().Add(time.Hour), phase0.Slot(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := states.Persist(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	truncate(t, dir, eth.RootAsString(phase0.Root{0x01}))
+
+	loaded := NewBeaconState(logrus.New(), Config{MaxItems: 10}, testNamespace("test_state_corrupt"))
+
+	err := loaded.Load(dir, func(stateRoot phase0.Root, state []byte) error {
+		if len(state) == 0 {
+			return errors.New("state root mismatch")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected states failing verification to be skipped, got %v", err)
+	}
+
+	if _, err := loaded.GetByStateRoot(phase0.Root{0x01}); !errors.Is(err, ErrStateNotFound) {
+		t.Errorf("expected the corrupt state to be skipped, got %v", err)
+	}
+
+	if _, err := loaded.GetByStateRoot(phase0.Root{0x02}); err != nil {
+		t.Errorf("expected the intact state to be loaded, got %v", err)
+	}
+}
+
+func TestPersistRemovesInterruptedWrites(t *testing.T) {
+	dir := t.TempDir()
+
+	leftover := filepath.Join(dir, persistIndexFile+".123"+persistTempExt)
+	if err := os.WriteFile(leftover, []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := newTestBlockStore("test_block_interrupted", 1).Persist(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	assertNoTemporaryFiles(t, dir)
+}
+
+// persistedKeysBySlot returns the keys in the persisted index, by slot.
+func persistedKeysBySlot(t *testing.T, dir string) map[phase0.Slot]string {
+	t.Helper()
+
+	items, err := readPersistedIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[phase0.Slot]string{}
+	for _, item := range items {
+		keys[item.Slot] = item.Key
+	}
+
+	return keys
+}
+
+// truncate cuts the persisted item in half, like a write that was interrupted.
+func truncate(t *testing.T, dir, key string) {
+	t.Helper()
+
+	path := filepath.Join(dir, key+persistDataExt)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Truncate(path, info.Size()/2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertNoTemporaryFiles(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), persistTempExt) {
+			t.Errorf("expected no temporary files to be left behind, found %s", entry.Name())
+		}
+	}
+}
@@ -2,6 +2,8 @@ package store
 
 import (
 	"errors"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -13,6 +15,8 @@ import (
 type BeaconState struct {
 	store *cache.TTLMap
 	log   logrus.FieldLogger
+
+	stateRootToSlot sync.Map
 }
 
 func NewBeaconState(log logrus.FieldLogger, config Config, namespace string) *BeaconState {
@@ -23,20 +27,42 @@ func NewBeaconState(log logrus.FieldLogger, config Config, namespace string) *Be
 
 	c.store.OnItemDeleted(func(key string, value interface{}, expiredAt time.Time) {
 		c.log.WithField("state_root", key).WithField("expired_at", expiredAt.String()).Debug("State was deleted from the cache")
+
+		c.stateRootToSlot.Delete(key)
 	})
 
+	c.store.SetMaxSize(config.MaxBytes)
 	c.store.EnableMetrics(namespace)
 
 	return c
 }
 
+// OnEvicted registers a callback for states evicted from the store to make room for new states.
+func (c *BeaconState) OnEvicted(f func(stateRoot phase0.Root, slot phase0.Slot)) {
+	c.store.OnItemEvicted(func(key string, value interface{}, expiresAt time.Time) {
+		stateRoot, err := parsePersistedRoot(key)
+		if err != nil {
+			return
+		}
+
+		slot, ok := c.stateRootToSlot.Load(key)
+		if !ok {
+			return
+		}
+
+		go f(stateRoot, slot.(phase0.Slot))
+	})
+}
+
 func (c *BeaconState) Add(stateRoot phase0.Root, state *[]byte, expiresAt time.Time, slot phase0.Slot) error {
 	invincible := false
 	if slot == 0 {
 		invincible = true
 	}
 
-	c.store.Add(eth.RootAsString(stateRoot), state, expiresAt, invincible)
+	c.store.AddWithSize(eth.RootAsString(stateRoot), state, expiresAt, invincible, int64(len(*state)))
+
+	c.stateRootToSlot.Store(eth.RootAsString(stateRoot), slot)
 
 	c.log.WithFields(
 		logrus.Fields{
@@ -48,15 +74,180 @@ func (c *BeaconState) Add(stateRoot phase0.Root, state *[]byte, expiresAt time.T
 	return nil
 }
 
+// Pin protects the state from being evicted to make room for other states. It still expires.
+func (c *BeaconState) Pin(stateRoot phase0.Root) error {
+	return c.setPinned(stateRoot, true)
+}
+
+// Unpin allows the state to be evicted to make room for other states again.
+func (c *BeaconState) Unpin(stateRoot phase0.Root) error {
+	return c.setPinned(stateRoot, false)
+}
+
+func (c *BeaconState) setPinned(stateRoot phase0.Root, pinned bool) error {
+	if err := c.store.SetPinned(eth.RootAsString(stateRoot), pinned); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return ErrStateNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the state from the store.
+func (c *BeaconState) Delete(stateRoot phase0.Root) {
+	c.store.Delete(eth.RootAsString(stateRoot))
+}
+
 func (c *BeaconState) GetByStateRoot(stateRoot phase0.Root) (*[]byte, error) {
 	data, _, err := c.store.Get(eth.RootAsString(stateRoot))
 	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return nil, ErrStateNotFound
+		}
+
 		return nil, err
 	}
 
 	return c.parseState(data)
 }
 
+// StateEntry describes a beacon state held in the store.
+type StateEntry struct {
+	Slot      phase0.Slot `json:"slot"`
+	StateRoot string      `json:"state_root"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// List returns the states currently held in the store.
+func (c *BeaconState) List() []StateEntry {
+	entries := []StateEntry{}
+
+	for _, key := range c.store.Keys() {
+		_, expiresAt, err := c.store.Get(key)
+		if err != nil {
+			// The state was evicted while we were listing.
+			continue
+		}
+
+		slot, ok := c.stateRootToSlot.Load(key)
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, StateEntry{
+			Slot:      slot.(phase0.Slot),
+			StateRoot: key,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	return entries
+}
+
+// Persist writes all cached states to the given directory so they can be loaded after a restart.
+func (c *BeaconState) Persist(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	items := []persistedItem{}
+
+	for _, key := range c.store.Keys() {
+		data, expiresAt, err := c.store.Get(key)
+		if err != nil {
+			// The state was evicted while we were persisting.
+			continue
+		}
+
+		state, err := c.parseState(data)
+		if err != nil {
+			return err
+		}
+
+		slot, ok := c.stateRootToSlot.Load(key)
+		if !ok {
+			continue
+		}
+
+		if err := writePersistedItem(dir, key, *state); err != nil {
+			return err
+		}
+
+		items = append(items, persistedItem{
+			Key:       key,
+			Slot:      slot.(phase0.Slot),
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	if err := writePersistedIndex(dir, items); err != nil {
+		return err
+	}
+
+	c.log.WithField("count", len(items)).WithField("dir", dir).Info("Persisted states to disk")
+
+	return nil
+}
+
+// Load adds all states previously persisted to the given directory to the store. States that have
+// expired since they were persisted are dropped, as are states that can't be read or that verify rejects, e.g.
+// because they don't hash to the state root they were persisted under.
+func (c *BeaconState) Load(dir string, verify func(stateRoot phase0.Root, state []byte) error) error {
+	items, err := readPersistedIndex(dir)
+	if err != nil {
+		return err
+	}
+
+	loaded := 0
+
+	for _, item := range items {
+		if item.expired(time.Now()) {
+			c.log.WithField("state_root", item.Key).Debug("Skipping loading expired state")
+
+			continue
+		}
+
+		stateRoot, data, err := c.loadState(dir, item, verify)
+		if err != nil {
+			c.log.WithError(err).WithField("state_root", item.Key).Warn("Skipping loading persisted state")
+
+			continue
+		}
+
+		if err := c.Add(stateRoot, &data, item.ExpiresAt, item.Slot); err != nil {
+			return err
+		}
+
+		loaded++
+	}
+
+	c.log.WithField("count", loaded).WithField("dir", dir).Info("Loaded states from disk")
+
+	return nil
+}
+
+// loadState reads a persisted state and verifies it.
+func (c *BeaconState) loadState(dir string, item persistedItem, verify func(stateRoot phase0.Root, state []byte) error) (phase0.Root, []byte, error) {
+	stateRoot, err := parsePersistedRoot(item.Key)
+	if err != nil {
+		return phase0.Root{}, nil, err
+	}
+
+	data, err := readPersistedItem(dir, item.Key)
+	if err != nil {
+		return phase0.Root{}, nil, err
+	}
+
+	if err := verify(stateRoot, data); err != nil {
+		return phase0.Root{}, nil, err
+	}
+
+	return stateRoot, data, nil
+}
+
 func (c *BeaconState) parseState(data interface{}) (*[]byte, error) {
 	state, ok := data.(*[]byte)
 	if !ok {
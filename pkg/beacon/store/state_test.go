@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBeaconStateList(t *testing.T) {
+	states := NewBeaconState(logrus.New(), Config{MaxItems: 10}, testNamespace("test_state_list"))
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	for i := byte(1); i <= 2; i++ {
+		state := []byte{i}
+
+		if err := states.Add(phase0.Root{i}, &state, expiresAt, phase0.Slot(i)*32); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := states.List()
+	if len(entries) != 2 {
+		t.Fatalf("expected %d states to be listed, got %d", 2, len(entries))
+	}
+
+	for _, entry := range entries {
+		stateRoot := eth.RootAsString(phase0.Root{byte(entry.Slot / 32)})
+
+		if entry.StateRoot != stateRoot {
+			t.Errorf("expected the state at slot %d to be listed with state root %s, got %s", entry.Slot, stateRoot, entry.StateRoot)
+		}
+
+		if !entry.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("expected the state at slot %d to expire at %v, got %v", entry.Slot, expiresAt, entry.ExpiresAt)
+		}
+	}
+}
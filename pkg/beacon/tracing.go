@@ -0,0 +1,93 @@
+package beacon
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Tracer starts spans around the stages of bundle downloads and finality checks. It's shaped like an
+// OpenTelemetry tracer so one can be adapted to it and injected with Config.Tracer.
+type Tracer interface {
+	// Start starts a span. The returned context carries the span so spans started from it are nested under it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	SetAttribute(key, value string)
+	RecordError(err error)
+	End()
+}
+
+// startSpan starts a span with the configured tracer. It does nothing when tracing is disabled.
+func (d *Default) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if d.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	return d.tracer.Start(ctx, name)
+}
+
+// endSpan records the error, if any, and ends the span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key, value string) {}
+func (noopSpan) RecordError(err error)          {}
+func (noopSpan) End()                           {}
+
+// logTracer logs how long each span took at debug level, for when an OpenTelemetry tracer isn't available.
+type logTracer struct {
+	log logrus.FieldLogger
+}
+
+func newLogTracer(log logrus.FieldLogger) *logTracer {
+	return &logTracer{log: log.WithField("component", "tracing")}
+}
+
+func (t *logTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, &logSpan{
+		log:     t.log,
+		name:    name,
+		started: time.Now(),
+		fields:  logrus.Fields{},
+	}
+}
+
+type logSpan struct {
+	log     logrus.FieldLogger
+	name    string
+	started time.Time
+	fields  logrus.Fields
+	err     error
+}
+
+func (s *logSpan) SetAttribute(key, value string) {
+	s.fields[key] = value
+}
+
+func (s *logSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *logSpan) End() {
+	entry := s.log.
+		WithFields(s.fields).
+		WithField("span", s.name).
+		WithField("duration", time.Since(s.started).String())
+
+	if s.err != nil {
+		entry = entry.WithError(s.err)
+	}
+
+	entry.Debug("Span finished")
+}
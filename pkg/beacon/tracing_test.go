@@ -0,0 +1,82 @@
+package beacon
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type spanParentKey struct{}
+
+// recordingTracer records each finished span along with the span it was started under.
+type recordingTracer struct {
+	mu    sync.Mutex
+	ended []string
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanParentKey{}).(string)
+
+	if parent != "" {
+		name = parent + "/" + name
+	}
+
+	return context.WithValue(ctx, spanParentKey{}, name), &recordingSpan{tracer: r, name: name}
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	name   string
+}
+
+func (s *recordingSpan) SetAttribute(key, value string) {}
+func (s *recordingSpan) RecordError(err error)          {}
+
+func (s *recordingSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.tracer.ended = append(s.tracer.ended, s.name)
+}
+
+func TestBundleDownloadSpans(t *testing.T) {
+	st, data := newTestPhase0State(t)
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newTestPhase0Block(stateRoot)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := &recordingTracer{}
+
+	d := newTestDownloadProvider("test_tracing")
+	d.tracer = tracer
+
+	upstreams := Nodes{
+		newTestNode("a", &fakeUpstream{block: block, state: data}),
+	}
+
+	if _, err := d.fetchBundleWithFallback(context.Background(), root, upstreams); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"fetch_bundle/download_bundle/fetch_block",
+		"fetch_bundle/download_bundle/fetch_state",
+		"fetch_bundle/download_bundle/store_state",
+		"fetch_bundle/download_bundle",
+		"fetch_bundle",
+	}
+
+	if !reflect.DeepEqual(tracer.ended, expected) {
+		t.Errorf("expected spans %v, got %v", expected, tracer.ended)
+	}
+}
@@ -0,0 +1,70 @@
+package beacon
+
+import (
+	"context"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+)
+
+// upstreams returns the current upstreams. The returned slice is never modified, ReloadUpstreams replaces it.
+func (d *Default) upstreams() Nodes {
+	d.nodesMu.RLock()
+	defer d.nodesMu.RUnlock()
+
+	return d.nodes
+}
+
+// setupUpstream applies the provider's configuration to a newly created upstream.
+func (d *Default) setupUpstream(upstream *Node) {
+	upstream.FinalityLogSampler = node.NewSampler(d.config.FinalityFailureLogInterval)
+	upstream.Metrics = d.metrics
+
+	d.upstreamNames[upstream.Config.Name] = struct{}{}
+}
+
+// ReloadUpstreams replaces the upstreams with the given configs. Upstreams whose config is unchanged keep running,
+// new and changed upstreams are started with ctx and removed upstreams are stopped. Everything cached is kept.
+// An empty list of upstreams is rejected and the current upstreams are kept.
+func (d *Default) ReloadUpstreams(ctx context.Context, configs []node.Config) error {
+	if len(configs) == 0 {
+		return ErrNoUpstreams
+	}
+
+	d.nodesMu.Lock()
+
+	kept, added, removed := DiffUpstreams(d.nodes, configs)
+
+	nodes := make(Nodes, 0, len(configs))
+	nodes = append(nodes, kept...)
+
+	for _, config := range added {
+		// The beacon library's metrics for an upstream can only be registered once per name.
+		_, seen := d.upstreamNames[config.Name]
+
+		upstream := NewNodeFromConfig(d.log, config, d.namespace, !seen)
+		d.setupUpstream(upstream)
+
+		upstream.Beacon.StartAsync(ctx)
+
+		nodes = append(nodes, upstream)
+	}
+
+	d.nodes = nodes
+	d.nodeConfigs = configs
+
+	d.nodesMu.Unlock()
+
+	for _, upstream := range removed {
+		if err := upstream.Beacon.Stop(ctx); err != nil {
+			d.log.WithError(err).WithField("upstream", upstream.Config.Name).Warn("Failed to stop removed upstream")
+		}
+	}
+
+	d.log.
+		WithField("kept", len(kept)).
+		WithField("added", len(added)).
+		WithField("removed", len(removed)).
+		Info("Reloaded upstreams")
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethpandaops/checkpointz/pkg/beacon/node"
+)
+
+func upstreamNames(nodes Nodes) []string {
+	names := []string{}
+	for _, upstream := range nodes {
+		names = append(names, upstream.Config.Name)
+	}
+
+	return names
+}
+
+func configNames(configs []node.Config) []string {
+	names := []string{}
+	for _, config := range configs {
+		names = append(names, config.Name)
+	}
+
+	return names
+}
+
+func TestDiffUpstreams(t *testing.T) {
+	running := Nodes{
+		{Config: node.Config{Name: "a", Address: "http://a"}},
+		{Config: node.Config{Name: "b", Address: "http://b"}},
+		{Config: node.Config{Name: "c", Address: "http://c"}},
+	}
+
+	kept, added, removed := DiffUpstreams(running, []node.Config{
+		{Name: "a", Address: "http://a"},
+		{Name: "b", Address: "http://b2"},
+		{Name: "d", Address: "http://d"},
+	})
+
+	tests := []struct {
+		name     string
+		got      []string
+		expected []string
+	}{
+		{name: "kept", got: upstreamNames(kept), expected: []string{"a"}},
+		{name: "added", got: configNames(added), expected: []string{"b", "d"}},
+		{name: "removed", got: upstreamNames(removed), expected: []string{"b", "c"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if len(test.got) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, test.got)
+			}
+
+			for i := range test.expected {
+				if test.got[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, test.got)
+				}
+			}
+		})
+	}
+
+	// Unchanged upstreams keep running as they are.
+	if kept[0] != running[0] {
+		t.Error("expected the unchanged upstream to be kept as is")
+	}
+}
+
+func TestReloadUpstreamsRejectsEmptyList(t *testing.T) {
+	d := newTestDownloadProvider("test_reload_empty")
+
+	upstreams := Nodes{{Config: node.Config{Name: "a"}}}
+	d.nodes = upstreams
+
+	if err := d.ReloadUpstreams(context.Background(), nil); !errors.Is(err, ErrNoUpstreams) {
+		t.Fatalf("expected %v, got %v", ErrNoUpstreams, err)
+	}
+
+	if current := d.upstreams(); len(current) != 1 || current[0] != upstreams[0] {
+		t.Error("expected the current upstreams to be kept")
+	}
+}
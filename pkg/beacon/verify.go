@@ -0,0 +1,97 @@
+package beacon
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+var (
+	// ErrStateRootMismatch is returned when a beacon state does not hash to the state root of its block.
+	ErrStateRootMismatch = errors.New("state root does not match block")
+	// ErrBlockSlotMismatch is returned when an upstream serves a block for a different slot to the one requested.
+	ErrBlockSlotMismatch = errors.New("block slot does not match requested slot")
+	// ErrBlockRootMismatch is returned when the block at the finalized slot is not the finalized block.
+	ErrBlockRootMismatch = errors.New("block root does not match finalized checkpoint")
+	// ErrCheckpointNotCrossChecked is returned when not enough upstreams agree on the finalized block to serve it.
+	ErrCheckpointNotCrossChecked = errors.New("finalized block could not be cross checked across upstreams")
+	// ErrStateTooLarge is returned when a beacon state is bigger than the configured max_state_bytes.
+	ErrStateTooLarge = errors.New("state is larger than max_state_bytes")
+)
+
+// VerifyBlockAtSlot checks that a block served for a slot is for that slot. If the slot is the finalized
+// checkpoint's slot the block must also hash to the finalized root.
+func VerifyBlockAtSlot(block *spec.VersionedSignedBeaconBlock, slot phase0.Slot, finalized *phase0.Checkpoint, slotsPerEpoch phase0.Slot) error {
+	blockSlot, err := block.Slot()
+	if err != nil {
+		return fmt.Errorf("failed to get slot from block: %w", err)
+	}
+
+	if blockSlot != slot {
+		return fmt.Errorf("%w: requested %d, got %d", ErrBlockSlotMismatch, slot, blockSlot)
+	}
+
+	if finalized == nil || phase0.Slot(finalized.Epoch)*slotsPerEpoch != slot {
+		return nil
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return fmt.Errorf("failed to get root from block: %w", err)
+	}
+
+	if root != finalized.Root {
+		return fmt.Errorf("%w: expected %s, got %s", ErrBlockRootMismatch, eth.RootAsString(finalized.Root), eth.RootAsString(root))
+	}
+
+	return nil
+}
+
+// VerifyStateSize checks that the SSZ encoded beacon state is no bigger than maxBytes. A maxBytes of 0 is unlimited.
+func VerifyStateSize(state []byte, maxBytes int64) error {
+	if maxBytes > 0 && int64(len(state)) > maxBytes {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrStateTooLarge, len(state), maxBytes)
+	}
+
+	return nil
+}
+
+// VerifyStateRoot checks that the SSZ encoded beacon state hashes to the state root committed to by the block.
+// Phase0 states are not checked as the pinned go-eth2-client leaves eth1_deposit_index out of them when hashing.
+func VerifyStateRoot(block *spec.VersionedSignedBeaconBlock, state []byte) error {
+	if block.Version == spec.DataVersionPhase0 {
+		return nil
+	}
+
+	expected, err := block.StateRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get state root from block: %w", err)
+	}
+
+	actual, err := computeStateRoot(block.Version, state)
+	if err != nil {
+		return fmt.Errorf("failed to compute state root: %w", err)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", ErrStateRootMismatch, eth.RootAsString(expected), eth.RootAsString(actual))
+	}
+
+	return nil
+}
+
+func computeStateRoot(version spec.DataVersion, data []byte) (phase0.Root, error) {
+	state, err := decodeState(version, data)
+	if errors.Is(err, errUnknownStateVersion) {
+		return phase0.Root{}, fmt.Errorf("unknown state version: %s", version.String())
+	}
+
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	return state.HashTreeRoot()
+}
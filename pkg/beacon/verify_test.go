@@ -0,0 +1,141 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestVerifyStateRootMatches(t *testing.T) {
+	state, data := newTestAltairStateSSZ(t)
+
+	stateRoot, err := state.HashTreeRoot()
+	if err != nil {
+		t.Fatalf("failed to hash state: %v", err)
+	}
+
+	if err := VerifyStateRoot(newTestAltairBlock(stateRoot), data); err != nil {
+		t.Errorf("expected state root to match, got %v", err)
+	}
+}
+
+func TestVerifyStateRootMismatch(t *testing.T) {
+	_, data := newTestAltairStateSSZ(t)
+
+	mismatched := phase0.Root{0x01, 0x02, 0x03}
+
+	err := VerifyStateRoot(newTestAltairBlock(mismatched), data)
+	if !errors.Is(err, ErrStateRootMismatch) {
+		t.Errorf("expected ErrStateRootMismatch, got %v", err)
+	}
+}
+
+func TestVerifyStateRootSkipsPhase0(t *testing.T) {
+	_, data := newTestPhase0State(t)
+
+	if err := VerifyStateRoot(newTestPhase0Block(phase0.Root{0x01, 0x02, 0x03}), data); err != nil {
+		t.Errorf("expected phase0 states to be served unverified, got %v", err)
+	}
+}
+
+func TestVerifyStateRootUnknownVersion(t *testing.T) {
+	_, data := newTestPhase0State(t)
+
+	block := newTestPhase0Block(phase0.Root{})
+	block.Version = spec.DataVersion(99)
+
+	if err := VerifyStateRoot(block, data); err == nil {
+		t.Error("expected an error for an unknown block version")
+	}
+}
+
+func TestVerifyBlockAtSlot(t *testing.T) {
+	block := newTestPhase0Block(phase0.Root{})
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatalf("failed to hash block: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		slot      phase0.Slot
+		finalized *phase0.Checkpoint
+		expected  error
+	}{
+		{"historical slot", 64, &phase0.Checkpoint{Epoch: 10, Root: phase0.Root{0x01}}, nil},
+		{"no finalized checkpoint", 64, nil, nil},
+		{"finalized block", 64, &phase0.Checkpoint{Epoch: 2, Root: root}, nil},
+		{"tampered slot", 96, &phase0.Checkpoint{Epoch: 10, Root: phase0.Root{0x01}}, ErrBlockSlotMismatch},
+		{"not the finalized block", 64, &phase0.Checkpoint{Epoch: 2, Root: phase0.Root{0x01}}, ErrBlockRootMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyBlockAtSlot(block, test.slot, test.finalized, 32)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestCrossCheckFinalizedBlock(t *testing.T) {
+	block := newTestPhase0Block(phase0.Root{0x01})
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upstream := func(name string) *Node {
+		return newTestNode(name, &fakeUpstream{block: block})
+	}
+
+	tests := []struct {
+		name      string
+		finalized phase0.Root
+		upstreams Nodes
+		ok        bool
+	}{
+		{
+			name:      "two upstreams agree",
+			finalized: root,
+			upstreams: Nodes{upstream("a"), upstream("b")},
+			ok:        true,
+		},
+		{
+			name:      "upstreams serve a different block at the finalized slot",
+			finalized: phase0.Root{0xff},
+			upstreams: Nodes{upstream("a"), upstream("b")},
+			ok:        false,
+		},
+		{
+			name:      "only one upstream",
+			finalized: root,
+			upstreams: Nodes{upstream("a")},
+			ok:        false,
+		},
+	}
+
+	d := newTestDownloadProvider("test_cross_check")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkpoint := finalizedAt(2, 0)
+			checkpoint.Finalized.Root = test.finalized
+
+			err := d.crossCheckFinalizedBlock(context.Background(), checkpoint, 64, test.upstreams)
+			if test.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !test.ok && !errors.Is(err, ErrCheckpointNotCrossChecked) {
+				t.Errorf("expected %v, got %v", ErrCheckpointNotCrossChecked, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package beacon
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+// warmupPollInterval is how often warmup checks if finality, the spec and genesis are known yet.
+const warmupPollInterval = 5 * time.Second
+
+// WarmupSlots returns the epoch boundary slots of the most recent count finalized epochs, newest first.
+// The genesis slot is never included as it is fetched separately.
+func WarmupSlots(finalized phase0.Epoch, slotsPerEpoch phase0.Slot, count int) []phase0.Slot {
+	slots := []phase0.Slot{}
+
+	for i := 0; i < count && uint64(i) < uint64(finalized); i++ {
+		slots = append(slots, phase0.Slot(uint64(finalized)-uint64(i))*slotsPerEpoch)
+	}
+
+	return slots
+}
+
+// startWarmup waits until finality, the spec and genesis are known and then downloads the bundles for the most
+// recent WarmupEpochs finalized epoch boundaries once.
+func (d *Default) startWarmup(ctx context.Context) error {
+	for {
+		select {
+		case <-time.After(warmupPollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		head := d.finalityHead()
+		if head == nil || head.Finalized == nil {
+			continue
+		}
+
+		if _, err := d.Spec(ctx); err != nil {
+			continue
+		}
+
+		if _, err := d.Genesis(ctx); err != nil {
+			continue
+		}
+
+		return d.warmup(ctx, head)
+	}
+}
+
+func (d *Default) warmup(ctx context.Context, checkpoint *v1.Finality) error {
+	upstreams := d.dataProviders(ctx).
+		PastFinalizedCheckpoint(ctx, checkpoint)
+
+	upstream, err := upstreams.RandomNode(ctx)
+	if err != nil {
+		return errors.New("no data provider node available")
+	}
+
+	slots := WarmupSlots(checkpoint.Finalized.Epoch, d.slotsPerEpoch(ctx), d.config.WarmupEpochs)
+
+	d.log.WithField("epochs", len(slots)).Info("Warming up recent finalized checkpoints")
+
+	warmed := 0
+
+	for _, slot := range slots {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, ok := d.blockExpiration(ctx, slot); !ok {
+			continue
+		}
+
+		block, err := d.downloadBlock(ctx, slot, checkpoint.Finalized, upstream)
+		if err != nil {
+			d.log.WithError(err).WithField("slot", eth.SlotAsString(slot)).Warn("Failed to warm up block")
+
+			continue
+		}
+
+		root, err := block.Root()
+		if err != nil {
+			return err
+		}
+
+		if _, err := d.fetchBundleWithFallback(ctx, root, upstreams); err != nil {
+			d.log.WithError(err).WithField("slot", eth.SlotAsString(slot)).Warn("Failed to warm up bundle")
+
+			continue
+		}
+
+		warmed++
+	}
+
+	d.log.WithField("warmed", warmed).WithField("epochs", len(slots)).Info("Finished warming up recent finalized checkpoints")
+
+	return nil
+}
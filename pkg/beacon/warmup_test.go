@@ -0,0 +1,38 @@
+package beacon
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestWarmupSlots(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		finalized phase0.Epoch
+		count     int
+		expect    []phase0.Slot
+	}{
+		{100, 0, []phase0.Slot{}},
+		{100, 1, []phase0.Slot{3200}},
+		{100, 3, []phase0.Slot{3200, 3168, 3136}},
+		{2, 5, []phase0.Slot{64, 32}},
+		{0, 5, []phase0.Slot{}},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(fmt.Sprintf("%d/%d", test.finalized, test.count), func(t *testing.T) {
+			t.Parallel()
+
+			slots := WarmupSlots(test.finalized, 32, test.count)
+			if !reflect.DeepEqual(slots, test.expect) {
+				t.Errorf("expected %v, got %v", test.expect, slots)
+			}
+		})
+	}
+}
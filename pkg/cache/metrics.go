@@ -7,6 +7,7 @@ type Metrics struct {
 	Hits       prometheus.Counter
 	Misses     prometheus.Counter
 	Len        prometheus.Gauge
+	Size       prometheus.Gauge
 }
 
 var (
@@ -46,6 +47,12 @@ func NewMetrics(name, namespace string) Metrics {
 			Name:        "len",
 			Help:        "Count of items in the cache",
 		}),
+		Size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   namespace,
+			ConstLabels: labels,
+			Name:        "size_bytes",
+			Help:        "Total size of the items in the cache, if tracked",
+		}),
 	}
 
 	return m
@@ -56,6 +63,7 @@ func (m Metrics) Register() {
 	prometheus.MustRegister(m.Hits)
 	prometheus.MustRegister(m.Misses)
 	prometheus.MustRegister(m.Len)
+	prometheus.MustRegister(m.Size)
 }
 
 func (m Metrics) ObserveOperations(opType string, n int) {
@@ -73,3 +81,7 @@ func (m Metrics) ObserveMiss() {
 func (m Metrics) ObserveLen(n int) {
 	m.Len.Set(float64(n))
 }
+
+func (m Metrics) ObserveSize(n int64) {
+	m.Size.Set(float64(n))
+}
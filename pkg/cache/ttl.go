@@ -7,10 +7,18 @@ import (
 	"time"
 )
 
+var (
+	// ErrNotFound is returned when an item does not exist in the cache.
+	ErrNotFound = errors.New("not found")
+)
+
 type item struct {
 	value      interface{}
 	expiresAt  time.Time
 	invincible bool
+	// pinned items are never evicted to make room for other items, but still expire.
+	pinned bool
+	size   int64
 }
 
 type sortableItem struct {
@@ -22,11 +30,15 @@ type TTLMap struct {
 	m        map[string]*item
 	l        sync.Mutex
 	maxItems int
+	// maxSize is the total size of the items the map can hold. 0 is unlimited.
+	maxSize int64
+	size    int64
 
 	metrics Metrics
 
 	deletedCallbacks []func(string, interface{}, time.Time)
 	addedCallbacks   []func(string, interface{}, time.Time)
+	evictedCallbacks []func(string, interface{}, time.Time)
 }
 
 // NewTTLMap returns a new TTLMap.
@@ -39,14 +51,8 @@ func NewTTLMap(maxItems int, name, namespace string) (m *TTLMap) {
 
 	go func() {
 		for now := range time.Tick(time.Second * 1) {
-			for k, v := range m.m {
-				if v.invincible {
-					continue
-				}
-
-				if v.expiresAt.Before(now) {
-					m.Delete(k)
-				}
+			for _, k := range m.expiredKeys(now) {
+				m.Delete(k)
 			}
 		}
 	}()
@@ -54,6 +60,22 @@ func NewTTLMap(maxItems int, name, namespace string) (m *TTLMap) {
 	return
 }
 
+// expiredKeys returns the keys of the items that expired before now.
+func (m *TTLMap) expiredKeys(now time.Time) []string {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	keys := []string{}
+
+	for k, v := range m.m {
+		if !v.invincible && v.expiresAt.Before(now) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
 func (m *TTLMap) EnableMetrics(namespace string) {
 	m.metrics.Register()
 
@@ -64,6 +86,39 @@ func (m *TTLMap) EnableMetrics(namespace string) {
 	m.OnItemDeleted(func(k string, v interface{}, e time.Time) {
 		m.metrics.ObserveLen(m.Len())
 	})
+
+	m.OnItemAdded(func(k string, v interface{}, e time.Time) {
+		m.metrics.ObserveSize(m.Size())
+	})
+
+	m.OnItemDeleted(func(k string, v interface{}, e time.Time) {
+		m.metrics.ObserveSize(m.Size())
+	})
+}
+
+// SetMaxSize limits the total size of the items held in the map, evicting the items closest to expiry to make
+// room for new ones. 0 is unlimited.
+func (m *TTLMap) SetMaxSize(maxSize int64) {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.maxSize = maxSize
+}
+
+// Size returns the total size of the items held in the map.
+func (m *TTLMap) Size() int64 {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.size
+}
+
+// overBudget returns true if adding an item of the given size would exceed the map's max size.
+func (m *TTLMap) overBudget(size int64) bool {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.maxSize > 0 && m.size+size > m.maxSize
 }
 
 func (m *TTLMap) OnItemDeleted(f func(string, interface{}, time.Time)) {
@@ -74,6 +129,12 @@ func (m *TTLMap) OnItemAdded(f func(string, interface{}, time.Time)) {
 	m.addedCallbacks = append(m.addedCallbacks, f)
 }
 
+// OnItemEvicted registers a callback for items evicted to make room for new items.
+// Callbacks are called synchronously before the item is deleted so must not block or call back into the map.
+func (m *TTLMap) OnItemEvicted(f func(string, interface{}, time.Time)) {
+	m.evictedCallbacks = append(m.evictedCallbacks, f)
+}
+
 func (m *TTLMap) Delete(k string) {
 	val, expiresAt, err := m.Get(k)
 	if err != nil {
@@ -82,6 +143,10 @@ func (m *TTLMap) Delete(k string) {
 
 	m.l.Lock()
 
+	if it, ok := m.m[k]; ok {
+		m.size -= it.size
+	}
+
 	delete(m.m, k)
 
 	m.metrics.ObserveOperations(OperationDEL, 1)
@@ -93,13 +158,16 @@ func (m *TTLMap) Delete(k string) {
 	m.l.Unlock()
 }
 
-func (m *TTLMap) evictItemToClosestToExpiry() {
+// evictItemToClosestToExpiry evicts the item closest to expiry, returning false if there was nothing to evict.
+func (m *TTLMap) evictItemToClosestToExpiry() bool {
 	// This is a very naive implementation.
 	items := []sortableItem{}
 
-	// Get all non-invincible items.
+	m.l.Lock()
+
+	// Get all non-invincible, unpinned items.
 	for k, v := range m.m {
-		if v.invincible {
+		if v.invincible || v.pinned {
 			continue
 		}
 
@@ -113,21 +181,83 @@ func (m *TTLMap) evictItemToClosestToExpiry() {
 		return items[i].expiresAt.Before(items[j].expiresAt)
 	})
 
+	var evicted *item
+	if len(items) > 0 {
+		evicted = m.m[items[0].key]
+	}
+
+	m.l.Unlock()
+
 	if len(items) > 0 {
+		if evicted != nil {
+			for _, f := range m.evictedCallbacks {
+				f(items[0].key, evicted.value, evicted.expiresAt)
+			}
+		}
+
 		m.Delete(items[0].key)
 		m.metrics.ObserveOperations(OperationEVICT, 1)
+
+		return true
 	}
+
+	return false
 }
 
 func (m *TTLMap) Len() int {
+	m.l.Lock()
+	defer m.l.Unlock()
+
 	return len(m.m)
 }
 
+// Keys returns a snapshot of the keys currently held in the map.
+func (m *TTLMap) Keys() []string {
+	m.l.Lock()
+
+	defer m.l.Unlock()
+
+	keys := make([]string, 0, len(m.m))
+
+	for k := range m.m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+// SetPinned sets if an item is protected from being evicted to make room for other items. Unlike invincible items,
+// pinned items still expire.
+func (m *TTLMap) SetPinned(k string, pinned bool) error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	it, ok := m.m[k]
+	if !ok {
+		return ErrNotFound
+	}
+
+	it.pinned = pinned
+
+	return nil
+}
+
 func (m *TTLMap) Add(k string, v interface{}, expiresAt time.Time, invincible bool) {
+	m.AddWithSize(k, v, expiresAt, invincible, 0)
+}
+
+// AddWithSize adds an item that counts the given size towards the map's max size.
+func (m *TTLMap) AddWithSize(k string, v interface{}, expiresAt time.Time, invincible bool, size int64) {
 	if m.Len() >= m.maxItems {
 		m.evictItemToClosestToExpiry()
 	}
 
+	for m.overBudget(size) {
+		if !m.evictItemToClosestToExpiry() {
+			break
+		}
+	}
+
 	m.l.Lock()
 
 	defer m.l.Unlock()
@@ -138,8 +268,10 @@ func (m *TTLMap) Add(k string, v interface{}, expiresAt time.Time, invincible bo
 			value:      v,
 			expiresAt:  expiresAt,
 			invincible: invincible,
+			size:       size,
 		}
 		m.m[k] = it
+		m.size += size
 	}
 
 	m.metrics.ObserveOperations(OperationADD, 1)
@@ -149,6 +281,16 @@ func (m *TTLMap) Add(k string, v interface{}, expiresAt time.Time, invincible bo
 	}
 }
 
+// Has returns if the key is in the map. Unlike Get it doesn't count as a cache hit or miss.
+func (m *TTLMap) Has(k string) bool {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	_, ok := m.m[k]
+
+	return ok
+}
+
 func (m *TTLMap) Get(k string) (interface{}, time.Time, error) {
 	m.metrics.ObserveOperations(OperationGET, 1)
 
@@ -160,7 +302,7 @@ func (m *TTLMap) Get(k string) (interface{}, time.Time, error) {
 	if !ok {
 		m.metrics.ObserveMiss()
 
-		return nil, time.Now(), errors.New("not found")
+		return nil, time.Now(), ErrNotFound
 	}
 
 	m.metrics.ObserveHit()
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -172,3 +173,108 @@ func TestInvincible(t *testing.T) {
 		t.Error("key2 should not be found")
 	}
 }
+
+func TestItemEvictedCallback(t *testing.T) {
+	instance := NewTTLMap(2, "", "")
+
+	evicted := []string{}
+
+	instance.OnItemEvicted(func(key string, value interface{}, expiresAt time.Time) {
+		evicted = append(evicted, key)
+	})
+
+	instance.Add("soonest", "value", time.Now().Add(time.Hour), false)
+	instance.Add("latest", "value", time.Now().Add(time.Hour*3), false)
+	instance.Add("new", "value", time.Now().Add(time.Hour*2), false)
+
+	if len(evicted) != 1 || evicted[0] != "soonest" {
+		t.Fatalf("Expected only the item closest to expiry to be evicted, got %v", evicted)
+	}
+
+	instance.Delete("latest")
+
+	if len(evicted) != 1 {
+		t.Fatalf("Expected deleting an item to not be reported as an eviction, got %v", evicted)
+	}
+}
+
+func TestMaxSizeEvictsOldest(t *testing.T) {
+	instance := NewTTLMap(10, "", "")
+	instance.SetMaxSize(100)
+
+	for i := 1; i <= 5; i++ {
+		instance.AddWithSize(fmt.Sprintf("key%d", i), "value", time.Now().Add(time.Hour).Add(time.Second*time.Duration(i)), false, 40)
+	}
+
+	if instance.Size() != 80 {
+		t.Fatalf("Expected size 80, got %d", instance.Size())
+	}
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		if _, _, err := instance.Get(key); err == nil {
+			t.Fatalf("Expected %s to have been evicted", key)
+		}
+	}
+
+	for _, key := range []string{"key4", "key5"} {
+		if _, _, err := instance.Get(key); err != nil {
+			t.Fatalf("Expected %s to not have been evicted", key)
+		}
+	}
+}
+
+func TestMaxSizeKeepsInvincible(t *testing.T) {
+	instance := NewTTLMap(10, "", "")
+	instance.SetMaxSize(100)
+
+	instance.AddWithSize("invincible", "value", time.Now().Add(time.Hour), true, 80)
+	instance.AddWithSize("key1", "value", time.Now().Add(time.Hour), false, 80)
+
+	if _, _, err := instance.Get("invincible"); err != nil {
+		t.Fatalf("Expected invincible item to not have been evicted")
+	}
+
+	if _, _, err := instance.Get("key1"); err != nil {
+		t.Fatalf("Expected item to be added even if it exceeds the max size")
+	}
+
+	instance.Delete("key1")
+
+	if instance.Size() != 80 {
+		t.Fatalf("Expected size 80, got %d", instance.Size())
+	}
+}
+
+func TestPinnedIsNotEvicted(t *testing.T) {
+	instance := NewTTLMap(2, "", "")
+
+	now := time.Now()
+
+	instance.Add("pinned", "value", now.Add(time.Hour), false)
+
+	if err := instance.SetPinned("pinned", true); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 5; i++ {
+		instance.Add(fmt.Sprintf("key%d", i), "value", now.Add(time.Hour*time.Duration(i+1)), false)
+	}
+
+	if _, _, err := instance.Get("pinned"); err != nil {
+		t.Fatal("expected the pinned item to not have been evicted")
+	}
+
+	if err := instance.SetPinned("pinned", false); err != nil {
+		t.Fatal(err)
+	}
+
+	instance.Add("key6", "value", now.Add(time.Hour*7), false)
+
+	if _, _, err := instance.Get("pinned"); err == nil {
+		t.Fatal("expected the unpinned item to have been evicted")
+	}
+
+	if err := instance.SetPinned("missing", true); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, got %v", ErrNotFound, err)
+	}
+}
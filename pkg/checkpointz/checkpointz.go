@@ -2,6 +2,8 @@ package checkpointz
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
 	"time"
@@ -25,7 +27,9 @@ type Server struct {
 
 	provider beacon.FinalityProvider
 
-	http *api.Handler
+	http        *api.Handler
+	server      *http.Server
+	adminServer *http.Server
 }
 
 func NewServer(log *logrus.Logger, conf *Config) *Server {
@@ -63,36 +67,87 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	// The admin and debug endpoints aren't authenticated, so they're only served on their own address.
+	if s.Cfg.GlobalConfig.AdminAddr == "" {
+		if s.Cfg.Checkpointz.AdminEndpoints || s.Cfg.Checkpointz.DebugCacheEndpoint {
+			s.log.Warn("Admin and debug endpoints are disabled as no adminAddr is configured")
+		}
+	} else if err := s.ServeAdmin(ctx); err != nil {
+		return err
+	}
+
 	if s.Cfg.Checkpointz.Frontend.Enabled {
 		frontend, err := fs.Sub(static.FS, "build/frontend")
 		if err != nil {
 			return err
 		}
 
-		router.NotFound = http.FileServer(http.FS(frontend))
+		router.NotFound = api.NotFoundHandler(http.FileServer(http.FS(frontend)))
 	}
 
 	if err := s.ServeMetrics(ctx); err != nil {
 		return err
 	}
 
-	server := &http.Server{
+	s.server = &http.Server{
 		Addr:              s.Cfg.GlobalConfig.ListenAddr,
 		ReadHeaderTimeout: 3 * time.Minute,
 		WriteTimeout:      15 * time.Minute,
 	}
 
-	server.Handler = router
+	s.server.Handler = router
 
 	s.log.Infof("Serving http at %s", s.Cfg.GlobalConfig.ListenAddr)
 
-	if err := server.ListenAndServe(); err != nil {
-		s.log.Fatal(err)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	return s.Stop()
+}
+
+// ReloadUpstreams replaces the upstreams with those in conf, keeping everything cached. The rest of conf is ignored.
+// If conf is invalid the current upstreams are kept.
+func (s *Server) ReloadUpstreams(ctx context.Context, conf *Config) error {
+	if err := conf.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if err := s.provider.ReloadUpstreams(ctx, conf.BeaconConfig.BeaconUpstreams); err != nil {
+		return err
 	}
 
+	s.Cfg.BeaconConfig = conf.BeaconConfig
+
 	return nil
 }
 
+// Stop gracefully shuts down the http server and the finality provider.
+func (s *Server) Stop() error {
+	s.log.Info("Stopping Checkpointz server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if s.server != nil {
+		if err := s.server.Shutdown(ctx); err != nil {
+			s.log.WithError(err).Error("Failed to shutdown http server")
+		}
+	}
+
+	if s.adminServer != nil {
+		if err := s.adminServer.Shutdown(ctx); err != nil {
+			s.log.WithError(err).Error("Failed to shutdown admin http server")
+		}
+	}
+
+	return s.provider.Stop(ctx)
+}
+
 func (s *Server) ServeMetrics(ctx context.Context) error {
 	go func() {
 		server := &http.Server{
@@ -111,3 +166,29 @@ func (s *Server) ServeMetrics(ctx context.Context) error {
 
 	return nil
 }
+
+// ServeAdmin serves the admin and debug endpoints on their own listener, so they can be firewalled separately to
+// the public endpoints.
+func (s *Server) ServeAdmin(ctx context.Context) error {
+	router := httprouter.New()
+
+	if err := s.http.RegisterAdmin(ctx, router); err != nil {
+		return err
+	}
+
+	s.adminServer = &http.Server{
+		Addr:              s.Cfg.GlobalConfig.AdminAddr,
+		ReadHeaderTimeout: 15 * time.Second,
+		Handler:           router,
+	}
+
+	s.log.Infof("Serving admin http at %s", s.Cfg.GlobalConfig.AdminAddr)
+
+	go func() {
+		if err := s.adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Fatal(err)
+		}
+	}()
+
+	return nil
+}
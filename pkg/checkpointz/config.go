@@ -1,6 +1,7 @@
 package checkpointz
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
@@ -17,6 +18,8 @@ type GlobalConfig struct {
 	ListenAddr   string `yaml:"listenAddr" default:":5555"`
 	LoggingLevel string `yaml:"logging" default:"warn"`
 	MetricsAddr  string `yaml:"metricsAddr" default:":9090"`
+	// AdminAddr is the address the admin and debug endpoints are served on. If empty they are not served.
+	AdminAddr string `yaml:"adminAddr" default:""`
 }
 
 type BeaconConfig struct {
@@ -33,13 +36,94 @@ func (c *Config) Validate() error {
 		}
 
 		if _, ok := duplicates[u.Address]; ok {
-			return fmt.Errorf("there's a duplicate upstream with the same address: %s", u.Address)
+			return fmt.Errorf("there's a duplicate upstream with the same address: %s", u.RedactedAddress())
+		}
+
+		for _, address := range u.FallbackAddresses {
+			if _, ok := duplicates[address]; ok || address == u.Address {
+				return fmt.Errorf("upstream %s has a fallback address that is already used", u.Name)
+			}
+
+			duplicates[address] = struct{}{}
+		}
+
+		if u.PrimaryRetryInterval < 0 {
+			return fmt.Errorf("upstream %s has a negative primary retry interval: %v", u.Name, u.PrimaryRetryInterval)
+		}
+
+		if u.Weight < 0 {
+			return fmt.Errorf("upstream %s has a negative weight: %d", u.Name, u.Weight)
+		}
+
+		switch u.Role {
+		case "", node.RoleFinalityOnly, node.RoleDataProvider:
+		default:
+			return fmt.Errorf("upstream %s has an invalid role: %s", u.Name, u.Role)
+		}
+
+		if client := u.ConfiguredClientType(); client != node.ClientAuto && node.DetectClientType(string(client)) != client {
+			return fmt.Errorf("upstream %s has an invalid client type: %s", u.Name, u.ClientType)
+		}
+
+		if u.RateLimit < 0 {
+			return fmt.Errorf("upstream %s has a negative rate limit: %v", u.Name, u.RateLimit)
+		}
+
+		if err := u.Validate(); err != nil {
+			return fmt.Errorf("upstream %s has invalid authentication: %w", u.Name, err)
+		}
+
+		// Fail fast if the bearer token file can't be read.
+		if _, err := u.RequestHeaders(); err != nil {
+			return fmt.Errorf("upstream %s: %w", u.Name, err)
 		}
 
 		duplicates[u.Name] = struct{}{}
 		duplicates[u.Address] = struct{}{}
 	}
 
+	if c.Checkpointz.FinalityMode == beacon.FinalityModeSingleTrusted {
+		found := false
+
+		for _, u := range c.BeaconConfig.BeaconUpstreams {
+			if u.Name == c.Checkpointz.TrustedNode {
+				found = true
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("trusted_node %s is not a configured upstream", c.Checkpointz.TrustedNode)
+		}
+	}
+
+	if c.Checkpointz.MinReadyNodes > len(c.BeaconConfig.BeaconUpstreams) {
+		return fmt.Errorf("min_ready_nodes (%d) cannot be higher than the number of upstreams (%d)", c.Checkpointz.MinReadyNodes, len(c.BeaconConfig.BeaconUpstreams))
+	}
+
+	if c.Checkpointz.VerifyAcrossUpstreams {
+		providers := 0
+
+		for _, u := range c.BeaconConfig.BeaconUpstreams {
+			if u.Role != node.RoleFinalityOnly {
+				providers++
+			}
+		}
+
+		if providers < 2 {
+			return errors.New("verify_across_upstreams requires at least 2 data provider upstreams")
+		}
+	}
+
+	if c.GlobalConfig.AdminAddr != "" {
+		if c.GlobalConfig.AdminAddr == c.GlobalConfig.ListenAddr {
+			return errors.New("adminAddr must be different to listenAddr")
+		}
+
+		if c.GlobalConfig.AdminAddr == c.GlobalConfig.MetricsAddr {
+			return errors.New("adminAddr must be different to metricsAddr")
+		}
+	}
+
 	if err := c.Checkpointz.Validate(); err != nil {
 		return fmt.Errorf("invalid checkpointz config: %s", err)
 	}
@@ -0,0 +1,287 @@
+package eth
+
+import (
+	"errors"
+
+	"github.com/attestantio/go-eth2-client/api"
+	apiv1bellatrix "github.com/attestantio/go-eth2-client/api/v1/bellatrix"
+	apiv1capella "github.com/attestantio/go-eth2-client/api/v1/capella"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/capella"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+const (
+	maxTransactionsPerPayload = 1048576
+	maxBytesPerTransaction    = 1073741824
+	maxWithdrawalsPerPayload  = 16
+	bytesPerMerkleChunk       = 32
+	transactionChunkLimit     = (maxBytesPerTransaction + bytesPerMerkleChunk - 1) / bytesPerMerkleChunk
+
+	// signedBlockFixedSize is the SSZ offset of the message plus the signature.
+	signedBlockFixedSize = 4 + 96
+)
+
+// ErrBlockNotBlindable is returned when blinding a block from a fork before execution payloads existed.
+var ErrBlockNotBlindable = errors.New("block predates execution payloads and can't be blinded")
+
+// BlindBeaconBlock derives the blinded version of the given block by replacing its execution payload with the
+// payload's header. The blinded block has the same root as the full block.
+func BlindBeaconBlock(block *spec.VersionedSignedBeaconBlock) (*api.VersionedSignedBlindedBeaconBlock, error) {
+	if block == nil {
+		return nil, errors.New("block is nil")
+	}
+
+	switch block.Version {
+	case spec.DataVersionPhase0, spec.DataVersionAltair:
+		return nil, ErrBlockNotBlindable
+	case spec.DataVersionBellatrix:
+		blinded, err := blindBellatrixBlock(block.Bellatrix)
+		if err != nil {
+			return nil, err
+		}
+
+		return &api.VersionedSignedBlindedBeaconBlock{
+			Version:   block.Version,
+			Bellatrix: blinded,
+		}, nil
+	case spec.DataVersionCapella:
+		blinded, err := blindCapellaBlock(block.Capella)
+		if err != nil {
+			return nil, err
+		}
+
+		return &api.VersionedSignedBlindedBeaconBlock{
+			Version: block.Version,
+			Capella: blinded,
+		}, nil
+	default:
+		return nil, errors.New("unknown version")
+	}
+}
+
+// MarshalBlindedBeaconBlockSSZ SSZ encodes the signed blinded block. The client library can't encode signed blinded
+// capella blocks itself.
+func MarshalBlindedBeaconBlockSSZ(block *api.VersionedSignedBlindedBeaconBlock) ([]byte, error) {
+	if block == nil {
+		return nil, errors.New("block is nil")
+	}
+
+	switch block.Version {
+	case spec.DataVersionBellatrix:
+		if block.Bellatrix == nil {
+			return nil, errors.New("no bellatrix block")
+		}
+
+		return block.Bellatrix.MarshalSSZ()
+	case spec.DataVersionCapella:
+		if block.Capella == nil || block.Capella.Message == nil {
+			return nil, errors.New("no capella block")
+		}
+
+		message, err := block.Capella.Message.MarshalSSZ()
+		if err != nil {
+			return nil, err
+		}
+
+		return marshalSignedSSZ(message, block.Capella.Signature), nil
+	default:
+		return nil, errors.New("unknown version")
+	}
+}
+
+// marshalSignedSSZ SSZ encodes a signed container from its encoded message and signature.
+func marshalSignedSSZ(message []byte, signature phase0.BLSSignature) []byte {
+	dst := make([]byte, 0, signedBlockFixedSize+len(message))
+	dst = ssz.WriteOffset(dst, signedBlockFixedSize)
+	dst = append(dst, signature[:]...)
+
+	return append(dst, message...)
+}
+
+func blindBellatrixBlock(block *bellatrix.SignedBeaconBlock) (*apiv1bellatrix.SignedBlindedBeaconBlock, error) {
+	if block == nil || block.Message == nil || block.Message.Body == nil {
+		return nil, errors.New("no bellatrix block")
+	}
+
+	body := block.Message.Body
+
+	payload := body.ExecutionPayload
+	if payload == nil {
+		return nil, errors.New("no execution payload")
+	}
+
+	transactionsRoot, err := hashList(transactions(payload.Transactions))
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiv1bellatrix.SignedBlindedBeaconBlock{
+		Message: &apiv1bellatrix.BlindedBeaconBlock{
+			Slot:          block.Message.Slot,
+			ProposerIndex: block.Message.ProposerIndex,
+			ParentRoot:    block.Message.ParentRoot,
+			StateRoot:     block.Message.StateRoot,
+			Body: &apiv1bellatrix.BlindedBeaconBlockBody{
+				RANDAOReveal:      body.RANDAOReveal,
+				ETH1Data:          body.ETH1Data,
+				Graffiti:          body.Graffiti,
+				ProposerSlashings: body.ProposerSlashings,
+				AttesterSlashings: body.AttesterSlashings,
+				Attestations:      body.Attestations,
+				Deposits:          body.Deposits,
+				VoluntaryExits:    body.VoluntaryExits,
+				SyncAggregate:     body.SyncAggregate,
+				ExecutionPayloadHeader: &bellatrix.ExecutionPayloadHeader{
+					ParentHash:       payload.ParentHash,
+					FeeRecipient:     payload.FeeRecipient,
+					StateRoot:        payload.StateRoot,
+					ReceiptsRoot:     payload.ReceiptsRoot,
+					LogsBloom:        payload.LogsBloom,
+					PrevRandao:       payload.PrevRandao,
+					BlockNumber:      payload.BlockNumber,
+					GasLimit:         payload.GasLimit,
+					GasUsed:          payload.GasUsed,
+					Timestamp:        payload.Timestamp,
+					ExtraData:        payload.ExtraData,
+					BaseFeePerGas:    payload.BaseFeePerGas,
+					BlockHash:        payload.BlockHash,
+					TransactionsRoot: transactionsRoot,
+				},
+			},
+		},
+		Signature: block.Signature,
+	}, nil
+}
+
+func blindCapellaBlock(block *capella.SignedBeaconBlock) (*apiv1capella.SignedBlindedBeaconBlock, error) {
+	if block == nil || block.Message == nil || block.Message.Body == nil {
+		return nil, errors.New("no capella block")
+	}
+
+	body := block.Message.Body
+
+	payload := body.ExecutionPayload
+	if payload == nil {
+		return nil, errors.New("no execution payload")
+	}
+
+	transactionsRoot, err := hashList(transactions(payload.Transactions))
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawalsRoot, err := hashList(withdrawals(payload.Withdrawals))
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiv1capella.SignedBlindedBeaconBlock{
+		Message: &apiv1capella.BlindedBeaconBlock{
+			Slot:          block.Message.Slot,
+			ProposerIndex: block.Message.ProposerIndex,
+			ParentRoot:    block.Message.ParentRoot,
+			StateRoot:     block.Message.StateRoot,
+			Body: &apiv1capella.BlindedBeaconBlockBody{
+				RANDAOReveal:          body.RANDAOReveal,
+				ETH1Data:              body.ETH1Data,
+				Graffiti:              body.Graffiti,
+				ProposerSlashings:     body.ProposerSlashings,
+				AttesterSlashings:     body.AttesterSlashings,
+				Attestations:          body.Attestations,
+				Deposits:              body.Deposits,
+				VoluntaryExits:        body.VoluntaryExits,
+				SyncAggregate:         body.SyncAggregate,
+				BLSToExecutionChanges: body.BLSToExecutionChanges,
+				ExecutionPayloadHeader: &capella.ExecutionPayloadHeader{
+					ParentHash:       payload.ParentHash,
+					FeeRecipient:     payload.FeeRecipient,
+					StateRoot:        payload.StateRoot,
+					ReceiptsRoot:     payload.ReceiptsRoot,
+					LogsBloom:        payload.LogsBloom,
+					PrevRandao:       payload.PrevRandao,
+					BlockNumber:      payload.BlockNumber,
+					GasLimit:         payload.GasLimit,
+					GasUsed:          payload.GasUsed,
+					Timestamp:        payload.Timestamp,
+					ExtraData:        payload.ExtraData,
+					BaseFeePerGas:    payload.BaseFeePerGas,
+					BlockHash:        payload.BlockHash,
+					TransactionsRoot: transactionsRoot,
+					WithdrawalsRoot:  withdrawalsRoot,
+				},
+			},
+		},
+		Signature: block.Signature,
+	}, nil
+}
+
+// hashableList is an SSZ list that can be hashed into a hasher. It isn't a full ssz.HashRoot as the list types below
+// only exist to be hashed.
+type hashableList interface {
+	HashTreeRootWith(hh ssz.HashWalker) error
+}
+
+// hashList returns the hash tree root of the list using a hasher from the default pool.
+func hashList(list hashableList) (phase0.Root, error) {
+	hh := ssz.DefaultHasherPool.Get()
+	defer ssz.DefaultHasherPool.Put(hh)
+
+	if err := list.HashTreeRootWith(hh); err != nil {
+		return phase0.Root{}, err
+	}
+
+	return hh.HashRoot()
+}
+
+// transactions hashes the transactions of an execution payload the same way as the payload's own hash tree root.
+type transactions []bellatrix.Transaction
+
+func (t transactions) HashTreeRootWith(hh ssz.HashWalker) error {
+	indx := hh.Index()
+
+	num := uint64(len(t))
+	if num > maxTransactionsPerPayload {
+		return ssz.ErrIncorrectListSize
+	}
+
+	for _, tx := range t {
+		elemIndx := hh.Index()
+
+		byteLen := uint64(len(tx))
+		if byteLen > maxBytesPerTransaction {
+			return ssz.ErrIncorrectListSize
+		}
+
+		hh.AppendBytes32(tx)
+		hh.MerkleizeWithMixin(elemIndx, byteLen, transactionChunkLimit)
+	}
+
+	hh.MerkleizeWithMixin(indx, num, maxTransactionsPerPayload)
+
+	return nil
+}
+
+// withdrawals hashes the withdrawals of an execution payload the same way as the payload's own hash tree root.
+type withdrawals []*capella.Withdrawal
+
+func (w withdrawals) HashTreeRootWith(hh ssz.HashWalker) error {
+	indx := hh.Index()
+
+	num := uint64(len(w))
+	if num > maxWithdrawalsPerPayload {
+		return ssz.ErrIncorrectListSize
+	}
+
+	for _, withdrawal := range w {
+		if err := withdrawal.HashTreeRootWith(hh); err != nil {
+			return err
+		}
+	}
+
+	hh.MerkleizeWithMixin(indx, num, maxWithdrawalsPerPayload)
+
+	return nil
+}
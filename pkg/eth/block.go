@@ -0,0 +1,130 @@
+package eth
+
+import (
+	"errors"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+// NewBeaconBlockHeader derives the signed header of the given block.
+func NewBeaconBlockHeader(block *spec.VersionedSignedBeaconBlock) (*v1.BeaconBlockHeader, error) {
+	if block == nil {
+		return nil, errors.New("block is nil")
+	}
+
+	var (
+		proposerIndex phase0.ValidatorIndex
+		signature     phase0.BLSSignature
+	)
+
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		if block.Phase0 == nil || block.Phase0.Message == nil {
+			return nil, errors.New("no phase0 block")
+		}
+
+		proposerIndex = block.Phase0.Message.ProposerIndex
+		signature = block.Phase0.Signature
+	case spec.DataVersionAltair:
+		if block.Altair == nil || block.Altair.Message == nil {
+			return nil, errors.New("no altair block")
+		}
+
+		proposerIndex = block.Altair.Message.ProposerIndex
+		signature = block.Altair.Signature
+	case spec.DataVersionBellatrix:
+		if block.Bellatrix == nil || block.Bellatrix.Message == nil {
+			return nil, errors.New("no bellatrix block")
+		}
+
+		proposerIndex = block.Bellatrix.Message.ProposerIndex
+		signature = block.Bellatrix.Signature
+	case spec.DataVersionCapella:
+		if block.Capella == nil || block.Capella.Message == nil {
+			return nil, errors.New("no capella block")
+		}
+
+		proposerIndex = block.Capella.Message.ProposerIndex
+		signature = block.Capella.Signature
+	default:
+		return nil, errors.New("unknown version")
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	parentRoot, err := block.ParentRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	bodyRoot, err := block.BodyRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.BeaconBlockHeader{
+		Root:      root,
+		Canonical: true,
+		Header: &phase0.SignedBeaconBlockHeader{
+			Message: &phase0.BeaconBlockHeader{
+				Slot:          slot,
+				ProposerIndex: proposerIndex,
+				ParentRoot:    parentRoot,
+				StateRoot:     stateRoot,
+				BodyRoot:      bodyRoot,
+			},
+			Signature: signature,
+		},
+	}, nil
+}
+
+// MarshalBlockSSZ returns the SSZ encoding of the given signed block.
+func MarshalBlockSSZ(block *spec.VersionedSignedBeaconBlock) ([]byte, error) {
+	if block == nil {
+		return nil, errors.New("block is nil")
+	}
+
+	switch block.Version {
+	case spec.DataVersionPhase0:
+		if block.Phase0 == nil {
+			return nil, errors.New("no phase0 block")
+		}
+
+		return block.Phase0.MarshalSSZ()
+	case spec.DataVersionAltair:
+		if block.Altair == nil {
+			return nil, errors.New("no altair block")
+		}
+
+		return block.Altair.MarshalSSZ()
+	case spec.DataVersionBellatrix:
+		if block.Bellatrix == nil {
+			return nil, errors.New("no bellatrix block")
+		}
+
+		return block.Bellatrix.MarshalSSZ()
+	case spec.DataVersionCapella:
+		if block.Capella == nil {
+			return nil, errors.New("no capella block")
+		}
+
+		return block.Capella.MarshalSSZ()
+	default:
+		return nil, errors.New("unknown version")
+	}
+}
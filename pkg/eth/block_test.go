@@ -0,0 +1,75 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestNewBeaconBlockHeader(t *testing.T) {
+	block := &spec.VersionedSignedBeaconBlock{
+		Version: spec.DataVersionAltair,
+		Altair: &altair.SignedBeaconBlock{
+			Message: &altair.BeaconBlock{
+				Slot:          phase0.Slot(100),
+				ProposerIndex: phase0.ValidatorIndex(7),
+				ParentRoot:    phase0.Root{0x01},
+				StateRoot:     phase0.Root{0x02},
+				Body: &altair.BeaconBlockBody{
+					ETH1Data:          &phase0.ETH1Data{BlockHash: make([]byte, 32)},
+					ProposerSlashings: []*phase0.ProposerSlashing{},
+					AttesterSlashings: []*phase0.AttesterSlashing{},
+					Attestations:      []*phase0.Attestation{},
+					Deposits:          []*phase0.Deposit{},
+					VoluntaryExits:    []*phase0.SignedVoluntaryExit{},
+					SyncAggregate: &altair.SyncAggregate{
+						SyncCommitteeBits: make([]byte, 64),
+					},
+				},
+			},
+			Signature: phase0.BLSSignature{0x03},
+		},
+	}
+
+	header, err := NewBeaconBlockHeader(block)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !header.Canonical {
+		t.Error("expected header to be canonical")
+	}
+
+	msg := header.Header.Message
+
+	if msg.Slot != 100 || msg.ProposerIndex != 7 || msg.ParentRoot != (phase0.Root{0x01}) || msg.StateRoot != (phase0.Root{0x02}) {
+		t.Errorf("unexpected header message: %+v", msg)
+	}
+
+	if header.Header.Signature != (phase0.BLSSignature{0x03}) {
+		t.Errorf("unexpected signature: %#x", header.Header.Signature)
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The header commits to the block through its body root, so it hashes to the block root.
+	headerRoot, err := msg.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Root != root || headerRoot != root {
+		t.Errorf("expected the header root and its hash to be the block root %#x, got %#x and %#x", root, header.Root, headerRoot)
+	}
+}
+
+func TestNewBeaconBlockHeaderMissingBlock(t *testing.T) {
+	if _, err := NewBeaconBlockHeader(&spec.VersionedSignedBeaconBlock{Version: spec.DataVersionPhase0}); err == nil {
+		t.Error("expected an error for a missing block")
+	}
+}
@@ -0,0 +1,69 @@
+package eth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+)
+
+// forkOrder is the order forks activate in. Used to break ties when several forks are scheduled for the same epoch.
+var forkOrder = []string{"phase0", "altair", "bellatrix", "capella", "deneb"}
+
+// ForkName converts a spec fork name (e.g. "ALTAIR") in to the name used by the Eth-Consensus-Version header (e.g. "altair").
+func ForkName(name string) string {
+	name = strings.ToLower(name)
+	if name == "genesis" {
+		return "phase0"
+	}
+
+	return name
+}
+
+// ForkAtSlot returns the name of the fork that is active at the given slot according to the fork schedule in the spec.
+// Forks unknown to checkpointz are ignored.
+func ForkAtSlot(slot phase0.Slot, sp *state.Spec) (string, error) {
+	if sp == nil {
+		return "", errors.New("spec is nil")
+	}
+
+	if sp.SlotsPerEpoch == 0 {
+		return "", errors.New("spec has no slots per epoch")
+	}
+
+	epoch := phase0.Epoch(slot / sp.SlotsPerEpoch)
+
+	active := ""
+	activeIndex := -1
+
+	for _, fork := range sp.ForkEpochs {
+		if fork == nil || fork.Epoch > epoch {
+			continue
+		}
+
+		name := ForkName(fork.Name)
+
+		index := forkIndex(name)
+		if index > activeIndex {
+			active = name
+			activeIndex = index
+		}
+	}
+
+	if active == "" {
+		return "", errors.New("no fork is active at slot")
+	}
+
+	return active, nil
+}
+
+func forkIndex(name string) int {
+	for i, fork := range forkOrder {
+		if fork == name {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,91 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+)
+
+func TestForkAtSlot(t *testing.T) {
+	t.Parallel()
+
+	// Mainnet's fork schedule.
+	sp := &state.Spec{
+		SlotsPerEpoch: 32,
+		ForkEpochs: state.ForkEpochs{
+			{Name: "GENESIS", Epoch: 0},
+			{Name: "ALTAIR", Epoch: 74240},
+			{Name: "BELLATRIX", Epoch: 144896},
+			{Name: "CAPELLA", Epoch: 194048},
+			{Name: "SHARDING", Epoch: 18446744073709551615},
+		},
+	}
+
+	tests := []struct {
+		name string
+		slot phase0.Slot
+		fork string
+	}{
+		{"genesis", 0, "phase0"},
+		{"last phase0 slot", 74240*32 - 1, "phase0"},
+		{"first altair slot", 74240 * 32, "altair"},
+		{"last altair slot", 144896*32 - 1, "altair"},
+		{"first bellatrix slot", 144896 * 32, "bellatrix"},
+		{"first capella slot", 194048 * 32, "capella"},
+		{"far future", 1 << 40, "capella"},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			fork, err := ForkAtSlot(test.slot, sp)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if fork != test.fork {
+				t.Errorf("expected %v, got %v", test.fork, fork)
+			}
+		})
+	}
+}
+
+func TestForkAtSlotSameEpoch(t *testing.T) {
+	t.Parallel()
+
+	// Devnets commonly start with several forks at genesis.
+	sp := &state.Spec{
+		SlotsPerEpoch: 32,
+		ForkEpochs: state.ForkEpochs{
+			{Name: "BELLATRIX", Epoch: 0},
+			{Name: "GENESIS", Epoch: 0},
+			{Name: "ALTAIR", Epoch: 0},
+			{Name: "CAPELLA", Epoch: 10},
+		},
+	}
+
+	fork, err := ForkAtSlot(0, sp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fork != "bellatrix" {
+		t.Errorf("expected bellatrix, got %v", fork)
+	}
+}
+
+func TestForkAtSlotInvalidSpec(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ForkAtSlot(0, nil); err == nil {
+		t.Error("expected error for nil spec")
+	}
+
+	if _, err := ForkAtSlot(0, &state.Spec{}); err == nil {
+		t.Error("expected error for spec without slots per epoch")
+	}
+}
@@ -21,3 +21,23 @@ func CalculateSlotTime(slot phase0.Slot, genesisTime time.Time, durationPerSlot
 		EndTime:   slotStartTime.Add(durationPerSlot),
 	}
 }
+
+// CalculateWallClockSlot returns the slot the chain is in at the given time.
+// Times before genesis are considered to be in slot 0.
+func CalculateWallClockSlot(now, genesisTime time.Time, durationPerSlot time.Duration) phase0.Slot {
+	if !now.After(genesisTime) || durationPerSlot == 0 {
+		return 0
+	}
+
+	return phase0.Slot(now.Sub(genesisTime) / durationPerSlot)
+}
+
+// CalculateWallClockEpoch returns the epoch the chain is in at the given time.
+// Times before genesis are considered to be in epoch 0.
+func CalculateWallClockEpoch(now, genesisTime time.Time, durationPerSlot time.Duration, slotsPerEpoch phase0.Slot) phase0.Epoch {
+	if slotsPerEpoch == 0 {
+		return 0
+	}
+
+	return phase0.Epoch(CalculateWallClockSlot(now, genesisTime, durationPerSlot) / slotsPerEpoch)
+}
@@ -52,3 +52,73 @@ func TestCalculateSlotTime(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateWallClockEpoch(t *testing.T) {
+	genesisTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	durationPerSlot := time.Second * 12
+	slotsPerEpoch := phase0.Slot(32)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want phase0.Epoch
+	}{
+		{
+			name: "Before genesis",
+			now:  genesisTime.Add(-time.Hour),
+			want: phase0.Epoch(0),
+		},
+		{
+			name: "At genesis",
+			now:  genesisTime,
+			want: phase0.Epoch(0),
+		},
+		{
+			name: "Last slot of epoch 0",
+			now:  genesisTime.Add(31 * durationPerSlot),
+			want: phase0.Epoch(0),
+		},
+		{
+			name: "First slot of epoch 1",
+			now:  genesisTime.Add(32 * durationPerSlot),
+			want: phase0.Epoch(1),
+		},
+		{
+			name: "Mid epoch 10",
+			now:  genesisTime.Add(335 * durationPerSlot),
+			want: phase0.Epoch(10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateWallClockEpoch(tt.now, genesisTime, durationPerSlot, slotsPerEpoch); got != tt.want {
+				t.Errorf("CalculateWallClockEpoch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateWallClockSlot(t *testing.T) {
+	genesis := time.Unix(1606824023, 0)
+	slotDuration := 12 * time.Second
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want phase0.Slot
+	}{
+		{name: "before genesis", now: genesis.Add(-time.Hour), want: 0},
+		{name: "at genesis", now: genesis, want: 0},
+		{name: "mid slot", now: genesis.Add(18 * time.Second), want: 1},
+		{name: "slot boundary", now: genesis.Add(24 * time.Second), want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateWallClockSlot(tt.now, genesis, slotDuration); got != tt.want {
+				t.Errorf("CalculateWallClockSlot() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,10 @@ package checkpointz
 import (
 	"context"
 
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
 	"github.com/ethpandaops/checkpointz/pkg/eth"
 	"github.com/ethpandaops/checkpointz/pkg/version"
 	"github.com/sirupsen/logrus"
@@ -43,6 +46,46 @@ func (h *Handler) V1Status(ctx context.Context, req *StatusRequest) (*StatusResp
 
 	response.Upstreams = upstreams
 
+	healthy, err := h.provider.Healthy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Healthy = healthy
+	response.Pinned = h.provider.Pinned(ctx) != nil
+
+	// The sync state is best effort as it relies on the chain spec being known.
+	if syncing, err := h.provider.Syncing(ctx); err == nil {
+		response.Syncing = syncing
+	}
+
+	if lastQuorumAt := h.provider.LastFinalityQuorum(ctx); !lastQuorumAt.IsZero() {
+		response.LastQuorumAt = &lastQuorumAt
+	}
+
+	if servingSince := h.provider.ServingSince(ctx); !servingSince.IsZero() {
+		response.ServingSince = &servingSince
+	}
+
+	head, err := h.provider.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if head != nil && head.Finalized != nil {
+		response.Head = head
+	}
+
+	if justified, err := h.provider.Justified(ctx); err == nil {
+		response.Justified = justified
+	}
+
+	if err := h.provider.FinalityStalled(ctx); err != nil {
+		response.Stalled = err.Error()
+
+		return response, nil
+	}
+
 	finality, err := h.provider.Finalized(ctx)
 	if err != nil {
 		return nil, err
@@ -55,6 +98,84 @@ func (h *Handler) V1Status(ctx context.Context, req *StatusRequest) (*StatusResp
 	return response, nil
 }
 
+// V1Ready returns true once checkpointz has its serving checkpoint bundle cached and is ready to serve traffic.
+func (h *Handler) V1Ready(ctx context.Context, req *ReadyRequest) (bool, error) {
+	return h.provider.Ready(ctx)
+}
+
+// V1DebugCache lists the blocks and states checkpointz currently has cached.
+func (h *Handler) V1DebugCache(ctx context.Context, req *DebugCacheRequest) (*beacon.CacheContents, error) {
+	return h.provider.CacheContents(ctx), nil
+}
+
+// V1AdminRecheck checks the upstreams for finality immediately, returning the resulting head.
+func (h *Handler) V1AdminRecheck(ctx context.Context, req *AdminRecheckRequest) (*v1.Finality, error) {
+	return h.provider.RecheckFinality(ctx)
+}
+
+// V1AdminFetch queues a download of the bundle for the given finalized block root, returning the queued fetches.
+func (h *Handler) V1AdminFetch(ctx context.Context, req *AdminFetchRequest) (*beacon.BundleFetchQueue, error) {
+	return h.provider.QueueBundleFetch(ctx, req.root)
+}
+
+// V1AdminPin serves the bundle for the given finalized block root until it is unpinned, returning the pinned
+// checkpoint.
+func (h *Handler) V1AdminPin(ctx context.Context, req *AdminPinRequest) (*v1.Finality, error) {
+	if err := h.provider.Pin(ctx, req.root); err != nil {
+		return nil, err
+	}
+
+	return h.provider.Pinned(ctx), nil
+}
+
+// V1AdminUnpin goes back to serving the finalized head, returning the checkpoint that was pinned, if any.
+func (h *Handler) V1AdminUnpin(ctx context.Context, req *AdminUnpinRequest) (*v1.Finality, error) {
+	pinned := h.provider.Pinned(ctx)
+
+	h.provider.Unpin(ctx)
+
+	return pinned, nil
+}
+
+// V1Checkpoints returns the finalized checkpoints that can currently be downloaded, newest first.
+func (h *Handler) V1Checkpoints(ctx context.Context, req *CheckpointsRequest) ([]beacon.Checkpoint, error) {
+	return h.provider.Checkpoints(ctx), nil
+}
+
+// V1Bundle returns the SSZ encoded block and state for the given block root.
+func (h *Handler) V1Bundle(ctx context.Context, req *BundleRequest) (*BundleResponse, error) {
+	block, err := h.provider.GetBlockByRoot(ctx, req.root)
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return nil, err
+	}
+
+	blockSSZ, err := eth.MarshalBlockSSZ(block)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := h.provider.GetBeaconStateByRoot(ctx, req.root)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		return nil, store.ErrStateNotFound
+	}
+
+	return &BundleResponse{
+		Root:  req.root,
+		Slot:  slot,
+		Block: blockSSZ,
+		State: *state,
+	}, nil
+}
+
 // Slot returns the beacon slot for checkpointz.
 func (h *Handler) V1BeaconSlots(ctx context.Context, req *BeaconSlotsRequest) (*BeaconSlotsResponse, error) {
 	response := &BeaconSlotsResponse{}
@@ -0,0 +1,157 @@
+package checkpointz
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeStatusProvider reports a fixed status. Only the methods used by the status endpoint are implemented.
+type fakeStatusProvider struct {
+	beacon.FinalityProvider
+
+	head         *v1.Finality
+	finalized    *v1.Finality
+	healthy      bool
+	stalled      error
+	lastQuorumAt time.Time
+	servingSince time.Time
+}
+
+func (f *fakeStatusProvider) OperatingMode() beacon.OperatingMode {
+	return beacon.OperatingModeFull
+}
+
+func (f *fakeStatusProvider) UpstreamsStatus(ctx context.Context) (map[string]*beacon.UpstreamStatus, error) {
+	return map[string]*beacon.UpstreamStatus{}, nil
+}
+
+func (f *fakeStatusProvider) Healthy(ctx context.Context) (bool, error) {
+	return f.healthy, nil
+}
+
+func (f *fakeStatusProvider) Pinned(ctx context.Context) *v1.Finality {
+	return nil
+}
+
+func (f *fakeStatusProvider) Syncing(ctx context.Context) (*v1.SyncState, error) {
+	return nil, beacon.ErrSpecNotAvailable
+}
+
+func (f *fakeStatusProvider) LastFinalityQuorum(ctx context.Context) time.Time {
+	return f.lastQuorumAt
+}
+
+func (f *fakeStatusProvider) ServingSince(ctx context.Context) time.Time {
+	return f.servingSince
+}
+
+func (f *fakeStatusProvider) Head(ctx context.Context) (*v1.Finality, error) {
+	return f.head, nil
+}
+
+func (f *fakeStatusProvider) Justified(ctx context.Context) (*phase0.Checkpoint, error) {
+	return nil, errors.New("no justified checkpoint")
+}
+
+func (f *fakeStatusProvider) FinalityStalled(ctx context.Context) error {
+	return f.stalled
+}
+
+func (f *fakeStatusProvider) Finalized(ctx context.Context) (*v1.Finality, error) {
+	if f.stalled != nil {
+		return nil, f.stalled
+	}
+
+	return f.finalized, nil
+}
+
+func TestV1Status(t *testing.T) {
+	head := &v1.Finality{Finalized: &phase0.Checkpoint{Epoch: 3, Root: phase0.Root{0x03}}}
+	finalized := &v1.Finality{Finalized: &phase0.Checkpoint{Epoch: 2, Root: phase0.Root{0x02}}}
+	lastQuorumAt := time.Now().Add(-time.Minute)
+	servingSince := time.Now().Add(-time.Hour)
+
+	t.Run("serving", func(t *testing.T) {
+		h := NewHandler(logrus.New(), &fakeStatusProvider{
+			head:         head,
+			finalized:    finalized,
+			healthy:      true,
+			lastQuorumAt: lastQuorumAt,
+			servingSince: servingSince,
+		})
+
+		status, err := h.V1Status(context.Background(), NewStatusRequest())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !status.Healthy {
+			t.Error("expected the status to be healthy")
+		}
+
+		if status.Head != head {
+			t.Errorf("expected head %v, got %v", head, status.Head)
+		}
+
+		if status.Finality != finalized {
+			t.Errorf("expected finality %v, got %v", finalized, status.Finality)
+		}
+
+		if status.LastQuorumAt == nil || !status.LastQuorumAt.Equal(lastQuorumAt) {
+			t.Errorf("expected the last finality quorum at %v, got %v", lastQuorumAt, status.LastQuorumAt)
+		}
+
+		if status.ServingSince == nil || !status.ServingSince.Equal(servingSince) {
+			t.Errorf("expected the checkpoint to be served since %v, got %v", servingSince, status.ServingSince)
+		}
+
+		if status.Syncing != nil {
+			t.Errorf("expected no sync state without a spec, got %v", status.Syncing)
+		}
+
+		if status.Stalled != "" {
+			t.Errorf("expected finality to not be stalled, got %q", status.Stalled)
+		}
+	})
+
+	t.Run("stalled", func(t *testing.T) {
+		h := NewHandler(logrus.New(), &fakeStatusProvider{
+			head:         head,
+			finalized:    finalized,
+			stalled:      beacon.ErrFinalityStalled,
+			lastQuorumAt: lastQuorumAt,
+		})
+
+		status, err := h.V1Status(context.Background(), NewStatusRequest())
+		if err != nil {
+			t.Fatalf("expected the status to be reported while finality is stalled, got %v", err)
+		}
+
+		if status.Healthy {
+			t.Error("expected the status to be unhealthy")
+		}
+
+		if status.Stalled == "" {
+			t.Error("expected the stall to be reported")
+		}
+
+		if status.Finality != nil {
+			t.Errorf("expected no finality while stalled, got %v", status.Finality)
+		}
+
+		if status.Head != head {
+			t.Errorf("expected the head to still be reported, got %v", status.Head)
+		}
+
+		if status.ServingSince != nil {
+			t.Errorf("expected no serving time before anything is served, got %v", status.ServingSince)
+		}
+	})
+}
@@ -13,6 +13,100 @@ func NewStatusRequest() *StatusRequest {
 	return &StatusRequest{}
 }
 
+type ReadyRequest struct {
+}
+
+func (r *ReadyRequest) Validate() error {
+	return nil
+}
+
+func NewReadyRequest() *ReadyRequest {
+	return &ReadyRequest{}
+}
+
+type DebugCacheRequest struct {
+}
+
+func (r *DebugCacheRequest) Validate() error {
+	return nil
+}
+
+func NewDebugCacheRequest() *DebugCacheRequest {
+	return &DebugCacheRequest{}
+}
+
+type AdminRecheckRequest struct {
+}
+
+func (r *AdminRecheckRequest) Validate() error {
+	return nil
+}
+
+func NewAdminRecheckRequest() *AdminRecheckRequest {
+	return &AdminRecheckRequest{}
+}
+
+type AdminFetchRequest struct {
+	root phase0.Root
+}
+
+func (r *AdminFetchRequest) Validate() error {
+	return nil
+}
+
+func NewAdminFetchRequest(root phase0.Root) *AdminFetchRequest {
+	return &AdminFetchRequest{
+		root: root,
+	}
+}
+
+type AdminPinRequest struct {
+	root phase0.Root
+}
+
+func (r *AdminPinRequest) Validate() error {
+	return nil
+}
+
+func NewAdminPinRequest(root phase0.Root) *AdminPinRequest {
+	return &AdminPinRequest{
+		root: root,
+	}
+}
+
+type AdminUnpinRequest struct {
+}
+
+func (r *AdminUnpinRequest) Validate() error {
+	return nil
+}
+
+func NewAdminUnpinRequest() *AdminUnpinRequest {
+	return &AdminUnpinRequest{}
+}
+
+type SelfTestRequest struct {
+}
+
+func (r *SelfTestRequest) Validate() error {
+	return nil
+}
+
+func NewSelfTestRequest() *SelfTestRequest {
+	return &SelfTestRequest{}
+}
+
+type CheckpointsRequest struct {
+}
+
+func (r *CheckpointsRequest) Validate() error {
+	return nil
+}
+
+func NewCheckpointsRequest() *CheckpointsRequest {
+	return &CheckpointsRequest{}
+}
+
 type BeaconSlotsRequest struct {
 }
 
@@ -37,3 +131,17 @@ func NewBeaconSlotRequest(slot phase0.Slot) *BeaconSlotRequest {
 		slot: slot,
 	}
 }
+
+type BundleRequest struct {
+	root phase0.Root
+}
+
+func (r *BundleRequest) Validate() error {
+	return nil
+}
+
+func NewBundleRequest(root phase0.Root) *BundleRequest {
+	return &BundleRequest{
+		root: root,
+	}
+}
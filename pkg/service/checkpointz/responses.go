@@ -1,6 +1,8 @@
 package checkpointz
 
 import (
+	"time"
+
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
@@ -11,6 +13,14 @@ import (
 type StatusResponse struct {
 	Upstreams     map[string]*beacon.UpstreamStatus `json:"upstreams"`
 	Finality      *v1.Finality                      `json:"finality"`
+	Head          *v1.Finality                      `json:"head,omitempty"`
+	Justified     *phase0.Checkpoint                `json:"justified,omitempty"`
+	Healthy       bool                              `json:"healthy"`
+	Syncing       *v1.SyncState                     `json:"syncing,omitempty"`
+	LastQuorumAt  *time.Time                        `json:"last_finality_quorum_at,omitempty"`
+	ServingSince  *time.Time                        `json:"serving_since,omitempty"`
+	Stalled       string                            `json:"finality_stalled,omitempty"`
+	Pinned        bool                              `json:"pinned"`
 	PublicURL     string                            `json:"public_url,omitempty"`
 	BrandName     string                            `json:"brand_name,omitempty"`
 	BrandImageURL string                            `json:"brand_image_url,omitempty"`
@@ -42,3 +52,28 @@ type BeaconSlotResponse struct {
 	Epoch    phase0.Epoch                     `json:"epoch"`
 	SlotTime eth.SlotTime                     `json:"time"`
 }
+
+// BundleResponse holds the SSZ encoded block and state of a checkpoint bundle.
+type BundleResponse struct {
+	Root  phase0.Root
+	Slot  phase0.Slot
+	Block []byte
+	State []byte
+}
+
+// SelfTestResponse is the report of a self test of the served checkpoint.
+type SelfTestResponse struct {
+	Passed     bool           `json:"passed"`
+	BlockRoot  string         `json:"block_root,omitempty"`
+	Slot       phase0.Slot    `json:"slot,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+	Steps      []SelfTestStep `json:"steps"`
+}
+
+// SelfTestStep is the result of one step of a self test.
+type SelfTestStep struct {
+	Name       string `json:"name"`
+	Passed     bool   `json:"passed"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,173 @@
+package checkpointz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
+)
+
+const (
+	SelfTestStepFinalizedBlock = "finalized_block"
+	SelfTestStepFinalizedState = "finalized_state"
+	SelfTestStepAlignment      = "alignment"
+	SelfTestStepStateRoot      = "state_root"
+)
+
+// ErrCheckpointMisaligned is returned when the finalized block isn't the block a checkpoint sync client expects for
+// the finalized checkpoint.
+var ErrCheckpointMisaligned = errors.New("finalized block does not match the finalized checkpoint")
+
+// V1SelfTest runs the same sequence a checkpoint sync client would against the served checkpoint: it fetches the
+// finalized block and its state, checks the block is the finalized checkpoint's block, and checks the state hashes
+// to the block's state root. Steps after a failing step are not run.
+func (h *Handler) V1SelfTest(ctx context.Context, req *SelfTestRequest) (*SelfTestResponse, error) {
+	started := time.Now()
+
+	rsp := &SelfTestResponse{
+		Steps: []SelfTestStep{},
+	}
+
+	var (
+		block *spec.VersionedSignedBeaconBlock
+		state *[]byte
+	)
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{
+			name: SelfTestStepFinalizedBlock,
+			run: func() error {
+				var err error
+
+				block, err = h.provider.GetFinalizedBlock(ctx)
+				if err != nil {
+					return err
+				}
+
+				root, err := block.Root()
+				if err != nil {
+					return err
+				}
+
+				slot, err := block.Slot()
+				if err != nil {
+					return err
+				}
+
+				rsp.BlockRoot = eth.RootAsString(root)
+				rsp.Slot = slot
+
+				return nil
+			},
+		},
+		{
+			name: SelfTestStepFinalizedState,
+			run: func() error {
+				root, err := block.Root()
+				if err != nil {
+					return err
+				}
+
+				state, err = h.provider.GetBeaconStateByRoot(ctx, root)
+				if err != nil {
+					return err
+				}
+
+				if state == nil {
+					return store.ErrStateNotFound
+				}
+
+				return nil
+			},
+		},
+		{
+			name: SelfTestStepAlignment,
+			run: func() error {
+				return h.verifyCheckpointAlignment(ctx, block)
+			},
+		},
+		{
+			name: SelfTestStepStateRoot,
+			run: func() error {
+				return beacon.VerifyStateRoot(block, *state)
+			},
+		},
+	}
+
+	rsp.Passed = true
+
+	for _, step := range steps {
+		stepStarted := time.Now()
+
+		err := step.run()
+
+		result := SelfTestStep{
+			Name:       step.name,
+			Passed:     err == nil,
+			DurationMS: time.Since(stepStarted).Milliseconds(),
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		rsp.Steps = append(rsp.Steps, result)
+
+		if err != nil {
+			rsp.Passed = false
+
+			break
+		}
+	}
+
+	rsp.DurationMS = time.Since(started).Milliseconds()
+
+	return rsp, nil
+}
+
+// verifyCheckpointAlignment checks that the block is the finalized checkpoint's block. The checkpoint's block is the
+// block at the first slot of its epoch, or the last block before it if that slot was skipped.
+func (h *Handler) verifyCheckpointAlignment(ctx context.Context, block *spec.VersionedSignedBeaconBlock) error {
+	finality, err := h.provider.Finalized(ctx)
+	if err != nil {
+		return err
+	}
+
+	if finality == nil || finality.Finalized == nil {
+		return beacon.ErrFinalityNotFound
+	}
+
+	sp, err := h.provider.Spec(ctx)
+	if err != nil {
+		return err
+	}
+
+	root, err := block.Root()
+	if err != nil {
+		return err
+	}
+
+	slot, err := block.Slot()
+	if err != nil {
+		return err
+	}
+
+	if root != finality.Finalized.Root {
+		return fmt.Errorf("%w: expected root %s, got %s", ErrCheckpointMisaligned, eth.RootAsString(finality.Finalized.Root), eth.RootAsString(root))
+	}
+
+	if epochStart := phase0.Slot(finality.Finalized.Epoch) * sp.SlotsPerEpoch; slot > epochStart {
+		return fmt.Errorf("%w: block slot %d is after the checkpoint's epoch start slot %d", ErrCheckpointMisaligned, slot, epochStart)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package checkpointz
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeProvider serves a single finalized block and state. Only the methods used by the self test are implemented.
+type fakeProvider struct {
+	beacon.FinalityProvider
+
+	block     *spec.VersionedSignedBeaconBlock
+	state     *[]byte
+	finalized *phase0.Checkpoint
+}
+
+func (f *fakeProvider) GetFinalizedBlock(ctx context.Context) (*spec.VersionedSignedBeaconBlock, error) {
+	if f.block == nil {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return f.block, nil
+}
+
+func (f *fakeProvider) GetBeaconStateByRoot(ctx context.Context, root phase0.Root) (*[]byte, error) {
+	if f.state == nil {
+		return nil, store.ErrStateNotFound
+	}
+
+	return f.state, nil
+}
+
+func (f *fakeProvider) Finalized(ctx context.Context) (*v1.Finality, error) {
+	return &v1.Finality{Finalized: f.finalized}, nil
+}
+
+func (f *fakeProvider) Spec(ctx context.Context) (*state.Spec, error) {
+	return &state.Spec{SlotsPerEpoch: 32}, nil
+}
+
+func newTestBundle(t *testing.T, slot phase0.Slot) (*spec.VersionedSignedBeaconBlock, *[]byte) {
+	t.Helper()
+
+	block, data, err := beacontest.AltairBundle(slot)
+	if err != nil {
+		t.Fatalf("failed to build bundle: %v", err)
+	}
+
+	return block, &data
+}
+
+func TestV1SelfTest(t *testing.T) {
+	block, data := newTestBundle(t, 64)
+
+	root, err := block.Root()
+	if err != nil {
+		t.Fatalf("failed to hash block: %v", err)
+	}
+
+	mismatched, _ := newTestBundle(t, 64)
+	mismatched.Altair.Message.StateRoot = phase0.Root{0x01}
+
+	mismatchedRoot, err := mismatched.Root()
+	if err != nil {
+		t.Fatalf("failed to hash block: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		provider *fakeProvider
+		passed   bool
+		steps    int
+		failedAt string
+	}{
+		{
+			name:     "serviceable",
+			provider: &fakeProvider{block: block, state: data, finalized: &phase0.Checkpoint{Epoch: 2, Root: root}},
+			passed:   true,
+			steps:    4,
+		},
+		{
+			name:     "skipped epoch start slot",
+			provider: &fakeProvider{block: block, state: data, finalized: &phase0.Checkpoint{Epoch: 3, Root: root}},
+			passed:   true,
+			steps:    4,
+		},
+		{
+			name:     "no finalized block",
+			provider: &fakeProvider{finalized: &phase0.Checkpoint{Epoch: 2, Root: root}},
+			steps:    1,
+			failedAt: SelfTestStepFinalizedBlock,
+		},
+		{
+			name:     "no state",
+			provider: &fakeProvider{block: block, finalized: &phase0.Checkpoint{Epoch: 2, Root: root}},
+			steps:    2,
+			failedAt: SelfTestStepFinalizedState,
+		},
+		{
+			name:     "different finalized root",
+			provider: &fakeProvider{block: block, state: data, finalized: &phase0.Checkpoint{Epoch: 2, Root: phase0.Root{0x01}}},
+			steps:    3,
+			failedAt: SelfTestStepAlignment,
+		},
+		{
+			name:     "block after the epoch start",
+			provider: &fakeProvider{block: block, state: data, finalized: &phase0.Checkpoint{Epoch: 1, Root: root}},
+			steps:    3,
+			failedAt: SelfTestStepAlignment,
+		},
+		{
+			name:     "state does not match block",
+			provider: &fakeProvider{block: mismatched, state: data, finalized: &phase0.Checkpoint{Epoch: 2, Root: mismatchedRoot}},
+			steps:    4,
+			failedAt: SelfTestStepStateRoot,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := NewHandler(logrus.New(), test.provider)
+
+			rsp, err := h.V1SelfTest(context.Background(), NewSelfTestRequest())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rsp.Passed != test.passed {
+				t.Errorf("expected passed %v, got %v: %+v", test.passed, rsp.Passed, rsp.Steps)
+			}
+
+			if len(rsp.Steps) != test.steps {
+				t.Fatalf("expected %d steps, got %d: %+v", test.steps, len(rsp.Steps), rsp.Steps)
+			}
+
+			last := rsp.Steps[len(rsp.Steps)-1]
+			if test.failedAt != "" && (last.Name != test.failedAt || last.Passed || last.Error == "") {
+				t.Errorf("expected step %s to fail, got %+v", test.failedAt, last)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ const (
 	BlockIDFinalized
 	BlockIDSlot
 	BlockIDRoot
+	BlockIDJustified
 )
 
 type BlockIdentifier struct {
@@ -54,22 +55,26 @@ func (id BlockIdentifier) AsSlot() (phase0.Slot, error) {
 	return NewSlotFromString(id.v)
 }
 
-func NewBlockIdentifier(id string) (BlockIdentifier, error) {
-	switch id {
-	case string(IDHead):
+// ParseBlockID parses a Beacon API block_id, returning an error for malformed slots and roots.
+func ParseBlockID(id string) (BlockIdentifier, error) {
+	t, err := parseID(id)
+	if err != nil {
+		return newBlockIdentifier(BlockIDInvalid, id), fmt.Errorf("invalid block ID: %s: %w", id, err)
+	}
+
+	switch t {
+	case IDHead:
 		return newBlockIdentifier(BlockIDHead, id), nil
-	case string(IDGenesis):
+	case IDGenesis:
 		return newBlockIdentifier(BlockIDGenesis, id), nil
-	case string(IDFinalized):
+	case IDFinalized:
 		return newBlockIdentifier(BlockIDFinalized, id), nil
-	}
-
-	if strings.HasPrefix(id, "0x") {
-		return newBlockIdentifier(BlockIDRoot, id), nil
-	}
-
-	if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+	case IDJustified:
+		return newBlockIdentifier(BlockIDJustified, id), nil
+	case IDSlot:
 		return newBlockIdentifier(BlockIDSlot, id), nil
+	case IDRoot:
+		return newBlockIdentifier(BlockIDRoot, id), nil
 	}
 
 	return newBlockIdentifier(BlockIDInvalid, id), fmt.Errorf("invalid block ID: %s", id)
@@ -83,7 +88,7 @@ func newBlockIdentifier(id BlockIDType, value string) BlockIdentifier {
 }
 
 func NewSlotFromString(id string) (phase0.Slot, error) {
-	slot, err := strconv.ParseInt(id, 10, 64)
+	slot, err := strconv.ParseUint(id, 10, 64)
 	if err != nil {
 		return 0, err
 	}
@@ -120,6 +125,8 @@ func (t BlockIDType) String() string {
 		return string(IDSlot)
 	case BlockIDRoot:
 		return string(IDRoot)
+	case BlockIDJustified:
+		return string(IDJustified)
 	}
 
 	return string(IDInvalid)
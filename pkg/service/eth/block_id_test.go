@@ -1,6 +1,10 @@
 package eth
 
-import "testing"
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
 
 func TestBlockIDMapping(t *testing.T) {
 	t.Parallel()
@@ -12,7 +16,9 @@ func TestBlockIDMapping(t *testing.T) {
 		{"head", BlockIDHead},
 		{"genesis", BlockIDGenesis},
 		{"finalized", BlockIDFinalized},
+		{"justified", BlockIDJustified},
 		{"10", BlockIDSlot},
+		{"18446744073709551615", BlockIDSlot},
 		{"0x4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da59", BlockIDRoot},
 	}
 
@@ -22,7 +28,7 @@ func TestBlockIDMapping(t *testing.T) {
 
 			t.Parallel()
 
-			if id, err := NewBlockIdentifier(test.id); err != nil {
+			if id, err := ParseBlockID(test.id); err != nil {
 				t.Fatal(err)
 			} else if id.Type() != test.expect {
 				t.Errorf("Expected %d, got %d", test.expect, id.Type())
@@ -30,3 +36,80 @@ func TestBlockIDMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBlockIDInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"",
+		"Head",
+		"-1",
+		"+1",
+		"1.5",
+		"abc",
+		"18446744073709551616",
+		"0x",
+		"0x4a74",
+		"0x4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da5",
+		"0x4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da5900",
+		"0xzz74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da59",
+		"0X4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da59",
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test, func(t *testing.T) {
+			t.Parallel()
+
+			id, err := ParseBlockID(test)
+			if err == nil {
+				t.Fatalf("expected an error, got %v", id.Type())
+			}
+
+			if id.Type() != BlockIDInvalid {
+				t.Errorf("expected an invalid block ID, got %v", id.Type())
+			}
+		})
+	}
+}
+
+func FuzzParseBlockID(f *testing.F) {
+	for _, seed := range []string{"head", "genesis", "finalized", "justified", "0", "12345", "-1", "0x", "0x00",
+		"0x4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da59", "18446744073709551616"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		id, err := ParseBlockID(input)
+		if err != nil {
+			if id.Type() != BlockIDInvalid {
+				t.Fatalf("error returned with a valid type %v for %q", id.Type(), input)
+			}
+
+			return
+		}
+
+		switch id.Type() {
+		case BlockIDSlot:
+			slot, err := id.AsSlot()
+			if err != nil {
+				t.Fatalf("slot %q did not convert: %v", input, err)
+			}
+
+			if strings.TrimLeft(input, "0") != strings.TrimLeft(strconv.FormatUint(uint64(slot), 10), "0") {
+				t.Fatalf("slot %q mis-parsed as %d", input, slot)
+			}
+		case BlockIDRoot:
+			if _, err := id.AsRoot(); err != nil {
+				t.Fatalf("root %q did not convert: %v", input, err)
+			}
+		case BlockIDHead, BlockIDGenesis, BlockIDFinalized, BlockIDJustified:
+			if input != id.Type().String() {
+				t.Fatalf("%q parsed as %v", input, id.Type())
+			}
+		default:
+			t.Fatalf("no error for invalid block ID %q", input)
+		}
+	})
+}
@@ -2,14 +2,19 @@ package eth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/attestantio/go-eth2-client/api"
 	v1 "github.com/attestantio/go-eth2-client/api/v1"
 	"github.com/attestantio/go-eth2-client/spec"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethpandaops/beacon/pkg/beacon/api/types"
 	"github.com/ethpandaops/beacon/pkg/beacon/state"
 	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/ethpandaops/checkpointz/pkg/eth"
 	"github.com/ethpandaops/checkpointz/pkg/version"
 	"github.com/sirupsen/logrus"
 )
@@ -20,15 +25,38 @@ type Handler struct {
 	log      logrus.FieldLogger
 	provider beacon.FinalityProvider
 
+	// randaoMixes caches the RANDAO mixes vectors decoded from states by state root, as decoding a state is expensive.
+	randaoMixes   map[phase0.Root]*beacon.RandaoMixes
+	randaoMixesMu sync.Mutex
+
+	// finalities caches the finality checkpoints decoded from states by state root.
+	finalities   map[phase0.Root]*v1.Finality
+	finalitiesMu sync.Mutex
+
+	// validators caches the validators decoded from states by state root.
+	validators   map[phase0.Root][]*v1.Validator
+	validatorsMu sync.Mutex
+
 	metrics *Metrics
 }
 
+// maxCachedFinalities bounds the finality checkpoints cache. It is emptied when full.
+const maxCachedFinalities = 1024
+
+// maxCachedRandaoMixes bounds the RANDAO mixes cache. Each entry holds a state's whole mixes vector, 2MiB on
+// mainnet, so only a few are kept. It is emptied when full.
+const maxCachedRandaoMixes = 16
+
 // NewHandler returns a new Handler instance.
 func NewHandler(log logrus.FieldLogger, beac beacon.FinalityProvider, namespace string) *Handler {
 	return &Handler{
 		log:      log.WithField("module", "service/eth"),
 		provider: beac,
 
+		randaoMixes: make(map[phase0.Root]*beacon.RandaoMixes),
+		finalities:  make(map[phase0.Root]*v1.Finality),
+		validators:  make(map[phase0.Root][]*v1.Validator),
+
 		metrics: NewMetrics(namespace),
 	}
 }
@@ -65,21 +93,59 @@ func (h *Handler) BeaconBlock(ctx context.Context, blockID BlockIdentifier) (*sp
 
 		return h.provider.GetBlockByRoot(ctx, root)
 	case BlockIDFinalized:
-		finality, err := h.provider.Finalized(ctx)
+		return h.provider.GetFinalizedBlock(ctx)
+	case BlockIDJustified:
+		root, err := h.justifiedRoot(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		if finality == nil || finality.Finalized == nil {
-			return nil, fmt.Errorf("no finality")
-		}
-
-		return h.provider.GetBlockByRoot(ctx, finality.Finalized.Root)
+		return h.provider.GetBlockByRoot(ctx, root)
+	case BlockIDHead:
+		return h.provider.GetHeadBlock(ctx)
 	default:
 		return nil, fmt.Errorf("invalid block id: %v", blockID.String())
 	}
 }
 
+// justifiedRoot returns the block root of the justified checkpoint being served.
+func (h *Handler) justifiedRoot(ctx context.Context) (phase0.Root, error) {
+	justified, err := h.provider.Justified(ctx)
+	if err != nil {
+		if errors.Is(err, beacon.ErrJustifiedNotAvailable) {
+			return phase0.Root{}, fmt.Errorf("%w: %s", store.ErrBlockNotFound, err)
+		}
+
+		return phase0.Root{}, err
+	}
+
+	return justified.Root, nil
+}
+
+// BlockHeader returns the header of the beacon block for the given block id.
+func (h *Handler) BlockHeader(ctx context.Context, blockID BlockIdentifier) (*v1.BeaconBlockHeader, error) {
+	var err error
+
+	const call = "block_header"
+
+	h.metrics.ObserveCall(call, blockID.Type().String())
+
+	defer func() {
+		if err != nil {
+			h.metrics.ObserveErrorCall(call, blockID.Type().String())
+		}
+	}()
+
+	block, err := h.BeaconBlock(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := eth.NewBeaconBlockHeader(block)
+
+	return header, err
+}
+
 // BeaconGenesis returns the details of the chain's genesis.
 func (h *Handler) BeaconGenesis(ctx context.Context) (*v1.Genesis, error) {
 	var err error
@@ -94,7 +160,9 @@ func (h *Handler) BeaconGenesis(ctx context.Context) (*v1.Genesis, error) {
 		}
 	}()
 
-	return h.provider.Genesis(ctx)
+	genesis, err := h.provider.Genesis(ctx)
+
+	return genesis, err
 }
 
 // ConfigSpec gets the spec configuration.
@@ -111,7 +179,9 @@ func (h *Handler) ConfigSpec(ctx context.Context) (*state.Spec, error) {
 		}
 	}()
 
-	return h.provider.Spec(ctx)
+	sp, err := h.provider.Spec(ctx)
+
+	return sp, err
 }
 
 // ForkSchedule returns the upcoming forks.
@@ -291,21 +361,149 @@ func (h *Handler) BeaconState(ctx context.Context, stateID StateIdentifier) (*[]
 
 		return h.provider.GetBeaconStateByStateRoot(ctx, root)
 	case StateIDFinalized:
-		finality, err := h.provider.Finalized(ctx)
+		root, err := h.finalizedStateBlockRoot(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		if finality == nil || finality.Finalized == nil {
-			return nil, fmt.Errorf("no finality known")
+		return h.provider.GetBeaconStateByRoot(ctx, root)
+	case StateIDJustified:
+		root, err := h.justifiedRoot(ctx)
+		if err != nil {
+			return nil, err
 		}
 
-		return h.provider.GetBeaconStateByRoot(ctx, finality.Finalized.Root)
+		return h.provider.GetBeaconStateByRoot(ctx, root)
 	case StateIDGenesis:
 		return h.provider.GetBeaconStateBySlot(ctx, phase0.Slot(0))
+	case StateIDHead:
+		return h.provider.GetHeadBeaconState(ctx)
+	default:
+		return nil, fmt.Errorf("invalid state id: %v", stateID.String())
+	}
+}
+
+// finalizedStateBlockRoot returns the block root whose state should be served for the finalized state id.
+// If the serving bundle's state is no longer cached we fall back to a recently served bundle so clients
+// mid-sync against a just-superseded checkpoint aren't broken.
+func (h *Handler) finalizedStateBlockRoot(ctx context.Context) (phase0.Root, error) {
+	finality, err := h.provider.Finalized(ctx)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	if finality == nil || finality.Finalized == nil {
+		return phase0.Root{}, fmt.Errorf("no finality known")
+	}
+
+	for _, bundle := range h.provider.ServedBundles(ctx) {
+		if bundle == nil || bundle.Finalized == nil {
+			continue
+		}
+
+		if _, err := h.provider.GetBeaconStateByRoot(ctx, bundle.Finalized.Root); err == nil {
+			return bundle.Finalized.Root, nil
+		}
+	}
+
+	return finality.Finalized.Root, nil
+}
+
+// BeaconStateVersion returns the fork name of the state for the given state id.
+func (h *Handler) BeaconStateVersion(ctx context.Context, stateID StateIdentifier) (string, error) {
+	block, err := h.stateBlock(ctx, stateID)
+	if err != nil {
+		return "", err
+	}
+
+	return h.ConsensusVersion(ctx, block), nil
+}
+
+// stateBlock returns the block whose post-state is the state for the given state id.
+func (h *Handler) stateBlock(ctx context.Context, stateID StateIdentifier) (*spec.VersionedSignedBeaconBlock, error) {
+	var block *spec.VersionedSignedBeaconBlock
+
+	switch stateID.Type() {
+	case StateIDSlot:
+		slot, err := NewSlotFromString(stateID.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		block, err = h.provider.GetBlockBySlot(ctx, slot)
+		if err != nil {
+			return nil, err
+		}
+	case StateIDRoot:
+		root, err := stateID.AsRoot()
+		if err != nil {
+			return nil, err
+		}
+
+		block, err = h.provider.GetBlockByStateRoot(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+	case StateIDFinalized:
+		root, err := h.finalizedStateBlockRoot(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		block, err = h.provider.GetBlockByRoot(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+	case StateIDJustified:
+		root, err := h.justifiedRoot(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		block, err = h.provider.GetBlockByRoot(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+	case StateIDGenesis:
+		var err error
+
+		block, err = h.provider.GetBlockBySlot(ctx, phase0.Slot(0))
+		if err != nil {
+			return nil, err
+		}
+	case StateIDHead:
+		var err error
+
+		block, err = h.provider.GetHeadBlock(ctx)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("invalid state id: %v", stateID.String())
 	}
+
+	if block == nil {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return block, nil
+}
+
+// ConsensusVersion returns the fork name of the given block for use in the Eth-Consensus-Version header.
+// The fork is derived from the spec's fork schedule, falling back to the block's own version if the spec
+// is not available.
+func (h *Handler) ConsensusVersion(ctx context.Context, block *spec.VersionedSignedBeaconBlock) string {
+	slot, err := block.Slot()
+	if err != nil {
+		return block.Version.String()
+	}
+
+	fork, err := h.provider.ForkAtSlot(ctx, slot)
+	if err != nil {
+		return block.Version.String()
+	}
+
+	return fork
 }
 
 // FinalityCheckpoints returns the finality checkpoints for the given state id.
@@ -345,11 +543,209 @@ func (h *Handler) FinalityCheckpoints(ctx context.Context, stateID StateIdentifi
 		}
 
 		return finality, nil
+	case StateIDSlot, StateIDRoot, StateIDGenesis, StateIDJustified:
+		var block *spec.VersionedSignedBeaconBlock
+
+		block, err = h.stateBlock(ctx, stateID)
+		if err != nil {
+			return nil, err
+		}
+
+		var stateRoot phase0.Root
+
+		stateRoot, err = block.StateRoot()
+		if err != nil {
+			return nil, err
+		}
+
+		var finality *v1.Finality
+
+		finality, err = h.stateFinality(ctx, block.Version, stateRoot)
+
+		return finality, err
 	default:
 		return nil, fmt.Errorf("invalid state id: %v", stateID.String())
 	}
 }
 
+// stateFinality returns the finality checkpoints of the state with the given root, decoding the state if they aren't
+// already cached.
+func (h *Handler) stateFinality(ctx context.Context, version spec.DataVersion, stateRoot phase0.Root) (*v1.Finality, error) {
+	h.finalitiesMu.Lock()
+	finality, ok := h.finalities[stateRoot]
+	h.finalitiesMu.Unlock()
+
+	if ok {
+		return finality, nil
+	}
+
+	data, err := h.provider.GetBeaconStateByStateRoot(ctx, stateRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, store.ErrStateNotFound
+	}
+
+	finality, err = beacon.StateFinality(version, *data)
+	if err != nil {
+		return nil, err
+	}
+
+	h.finalitiesMu.Lock()
+	if len(h.finalities) >= maxCachedFinalities {
+		h.finalities = make(map[phase0.Root]*v1.Finality)
+	}
+
+	h.finalities[stateRoot] = finality
+	h.finalitiesMu.Unlock()
+
+	return finality, nil
+}
+
+// RandaoMix returns the RANDAO mix of the epoch from the state for the given state id. If epoch is nil the mix of
+// the state's own epoch is returned.
+func (h *Handler) RandaoMix(ctx context.Context, stateID StateIdentifier, epoch *phase0.Epoch) (phase0.Root, error) {
+	var err error
+
+	const call = "randao"
+
+	h.metrics.ObserveCall(call, stateID.Type().String())
+
+	defer func() {
+		if err != nil {
+			h.metrics.ObserveErrorCall(call, stateID.Type().String())
+		}
+	}()
+
+	var block *spec.VersionedSignedBeaconBlock
+
+	block, err = h.stateBlock(ctx, stateID)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	var sp *state.Spec
+
+	sp, err = h.provider.Spec(ctx)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	var (
+		slot      phase0.Slot
+		stateRoot phase0.Root
+	)
+
+	if slot, err = block.Slot(); err != nil {
+		return phase0.Root{}, err
+	}
+
+	if stateRoot, err = block.StateRoot(); err != nil {
+		return phase0.Root{}, err
+	}
+
+	if epoch == nil {
+		current := phase0.Epoch(slot / sp.SlotsPerEpoch)
+		epoch = &current
+	}
+
+	var mixes *beacon.RandaoMixes
+
+	mixes, err = h.stateRandaoMixes(ctx, stateID, block.Version, stateRoot)
+	if err != nil {
+		return phase0.Root{}, err
+	}
+
+	var mix phase0.Root
+
+	mix, err = mixes.Mix(sp.SlotsPerEpoch, *epoch)
+
+	return mix, err
+}
+
+// stateRandaoMixes returns the RANDAO mixes of the state with the given root, decoding the state if its mixes aren't
+// already cached. The whole vector is cached so requests for other epochs of the same state don't decode it again.
+func (h *Handler) stateRandaoMixes(ctx context.Context, stateID StateIdentifier, version spec.DataVersion, stateRoot phase0.Root) (*beacon.RandaoMixes, error) {
+	h.randaoMixesMu.Lock()
+	mixes, ok := h.randaoMixes[stateRoot]
+	h.randaoMixesMu.Unlock()
+
+	if ok {
+		return mixes, nil
+	}
+
+	data, err := h.BeaconState(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, store.ErrStateNotFound
+	}
+
+	mixes, err = beacon.StateRandaoMixes(version, *data)
+	if err != nil {
+		return nil, err
+	}
+
+	h.randaoMixesMu.Lock()
+	if len(h.randaoMixes) >= maxCachedRandaoMixes {
+		h.randaoMixes = make(map[phase0.Root]*beacon.RandaoMixes)
+	}
+
+	h.randaoMixes[stateRoot] = mixes
+	h.randaoMixesMu.Unlock()
+
+	return mixes, nil
+}
+
+// BlindedBeaconBlock returns the beacon block for the given block ID along with its blinded form. The blinded block
+// is nil for blocks from before execution payloads existed, which are the same blinded or not.
+func (h *Handler) BlindedBeaconBlock(ctx context.Context, blockID BlockIdentifier) (*spec.VersionedSignedBeaconBlock, *api.VersionedSignedBlindedBeaconBlock, error) {
+	block, err := h.BeaconBlock(ctx, blockID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	blinded, err := eth.BlindBeaconBlock(block)
+	if err != nil {
+		if errors.Is(err, eth.ErrBlockNotBlindable) {
+			return block, nil, nil
+		}
+
+		return nil, nil, err
+	}
+
+	return block, blinded, nil
+}
+
+// MarshalBeaconBlockSSZ SSZ encodes the block, reusing the cached encoding if the block is stored.
+func (h *Handler) MarshalBeaconBlockSSZ(ctx context.Context, block *spec.VersionedSignedBeaconBlock) ([]byte, error) {
+	root, err := block.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := h.provider.GetBlockSSZ(ctx, root)
+	if err == nil {
+		return encoded, nil
+	}
+
+	if !errors.Is(err, store.ErrBlockNotFound) {
+		return nil, err
+	}
+
+	// The block isn't stored, e.g. it is the unfinalized head.
+	return store.MarshalBlockSSZ(block)
+}
+
+// MarshalBlindedBeaconBlockSSZ SSZ encodes the blinded block.
+func (h *Handler) MarshalBlindedBeaconBlockSSZ(block *api.VersionedSignedBlindedBeaconBlock) ([]byte, error) {
+	return eth.MarshalBlindedBeaconBlockSSZ(block)
+}
+
 // BlockRoot returns the beacon block root for the given block ID.
 func (h *Handler) BlockRoot(ctx context.Context, blockID BlockIdentifier) (phase0.Root, error) {
 	var err error
@@ -409,22 +805,28 @@ func (h *Handler) BlockRoot(ctx context.Context, blockID BlockIdentifier) (phase
 
 		return block.Root()
 	case BlockIDFinalized:
-		finality, err := h.provider.Finalized(ctx)
+		block, err := h.provider.GetFinalizedBlock(ctx)
 		if err != nil {
 			return phase0.Root{}, err
 		}
 
-		if finality == nil || finality.Finalized == nil {
-			return phase0.Root{}, fmt.Errorf("no finality")
+		return block.Root()
+	case BlockIDJustified:
+		root, err := h.justifiedRoot(ctx)
+		if err != nil {
+			return phase0.Root{}, err
 		}
 
-		block, err := h.provider.GetBlockByRoot(ctx, finality.Finalized.Root)
+		block, err := h.provider.GetBlockByRoot(ctx, root)
 		if err != nil {
 			return phase0.Root{}, err
 		}
 
-		if block == nil {
-			return phase0.Root{}, fmt.Errorf("no block for finalized root %v", finality.Finalized.Root)
+		return block.Root()
+	case BlockIDHead:
+		block, err := h.provider.GetHeadBlock(ctx)
+		if err != nil {
+			return phase0.Root{}, err
 		}
 
 		return block.Root()
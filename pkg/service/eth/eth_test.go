@@ -0,0 +1,290 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec"
+	"github.com/attestantio/go-eth2-client/spec/altair"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/beacon/pkg/beacon/state"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/beacontest"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeProvider serves a single block and its state by state root, counting how often the state is fetched. Only the
+// methods used by the handler's state lookups are implemented.
+type fakeProvider struct {
+	beacon.FinalityProvider
+
+	block        *spec.VersionedSignedBeaconBlock
+	state        *[]byte
+	stateRoot    phase0.Root
+	stateFetches int
+}
+
+func (f *fakeProvider) Spec(ctx context.Context) (*state.Spec, error) {
+	return &state.Spec{SlotsPerEpoch: 32}, nil
+}
+
+func (f *fakeProvider) GetBlockByStateRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	if root != f.stateRoot {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return f.block, nil
+}
+
+func (f *fakeProvider) GetBeaconStateByStateRoot(ctx context.Context, root phase0.Root) (*[]byte, error) {
+	if root != f.stateRoot {
+		return nil, store.ErrStateNotFound
+	}
+
+	f.stateFetches++
+
+	return f.state, nil
+}
+
+// newFakeProvider returns a provider serving an altair state at slot 64 that was modified by update.
+func newFakeProvider(t *testing.T, update func(*altair.BeaconState)) *fakeProvider {
+	t.Helper()
+
+	st := beacontest.AltairState(64)
+	update(st)
+
+	data, err := st.MarshalSSZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stateRoot, err := st.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &fakeProvider{
+		block:     beacontest.AltairBlock(64, stateRoot),
+		state:     &data,
+		stateRoot: stateRoot,
+	}
+}
+
+func TestRandaoMixDecodesEachStateOnce(t *testing.T) {
+	provider := newFakeProvider(t, func(st *altair.BeaconState) {
+		st.RANDAOMixes[1] = phase0.Root{0x01}
+		st.RANDAOMixes[2] = phase0.Root{0x02}
+	})
+
+	h := NewHandler(logrus.New(), provider, "test_randao_cache")
+
+	stateID, err := ParseStateID(fmt.Sprintf("%#x", provider.stateRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ask for every epoch the state holds a mix for, along with one it doesn't.
+	for _, epoch := range []phase0.Epoch{2, 1, 0, 3} {
+		epoch := epoch
+
+		mix, err := h.RandaoMix(context.Background(), stateID, &epoch)
+		if epoch == 3 {
+			if err == nil {
+				t.Error("expected an error for an epoch after the state's")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if expected := (phase0.Root{byte(epoch)}); mix != expected {
+			t.Errorf("expected mix %#x for epoch %d, got %#x", expected, epoch, mix)
+		}
+	}
+
+	mix, err := h.RandaoMix(context.Background(), stateID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mix != (phase0.Root{0x02}) {
+		t.Errorf("expected the mix of the state's epoch, got %#x", mix)
+	}
+
+	if provider.stateFetches != 1 {
+		t.Errorf("expected the state to be decoded once, got %d", provider.stateFetches)
+	}
+}
+
+func TestFinalityCheckpointsDecodesEachStateOnce(t *testing.T) {
+	finalized := &phase0.Checkpoint{Epoch: 1, Root: phase0.Root{0x01}}
+
+	provider := newFakeProvider(t, func(st *altair.BeaconState) {
+		st.FinalizedCheckpoint = finalized
+	})
+
+	h := NewHandler(logrus.New(), provider, "test_finality_cache")
+
+	stateID, err := ParseStateID(fmt.Sprintf("%#x", provider.stateRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		finality, err := h.FinalityCheckpoints(context.Background(), stateID)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if *finality.Finalized != *finalized {
+			t.Errorf("expected finalized %v, got %v", finalized, finality.Finalized)
+		}
+	}
+
+	if provider.stateFetches != 1 {
+		t.Errorf("expected the state to be decoded once, got %d", provider.stateFetches)
+	}
+}
+
+// fakeServedProvider serves the states of recently served bundles that are still cached. Only the methods used to
+// find the finalized state are implemented.
+type fakeServedProvider struct {
+	beacon.FinalityProvider
+
+	served []*v1.Finality
+	states map[phase0.Root][]byte
+}
+
+func (f *fakeServedProvider) Finalized(ctx context.Context) (*v1.Finality, error) {
+	return f.served[0], nil
+}
+
+func (f *fakeServedProvider) ServedBundles(ctx context.Context) []*v1.Finality {
+	return f.served
+}
+
+func (f *fakeServedProvider) GetBeaconStateByRoot(ctx context.Context, root phase0.Root) (*[]byte, error) {
+	state, ok := f.states[root]
+	if !ok {
+		return nil, store.ErrStateNotFound
+	}
+
+	return &state, nil
+}
+
+func TestFinalizedStateFallsBackToRecentlyServedBundles(t *testing.T) {
+	provider := &fakeServedProvider{
+		served: []*v1.Finality{
+			{Finalized: &phase0.Checkpoint{Epoch: 3, Root: phase0.Root{0x03}}},
+			{Finalized: &phase0.Checkpoint{Epoch: 2, Root: phase0.Root{0x02}}},
+		},
+		states: map[phase0.Root][]byte{
+			{0x03}: []byte("serving"),
+			{0x02}: []byte("previous"),
+		},
+	}
+
+	h := NewHandler(logrus.New(), provider, "test_finalized_fallback")
+
+	stateID, err := ParseStateID("finalized")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		evicted []phase0.Root
+		state   string
+		err     bool
+	}{
+		{name: "serving bundle cached", state: "serving"},
+		{name: "serving bundle evicted", evicted: []phase0.Root{{0x03}}, state: "previous"},
+		{name: "every bundle evicted", evicted: []phase0.Root{{0x02}}, err: true},
+	}
+
+	for _, test := range tests {
+		for _, root := range test.evicted {
+			delete(provider.states, root)
+		}
+
+		state, err := h.BeaconState(context.Background(), stateID)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got state %q", test.name, *state)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+
+		if string(*state) != test.state {
+			t.Errorf("%s: expected the %s state, got %q", test.name, test.state, *state)
+		}
+	}
+}
+
+// fakeJustifiedProvider serves the block of the justified checkpoint once it is known. Only the methods used to look
+// up the justified block are implemented.
+type fakeJustifiedProvider struct {
+	beacon.FinalityProvider
+
+	justified *phase0.Checkpoint
+	block     *spec.VersionedSignedBeaconBlock
+}
+
+func (f *fakeJustifiedProvider) Justified(ctx context.Context) (*phase0.Checkpoint, error) {
+	if f.justified == nil {
+		return nil, beacon.ErrJustifiedNotAvailable
+	}
+
+	return f.justified, nil
+}
+
+func (f *fakeJustifiedProvider) GetBlockByRoot(ctx context.Context, root phase0.Root) (*spec.VersionedSignedBeaconBlock, error) {
+	if f.justified == nil || root != f.justified.Root {
+		return nil, store.ErrBlockNotFound
+	}
+
+	return f.block, nil
+}
+
+func TestJustifiedBlock(t *testing.T) {
+	provider := &fakeJustifiedProvider{block: beacontest.Phase0Block(64, phase0.Root{0x01})}
+
+	h := NewHandler(logrus.New(), provider, "test_justified_block")
+
+	blockID, err := ParseBlockID("justified")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.BeaconBlock(context.Background(), blockID); !errors.Is(err, store.ErrBlockNotFound) {
+		t.Errorf("expected the justified block to be not found before it is downloaded, got %v", err)
+	}
+
+	root, err := provider.block.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider.justified = &phase0.Checkpoint{Epoch: 2, Root: root}
+
+	block, err := h.BeaconBlock(context.Background(), blockID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if block != provider.block {
+		t.Error("expected the justified block to be served")
+	}
+}
@@ -1,5 +1,10 @@
 package eth
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ID string
 
 const (
@@ -7,6 +12,30 @@ const (
 	IDHead      ID = "head"
 	IDGenesis   ID = "genesis"
 	IDFinalized ID = "finalized"
+	IDJustified ID = "justified"
 	IDSlot      ID = "slot"
 	IDRoot      ID = "root"
 )
+
+// parseID classifies a block or state id. Slots must be unsigned decimal integers and roots must be 0x prefixed
+// 32 byte hex strings.
+func parseID(id string) (ID, error) {
+	switch ID(id) {
+	case IDHead, IDGenesis, IDFinalized, IDJustified:
+		return ID(id), nil
+	}
+
+	if strings.HasPrefix(id, "0x") {
+		if _, err := NewRootFromString(id); err != nil {
+			return IDInvalid, err
+		}
+
+		return IDRoot, nil
+	}
+
+	if _, err := NewSlotFromString(id); err != nil {
+		return IDInvalid, fmt.Errorf("invalid slot: %w", err)
+	}
+
+	return IDSlot, nil
+}
@@ -2,8 +2,6 @@ package eth
 
 import (
 	"fmt"
-	"strconv"
-	"strings"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 )
@@ -17,6 +15,7 @@ const (
 	StateIDFinalized
 	StateIDSlot
 	StateIDRoot
+	StateIDJustified
 )
 
 type StateIdentifier struct {
@@ -52,22 +51,26 @@ func (id StateIdentifier) AsSlot() (phase0.Slot, error) {
 	return NewSlotFromString(id.v)
 }
 
-func NewStateIdentifier(id string) (StateIdentifier, error) {
-	switch id {
-	case "head":
+// ParseStateID parses a Beacon API state_id, returning an error for malformed slots and roots.
+func ParseStateID(id string) (StateIdentifier, error) {
+	t, err := parseID(id)
+	if err != nil {
+		return newStateIdentifier(StateIDInvalid, id), fmt.Errorf("invalid state ID: %s: %w", id, err)
+	}
+
+	switch t {
+	case IDHead:
 		return newStateIdentifier(StateIDHead, id), nil
-	case "genesis":
+	case IDGenesis:
 		return newStateIdentifier(StateIDGenesis, id), nil
-	case "finalized":
+	case IDFinalized:
 		return newStateIdentifier(StateIDFinalized, id), nil
-	}
-
-	if strings.HasPrefix(id, "0x") {
-		return newStateIdentifier(StateIDRoot, id), nil
-	}
-
-	if _, err := strconv.ParseInt(id, 10, 64); err == nil {
+	case IDJustified:
+		return newStateIdentifier(StateIDJustified, id), nil
+	case IDSlot:
 		return newStateIdentifier(StateIDSlot, id), nil
+	case IDRoot:
+		return newStateIdentifier(StateIDRoot, id), nil
 	}
 
 	return newStateIdentifier(StateIDInvalid, id), fmt.Errorf("invalid state ID: %s", id)
@@ -92,6 +95,8 @@ func (t StateIDType) String() string {
 		return string(IDSlot)
 	case StateIDRoot:
 		return string(IDRoot)
+	case StateIDJustified:
+		return string(IDJustified)
 	}
 
 	return string(IDInvalid)
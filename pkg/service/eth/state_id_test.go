@@ -1,6 +1,10 @@
 package eth
 
-import "testing"
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
 
 func TestStateIDMapping(t *testing.T) {
 	t.Parallel()
@@ -12,6 +16,7 @@ func TestStateIDMapping(t *testing.T) {
 		{"head", StateIDHead},
 		{"genesis", StateIDGenesis},
 		{"finalized", StateIDFinalized},
+		{"justified", StateIDJustified},
 		{"100", StateIDSlot},
 		{"0x4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da59", StateIDRoot},
 	}
@@ -22,7 +27,7 @@ func TestStateIDMapping(t *testing.T) {
 
 			t.Parallel()
 
-			got, err := NewStateIdentifier(test.id)
+			got, err := ParseStateID(test.id)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -33,3 +38,59 @@ func TestStateIDMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStateIDInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []string{"", "-5", "99999999999999999999", "0x1234", "finalised"} {
+		test := test
+
+		t.Run(test, func(t *testing.T) {
+			t.Parallel()
+
+			if id, err := ParseStateID(test); err == nil {
+				t.Fatalf("expected an error, got %v", id.Type())
+			}
+		})
+	}
+}
+
+func FuzzParseStateID(f *testing.F) {
+	for _, seed := range []string{"head", "genesis", "finalized", "justified", "0", "12345", "-1", "0x", "0x00",
+		"0x4a74943698817939e32aa6b2c688ccf1336bbff9190e400cc1360013d635da59", "18446744073709551616"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		id, err := ParseStateID(input)
+		if err != nil {
+			if id.Type() != StateIDInvalid {
+				t.Fatalf("error returned with a valid type %v for %q", id.Type(), input)
+			}
+
+			return
+		}
+
+		switch id.Type() {
+		case StateIDSlot:
+			slot, err := id.AsSlot()
+			if err != nil {
+				t.Fatalf("slot %q did not convert: %v", input, err)
+			}
+
+			if strings.TrimLeft(input, "0") != strings.TrimLeft(strconv.FormatUint(uint64(slot), 10), "0") {
+				t.Fatalf("slot %q mis-parsed as %d", input, slot)
+			}
+		case StateIDRoot:
+			if _, err := id.AsRoot(); err != nil {
+				t.Fatalf("root %q did not convert: %v", input, err)
+			}
+		case StateIDHead, StateIDGenesis, StateIDFinalized, StateIDJustified:
+			if input != id.Type().String() {
+				t.Fatalf("%q parsed as %v", input, id.Type())
+			}
+		default:
+			t.Fatalf("no error for invalid state ID %q", input)
+		}
+	})
+}
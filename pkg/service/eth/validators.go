@@ -0,0 +1,252 @@
+package eth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethpandaops/checkpointz/pkg/beacon"
+	"github.com/ethpandaops/checkpointz/pkg/beacon/store"
+)
+
+var (
+	// ErrInvalidValidatorID is returned when a validator id is neither a validator index nor a public key.
+	ErrInvalidValidatorID = errors.New("invalid validator id")
+	// ErrInvalidValidatorStatus is returned when a validator status filter isn't a known status or status group.
+	ErrInvalidValidatorStatus = errors.New("invalid validator status")
+)
+
+// maxCachedValidatorSets bounds the decoded validator cache. Each entry holds every validator in a state, so only a
+// few are kept. It is emptied when full.
+const maxCachedValidatorSets = 4
+
+// validatorStatusGroups are the status filters that match every status starting with them, e.g. "active" matches
+// "active_ongoing".
+var validatorStatusGroups = []string{"pending", "active", "exited", "withdrawal"}
+
+// validatorFilter selects validators by id and status. Empty filters match every validator.
+type validatorFilter struct {
+	indices  map[phase0.ValidatorIndex]bool
+	pubkeys  map[phase0.BLSPubKey]bool
+	statuses []string
+}
+
+// newValidatorFilter parses validator ids, which are decimal validator indices or 0x prefixed public keys, and
+// validator statuses, which are a status (e.g. "active_ongoing") or a status group (e.g. "active").
+func newValidatorFilter(ids, statuses []string) (*validatorFilter, error) {
+	filter := &validatorFilter{
+		indices: make(map[phase0.ValidatorIndex]bool),
+		pubkeys: make(map[phase0.BLSPubKey]bool),
+	}
+
+	for _, id := range ids {
+		if strings.HasPrefix(id, "0x") {
+			data, err := hex.DecodeString(strings.TrimPrefix(id, "0x"))
+			if err != nil || len(data) != len(phase0.BLSPubKey{}) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidValidatorID, id)
+			}
+
+			var pubkey phase0.BLSPubKey
+
+			copy(pubkey[:], data)
+
+			filter.pubkeys[pubkey] = true
+
+			continue
+		}
+
+		index, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidValidatorID, id)
+		}
+
+		filter.indices[phase0.ValidatorIndex(index)] = true
+	}
+
+	for _, status := range statuses {
+		if !isValidatorStatus(status) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidValidatorStatus, status)
+		}
+
+		filter.statuses = append(filter.statuses, status)
+	}
+
+	return filter, nil
+}
+
+func isValidatorStatus(status string) bool {
+	for _, group := range validatorStatusGroups {
+		if status == group {
+			return true
+		}
+	}
+
+	for s := v1.ValidatorStatePendingInitialized; s <= v1.ValidatorStateWithdrawalDone; s++ {
+		if status == s.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f *validatorFilter) matches(validator *v1.Validator) bool {
+	if len(f.indices) != 0 || len(f.pubkeys) != 0 {
+		if !f.indices[validator.Index] && !f.pubkeys[validator.Validator.PublicKey] {
+			return false
+		}
+	}
+
+	if len(f.statuses) == 0 {
+		return true
+	}
+
+	status := validator.Status.String()
+
+	for _, s := range f.statuses {
+		if status == s || strings.HasPrefix(status, s+"_") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validators returns the validators in the state for the given state id, filtered by ids and statuses. Ids that
+// don't match a validator are ignored.
+func (h *Handler) Validators(ctx context.Context, stateID StateIdentifier, ids, statuses []string) ([]*v1.Validator, error) {
+	var err error
+
+	const call = "validators"
+
+	h.metrics.ObserveCall(call, stateID.Type().String())
+
+	defer func() {
+		if err != nil {
+			h.metrics.ObserveErrorCall(call, stateID.Type().String())
+		}
+	}()
+
+	var filter *validatorFilter
+
+	filter, err = newValidatorFilter(ids, statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	var validators []*v1.Validator
+
+	validators, err = h.stateValidators(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*v1.Validator, 0)
+
+	for _, validator := range validators {
+		if filter.matches(validator) {
+			result = append(result, validator)
+		}
+	}
+
+	return result, nil
+}
+
+// ValidatorBalances returns the balances of the validators in the state for the given state id, filtered by ids.
+// Ids that don't match a validator are ignored.
+func (h *Handler) ValidatorBalances(ctx context.Context, stateID StateIdentifier, ids []string) ([]*v1.ValidatorBalance, error) {
+	var err error
+
+	const call = "validator_balances"
+
+	h.metrics.ObserveCall(call, stateID.Type().String())
+
+	defer func() {
+		if err != nil {
+			h.metrics.ObserveErrorCall(call, stateID.Type().String())
+		}
+	}()
+
+	var filter *validatorFilter
+
+	filter, err = newValidatorFilter(ids, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var validators []*v1.Validator
+
+	validators, err = h.stateValidators(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*v1.ValidatorBalance, 0)
+
+	for _, validator := range validators {
+		if filter.matches(validator) {
+			result = append(result, &v1.ValidatorBalance{
+				Index:   validator.Index,
+				Balance: validator.Balance,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// stateValidators returns every validator in the state for the given state id, decoding the state if its
+// validators aren't already cached.
+func (h *Handler) stateValidators(ctx context.Context, stateID StateIdentifier) ([]*v1.Validator, error) {
+	block, err := h.stateBlock(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := block.StateRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	h.validatorsMu.Lock()
+	validators, ok := h.validators[stateRoot]
+	h.validatorsMu.Unlock()
+
+	if ok {
+		return validators, nil
+	}
+
+	sp, err := h.provider.Spec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := h.BeaconState(ctx, stateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, store.ErrStateNotFound
+	}
+
+	validators, err = beacon.StateValidators(block.Version, *data, sp.SlotsPerEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	h.validatorsMu.Lock()
+	if len(h.validators) >= maxCachedValidatorSets {
+		h.validators = make(map[phase0.Root][]*v1.Validator)
+	}
+
+	h.validators[stateRoot] = validators
+	h.validatorsMu.Unlock()
+
+	return validators, nil
+}
@@ -0,0 +1,79 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	v1 "github.com/attestantio/go-eth2-client/api/v1"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+)
+
+func TestValidatorFilter(t *testing.T) {
+	t.Parallel()
+
+	pubkey := fmt.Sprintf("%#x", phase0.BLSPubKey{0x01})
+
+	validators := []*v1.Validator{
+		{Index: 0, Status: v1.ValidatorStateActiveOngoing, Validator: &phase0.Validator{PublicKey: phase0.BLSPubKey{0x01}}},
+		{Index: 1, Status: v1.ValidatorStateActiveExiting, Validator: &phase0.Validator{PublicKey: phase0.BLSPubKey{0x02}}},
+		{Index: 2, Status: v1.ValidatorStatePendingQueued, Validator: &phase0.Validator{PublicKey: phase0.BLSPubKey{0x03}}},
+		{Index: 3, Status: v1.ValidatorStateExitedUnslashed, Validator: &phase0.Validator{PublicKey: phase0.BLSPubKey{0x04}}},
+	}
+
+	tests := []struct {
+		name     string
+		ids      []string
+		statuses []string
+		expected []phase0.ValidatorIndex
+		err      error
+	}{
+		{name: "no filters", expected: []phase0.ValidatorIndex{0, 1, 2, 3}},
+		{name: "index", ids: []string{"2"}, expected: []phase0.ValidatorIndex{2}},
+		{name: "public key", ids: []string{pubkey}, expected: []phase0.ValidatorIndex{0}},
+		{name: "index and public key", ids: []string{pubkey, "3"}, expected: []phase0.ValidatorIndex{0, 3}},
+		{name: "unknown index", ids: []string{"10"}, expected: []phase0.ValidatorIndex{}},
+		{name: "status", statuses: []string{"active_exiting"}, expected: []phase0.ValidatorIndex{1}},
+		{name: "status group", statuses: []string{"active"}, expected: []phase0.ValidatorIndex{0, 1}},
+		{name: "statuses", statuses: []string{"pending", "exited_unslashed"}, expected: []phase0.ValidatorIndex{2, 3}},
+		{name: "index and status", ids: []string{"0", "2"}, statuses: []string{"active"}, expected: []phase0.ValidatorIndex{0}},
+		{name: "invalid index", ids: []string{"-1"}, err: ErrInvalidValidatorID},
+		{name: "short public key", ids: []string{"0x01"}, err: ErrInvalidValidatorID},
+		{name: "invalid status", statuses: []string{"act"}, err: ErrInvalidValidatorStatus},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := newValidatorFilter(test.ids, test.statuses)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			got := []phase0.ValidatorIndex{}
+
+			for _, validator := range validators {
+				if filter.matches(validator) {
+					got = append(got, validator.Index)
+				}
+			}
+
+			if len(got) != len(test.expected) {
+				t.Fatalf("expected validators %v, got %v", test.expected, got)
+			}
+
+			for i := range got {
+				if got[i] != test.expected[i] {
+					t.Fatalf("expected validators %v, got %v", test.expected, got)
+				}
+			}
+		})
+	}
+}